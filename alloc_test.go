@@ -0,0 +1,51 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type allocConfig struct {
+	host string
+	port int
+}
+
+type allocGreeter interface{ Greet() string }
+
+type allocImpl struct{}
+
+func (allocImpl) Greet() string { return "hello" }
+
+func TestResolveAllocs(t *testing.T) {
+	c := di.New()
+	di.RegisterIn(c, allocConfig{host: "localhost", port: 8080})
+	di.RegisterIn(c, 42)
+	di.RegisterFactoryIn(c, func() *benchService { return &benchService{} })
+	di.RegisterFactoryIn[allocGreeter](c, func() allocGreeter { return allocImpl{} })
+	di.RegisterNamedIn(c, "primary", "db")
+	di.RegisterScopedIn(c, func(*di.Scope) *benchDep { return &benchDep{} })
+
+	s := c.NewScope()
+	defer s.Close()
+
+	tests := map[string]func() error{
+		"pointer": func() error { _, err := di.ResolveIn[*benchService](c); return err },
+		"struct":  func() error { _, err := di.ResolveIn[allocConfig](c); return err },
+		"int":     func() error { _, err := di.ResolveIn[int](c); return err },
+		"iface":   func() error { _, err := di.ResolveIn[allocGreeter](c); return err },
+		"named":   func() error { _, err := di.ResolveNamedIn[string](c, "primary"); return err },
+		"scoped":  func() error { _, err := di.ResolveIn[*benchDep](s); return err },
+	}
+	for name, resolve := range tests {
+		t.Run(name, func(t *testing.T) {
+			// the first resolution builds and caches the instance
+			if err := resolve(); err != nil {
+				t.Fatal(err)
+			}
+			if allocs := testing.AllocsPerRun(100, func() { _ = resolve() }); allocs != 0 {
+				t.Errorf("got %v allocations per resolve, want 0", allocs)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// DefaultStopTimeout is how long App waits for stop hooks unless configured otherwise
+const DefaultStopTimeout = 15 * time.Second
+
+// App runs the lifecycle of a container as an application: it starts the
+// registrations with start hooks, waits for a shutdown signal and stops them
+type App struct {
+	container   *Container
+	stopTimeout time.Duration
+	signals     []os.Signal
+}
+
+// AppOption configures an App
+type AppOption func(a *App)
+
+// WithStopTimeout sets the deadline of the context passed to the stop hooks
+func WithStopTimeout(d time.Duration) AppOption {
+	return func(a *App) {
+		a.stopTimeout = d
+	}
+}
+
+// WithSignals sets the signals that stop the app, SIGINT and SIGTERM by default
+func WithSignals(signals ...os.Signal) AppOption {
+	return func(a *App) {
+		a.signals = signals
+	}
+}
+
+// NewApp creates an app that runs c, or the default container if c is nil
+func NewApp(c *Container, opts ...AppOption) *App {
+	if c == nil {
+		c = Default()
+	}
+	a := &App{
+		container:   c,
+		stopTimeout: DefaultStopTimeout,
+		signals:     []os.Signal{os.Interrupt, syscall.SIGTERM},
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Container returns the container the app runs
+func (a *App) Container() *Container {
+	return a.container
+}
+
+// Run starts the container and blocks until one of the app's signals arrives
+// or ctx is done. It then shuts the container down, passing the stop hooks a
+// context that expires after the stop timeout. The signals are watched from
+// the start on: one that arrives while starting cancels the context passed to
+// the start hooks. Start errors are returned without waiting.
+func (a *App) Run(ctx context.Context) error {
+	wait, cancel := signal.NotifyContext(ctx, a.signals...)
+	if err := a.container.Start(wait); err != nil {
+		cancel()
+		return err
+	}
+	<-wait.Done()
+	cancel()
+
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), a.stopTimeout)
+	defer cancel()
+
+	err := a.container.Shutdown(stopCtx)
+	if stopCtx.Err() != nil {
+		err = errors.Join(err, stopCtx.Err())
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ryanbekhen/di"
+)
+
+type appServer struct{}
+
+func TestRunHandlesSignalDuringStart(t *testing.T) {
+	c := di.New()
+	di.RegisterFactoryIn(c, func() *appServer { return &appServer{} },
+		di.OnStart(func(ctx context.Context, _ *appServer) error {
+			p, err := os.FindProcess(os.Getpid())
+			if err != nil {
+				return err
+			}
+			if err := p.Signal(os.Interrupt); err != nil {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+				return errors.New("the signal did not cancel the start context")
+			}
+		}))
+
+	err := di.NewApp(c, di.WithSignals(os.Interrupt)).Run(context.Background())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run returned %v, want context.Canceled", err)
+	}
+}
+
+type appWorker struct{ events []string }
+
+func registerWorker(c *di.Container, stop func(ctx context.Context) error) *appWorker {
+	w := &appWorker{}
+	di.RegisterFactoryIn(c, func() *appWorker { return w },
+		di.OnStart(func(_ context.Context, w *appWorker) error {
+			w.events = append(w.events, "start")
+			return nil
+		}),
+		di.OnStop(func(ctx context.Context, w *appWorker) error {
+			w.events = append(w.events, "stop")
+			return stop(ctx)
+		}))
+	return w
+}
+
+func TestRunStopsWhenContextIsDone(t *testing.T) {
+	c := di.New()
+	w := registerWorker(c, func(context.Context) error { return nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- di.NewApp(c).Run(ctx) }()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after its context was canceled")
+	}
+	if len(w.events) != 2 || w.events[0] != "start" || w.events[1] != "stop" {
+		t.Fatalf("events %v, want start then stop", w.events)
+	}
+}
+
+func TestRunReportsStopTimeout(t *testing.T) {
+	c := di.New()
+	registerWorker(c, func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := di.NewApp(c, di.WithStopTimeout(10*time.Millisecond)).Run(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run returned %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestRunReturnsStartErrorWithoutWaiting(t *testing.T) {
+	c := di.New()
+	boom := errors.New("boom")
+	di.RegisterFactoryIn(c, func() *appServer { return &appServer{} },
+		di.OnStart(func(context.Context, *appServer) error { return boom }))
+
+	err := di.NewApp(c).Run(context.Background())
+	if !errors.Is(err, boom) {
+		t.Fatalf("Run returned %v, want the start error", err)
+	}
+}
+
+func TestNewAppWithoutContainerUsesDefault(t *testing.T) {
+	c := di.New()
+	restore := di.SetDefault(c)
+	defer restore()
+
+	if di.NewApp(nil).Container() != c {
+		t.Fatal("NewApp(nil) does not run the default container")
+	}
+}
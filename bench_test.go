@@ -0,0 +1,127 @@
+package di_test
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type benchService struct{ n int }
+
+type benchDep struct{ svc *benchService }
+
+func BenchmarkResolve(b *testing.B) {
+	c := di.New()
+	di.RegisterFactoryIn(c, func() *benchService { return &benchService{} })
+	di.MustResolveIn[*benchService](c)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := di.ResolveIn[*benchService](c); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkResolveNamed(b *testing.B) {
+	c := di.New()
+	di.RegisterNamedIn(c, "primary", &benchService{})
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := di.ResolveNamedIn[*benchService](c, "primary"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkResolveParallel(b *testing.B) {
+	c := di.New()
+	di.RegisterFactoryIn(c, func() *benchService { return &benchService{} })
+	di.MustResolveIn[*benchService](c)
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := di.ResolveIn[*benchService](c); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkResolveTransientWithDependency(b *testing.B) {
+	c := di.New()
+	di.RegisterIn(c, &benchService{})
+	di.RegisterTransientIn(c, func() *benchDep { return &benchDep{svc: di.MustResolveIn[*benchService](c)} })
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := di.ResolveIn[*benchDep](c); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRegister(b *testing.B) {
+	c := di.New()
+	svc := &benchService{}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		di.RegisterIn(c, svc)
+	}
+}
+
+// workloadKeys is the number of named registrations the workload benchmarks spread over
+const workloadKeys = 1024
+
+// benchmarkWorkload resolves named registrations from parallel goroutines,
+// replacing the registration instead on one operation out of every writeEvery.
+// A writeEvery of 1 replaces on every operation and then resolves the new one.
+func benchmarkWorkload(b *testing.B, writeEvery int) {
+	c := di.New()
+	names := make([]string, workloadKeys)
+	for i := range names {
+		names[i] = "svc-" + strconv.Itoa(i)
+		di.RegisterNamedFactoryIn(c, names[i], func() *benchService { return &benchService{n: i} })
+		if _, err := di.ResolveNamedIn[*benchService](c, names[i]); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	var next atomic.Uint64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			op := next.Add(1)
+			name := names[op%workloadKeys]
+			if op%uint64(writeEvery) == 0 {
+				di.RegisterNamedFactoryIn(c, name, func() *benchService { return &benchService{} })
+				if writeEvery > 1 {
+					continue
+				}
+			}
+			if _, err := di.ResolveNamedIn[*benchService](c, name); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkWorkloadReadHeavy(b *testing.B) {
+	benchmarkWorkload(b, 1000)
+}
+
+func BenchmarkWorkloadMixed(b *testing.B) {
+	benchmarkWorkload(b, 10)
+}
+
+func BenchmarkWorkloadWriteHeavy(b *testing.B) {
+	benchmarkWorkload(b, 1)
+}
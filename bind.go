@@ -0,0 +1,31 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Bind makes resolving I delegate to the registration of Impl, so both share
+// the same instance and lifetime
+func Bind[I, Impl any](opts ...RegisterOption) error {
+	return BindIn[I, Impl](Default(), opts...)
+}
+
+// BindIn makes resolving I in c delegate to the registration of Impl
+func BindIn[I, Impl any](c *Container, opts ...RegisterOption) error {
+	return c.alias(typeKey[I](), typeKey[Impl](), typeOf[I](), typeOf[Impl](), opts)
+}
+
+// alias registers from as a delegate of the registration stored under to
+func (c *Container) alias(from, to key, fromType, toType reflect.Type, opts []RegisterOption) error {
+	if !toType.AssignableTo(fromType) {
+		return fmt.Errorf("%w: cannot bind %v to %v: %v does not implement %v", ErrTypeMismatch, from, to, to, from)
+	}
+
+	return c.store(&factory{
+		key:      from,
+		lifetime: Singleton,
+		deps:     []key{to},
+		target:   &to,
+	}, opts)
+}
@@ -0,0 +1,85 @@
+package di
+
+import (
+	"reflect"
+	"sync"
+)
+
+// builtin is implemented by wrapper types, such as Optional, that the
+// container builds itself when they are not registered
+type builtin interface {
+	build(r Resolver) (any, error)
+}
+
+// builtinKeys records the keys of builtin wrapper types declared as dependencies
+var builtinKeys sync.Map
+
+// depKey returns the key of a dependency on T, remembering builtin wrappers
+func depKey[T any]() key {
+	k := typeKey[T]()
+	if builtinType[T]() {
+		builtinKeys.Store(k, true)
+	}
+	return k
+}
+
+// builtinType reports whether T is a builtin wrapper type. It inspects *T
+// rather than a T value, which would be boxed on every call; the wrappers
+// implement builtin with value receivers, so *T implements it too.
+func builtinType[T any]() bool {
+	_, ok := any((*T)(nil)).(builtin)
+	return ok
+}
+
+// depKeyOf returns the key of a dependency on t, remembering builtin wrappers
+func depKeyOf(t reflect.Type) key {
+	k := keyOf(t)
+	if _, ok := reflect.Zero(t).Interface().(builtin); ok {
+		builtinKeys.Store(k, true)
+	}
+	return k
+}
+
+// isBuiltin reports whether k is the key of a builtin wrapper type
+func isBuiltin(k key) bool {
+	_, ok := builtinKeys.Load(k)
+	return ok
+}
+
+// resolveType resolves the instance of t stored under k from r, building
+// builtin wrapper types that are not registered
+func resolveType(r Resolver, t reflect.Type, k key) (any, error) {
+	if k.name == "" && !r.owner().has(k) {
+		if b, ok := reflect.Zero(t).Interface().(builtin); ok {
+			return b.build(r)
+		}
+	}
+	return resolveKey(r, k)
+}
+
+// Optional is a dependency on T that can be resolved even when T is not
+// registered. Constructors that take an Optional[T] are auto-wired whether
+// or not T is registered; if T is registered but cannot be built, the
+// resolution fails with that error.
+type Optional[T any] struct {
+	value T
+	ok    bool
+}
+
+// Get returns the instance of T and whether it was available
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.ok
+}
+
+// build resolves T into an Optional, which is empty if T is not registered
+func (Optional[T]) build(r Resolver) (any, error) {
+	k := typeKey[T]()
+	if !r.owner().has(k) {
+		return Optional[T]{}, nil
+	}
+	v, err := resolveAs[T](r, k)
+	if err != nil {
+		return nil, err
+	}
+	return Optional[T]{value: v, ok: true}, nil
+}
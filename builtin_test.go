@@ -0,0 +1,53 @@
+package di_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type optionalTracer struct{}
+type optionalServer struct{ tracer di.Optional[*optionalTracer] }
+
+func newOptionalServer(tracer di.Optional[*optionalTracer]) *optionalServer {
+	return &optionalServer{tracer: tracer}
+}
+
+func TestOptionalWithoutRegistration(t *testing.T) {
+	c := di.New()
+	if err := c.RegisterConstructor(newOptionalServer); err != nil {
+		t.Fatal(err)
+	}
+	s, err := di.ResolveIn[*optionalServer](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.tracer.Get(); ok {
+		t.Fatal("Optional reports an unregistered type as available")
+	}
+}
+
+func TestOptionalWithRegistration(t *testing.T) {
+	c := di.New()
+	tracer := &optionalTracer{}
+	di.RegisterIn(c, tracer)
+	if err := c.RegisterConstructor(newOptionalServer); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := di.MustResolveIn[*optionalServer](c).tracer.Get(); !ok || got != tracer {
+		t.Fatalf("Optional.Get() = %v, %v, want the registered tracer", got, ok)
+	}
+}
+
+func TestOptionalReportsFailedFactory(t *testing.T) {
+	c := di.New()
+	errBroken := errors.New("tracer broken")
+	di.RegisterFactoryEIn(c, func() (*optionalTracer, error) { return nil, errBroken })
+	if err := c.RegisterConstructor(newOptionalServer); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := di.ResolveIn[*optionalServer](c); !errors.Is(err, errBroken) {
+		t.Fatalf("ResolveIn() error = %v, want %v", err, errBroken)
+	}
+}
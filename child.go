@@ -0,0 +1,28 @@
+package di
+
+// Child creates a container that resolves its own registrations first and
+// falls back to c for everything else. Registrations in the child override
+// the parent without changing it; singletons inherited from the parent are
+// built and shared by the parent.
+func (c *Container) Child() *Container {
+	child := New()
+	child.parent = c
+	child.strict = c.strict
+	child.errorFormatter.Store(c.errorFormatter.Load())
+	return child
+}
+
+// Parent returns the container c falls back to, or nil for a root container
+func (c *Container) Parent() *Container {
+	return c.parent
+}
+
+// lookup returns the registration for k in c or the nearest parent that has one
+func (c *Container) lookup(k key) (*factory, bool) {
+	for ; c != nil; c = c.parent {
+		if f, ok := c.factories.load(k); ok {
+			return f, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,31 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type childConfig struct{ env string }
+
+func TestChildInheritsAndOverrides(t *testing.T) {
+	parent := di.New()
+	di.RegisterIn(parent, &childConfig{env: "prod"})
+	di.RegisterIn(parent, "shared")
+
+	child := parent.Child()
+	di.RegisterIn(child, &childConfig{env: "test"})
+
+	if got := di.MustResolveIn[*childConfig](child); got.env != "test" {
+		t.Fatalf("child config = %q, want test", got.env)
+	}
+	if got := di.MustResolveIn[*childConfig](parent); got.env != "prod" {
+		t.Fatalf("parent config = %q, want prod", got.env)
+	}
+	if got := di.MustResolveIn[string](child); got != "shared" {
+		t.Fatalf("inherited value = %q, want shared", got)
+	}
+	if child.Parent() != parent {
+		t.Fatal("Parent() does not return the parent")
+	}
+}
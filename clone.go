@@ -0,0 +1,107 @@
+package di
+
+// CloneOption configures Clone
+type CloneOption func(o *cloneOptions)
+
+// cloneOptions holds the settings of a Clone call
+type cloneOptions struct {
+	instances bool
+}
+
+// WithInstances makes Clone share the singletons already resolved in the
+// original container instead of building them again
+func WithInstances() CloneOption {
+	return func(o *cloneOptions) {
+		o.instances = true
+	}
+}
+
+// Clone copies the registrations, including those deferred to Build,
+// decorators, hooks, instrumentation, metrics, logger and event subscribers
+// of c into a new, independent container. Singletons are built afresh in the clone unless WithInstances
+// is given. Later changes to either container do not affect the other.
+func (c *Container) Clone(opts ...CloneOption) *Container {
+	var o cloneOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	clone := New()
+	clone.parent = c.parent
+	clone.strict = c.strict
+	clone.slowFactoryThreshold.Store(c.slowFactoryThreshold.Load())
+	// the copied multiple bindings keep their ids, so the clone numbers its
+	// own from where c left off
+	clone.seq.Store(c.seq.Load())
+	if w := c.transients.Load(); w != nil {
+		clone.WarnFrequentTransients(w.limit, w.window)
+	}
+
+	for _, f := range c.sortedFactories(func(*factory) bool { return true }) {
+		copied := clone.adopt(f, f.key, f.target)
+		if o.instances && f.used.Load() {
+			// shared instances are served from the cache without marking
+			copied.used.Store(true)
+		}
+	}
+
+	if o.instances {
+		clone.instances.copyFrom(&c.instances)
+	}
+
+	c.decoratorsMu.RLock()
+	for k, ds := range c.decorators {
+		clone.decorators[k] = append([]func(any) any(nil), ds...)
+	}
+	c.decoratorsMu.RUnlock()
+
+	c.modulesMu.Lock()
+	clone.modules = append(clone.modules, c.modules...)
+	c.modulesMu.Unlock()
+
+	c.laterMu.Lock()
+	clone.later = append(clone.later, c.later...)
+	c.laterMu.Unlock()
+
+	clone.hooks.set.Store(c.hooks.load())
+	clone.instruments.list.Store(c.instruments.list.Load())
+	clone.metrics.Store(c.metrics.Load())
+	clone.logger.Store(c.logger.Load())
+	clone.logLevels.Store(c.logLevels.Load())
+	clone.errorFormatter.Store(c.errorFormatter.Load())
+	clone.componentLoggers.Store(c.componentLoggers.Load())
+	clone.subscribers.list.Store(c.subscribers.list.Load())
+
+	return clone
+}
+
+// adopt stores a copy of the registration f, taken from another container,
+// under k with the alias target given, that builds its own instances in c
+func (c *Container) adopt(f *factory, k key, target *key) *factory {
+	copied := c.newFactory(k, f.lifetime, f.create)
+	copied.deps = f.deps
+	copied.groups = f.groups
+	copied.priority = f.priority
+	copied.target = target
+	copied.aliases = f.aliases
+	copied.external = f.external
+	copied.fallback = f.fallback
+	copied.module = f.module
+	copied.onStart = f.onStart
+	copied.onStop = f.onStop
+	copied.retry = f.retry
+	copied.refreshAhead = f.refreshAhead
+	copied.site = f.site
+	copied.overrides = f.overrides
+	if f.weak != nil {
+		copied.weak = &weakSlot{}
+	}
+	if f.ttl != nil {
+		copied.ttl = &ttlSlot{ttl: f.ttl.ttl}
+	}
+	copied.seq = c.seq.Add(1)
+	_, replaced := c.factories.swap(copied.key, copied)
+	c.instances.delete(copied.key)
+	c.emitRegistration(copied, replaced)
+	return copied
+}
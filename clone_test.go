@@ -0,0 +1,42 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type cloneItem struct{ n int }
+
+func TestCloneKeepsMultipleBindings(t *testing.T) {
+	c := di.New()
+	di.RegisterMultiIn(c, cloneItem{1})
+	di.RegisterMultiIn(c, cloneItem{2})
+
+	clone := c.Clone()
+	di.RegisterMultiIn(clone, cloneItem{3})
+
+	all, err := di.ResolveAllIn[cloneItem](clone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].n != 1 || all[1].n != 2 || all[2].n != 3 {
+		t.Fatalf("ResolveAllIn(clone) = %v, want [{1} {2} {3}]", all)
+	}
+	if all, _ := di.ResolveAllIn[cloneItem](c); len(all) != 2 {
+		t.Fatalf("ResolveAllIn(original) = %v, want the two original bindings", all)
+	}
+}
+
+func TestCloneBuildsItsOwnSingletons(t *testing.T) {
+	c := di.New()
+	di.RegisterFactoryIn(c, func() *cloneItem { return &cloneItem{} })
+	original := di.MustResolveIn[*cloneItem](c)
+
+	if di.MustResolveIn[*cloneItem](c.Clone()) == original {
+		t.Fatal("the clone shares the singleton without WithInstances")
+	}
+	if di.MustResolveIn[*cloneItem](c.Clone(di.WithInstances())) != original {
+		t.Fatal("the clone does not share the singleton with WithInstances")
+	}
+}
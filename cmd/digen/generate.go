@@ -0,0 +1,385 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// diPath is the import path of the container package
+const diPath = "github.com/ryanbekhen/di"
+
+// directive marks the constructors digen wires
+const directive = "//digen:provide"
+
+// proxyDirective marks the interfaces digen generates proxies for
+const proxyDirective = "//digen:proxy"
+
+// provider is a constructor marked with the directive
+type provider struct {
+	fn *types.Func
+	// params lists the types of the parameters, nil for the container itself
+	params []types.Type
+	result types.Type
+	// fallible reports whether the constructor also returns an error
+	fallible bool
+}
+
+// generate returns the source of the wiring function name and of the
+// interface proxies for the package in dir
+func generate(dir, out, name string) ([]byte, error) {
+	pkg, err := load(dir, out)
+	if err != nil {
+		return nil, err
+	}
+	providers, proxies, err := collect(pkg, out)
+	if err != nil {
+		return nil, err
+	}
+	var order []*provider
+	if len(providers) > 0 {
+		order, err = sortProviders(pkg, providers)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return render(pkg, order, proxies, name)
+}
+
+// load type-checks the package in dir, ignoring errors in the previously
+// generated file, which may be stale
+func load(dir, out string) (*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps,
+		Dir:  dir,
+	}
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+
+	pkg := pkgs[0]
+	for _, e := range pkg.Errors {
+		if !strings.HasPrefix(filepath.Base(e.Pos), out+":") {
+			return nil, e
+		}
+	}
+	return pkg, nil
+}
+
+// collect returns the constructors and the interfaces of pkg marked with the
+// directives, in source order
+func collect(pkg *packages.Package, out string) ([]*provider, []*proxy, error) {
+	var providers []*provider
+	var proxies []*proxy
+	var errs []error
+	for _, file := range pkg.Syntax {
+		if filepath.Base(pkg.Fset.File(file.Pos()).Name()) == out {
+			continue
+		}
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if !marked(decl.Doc, directive) {
+					continue
+				}
+				p, err := newProvider(pkg, decl)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", pkg.Fset.Position(decl.Pos()), err))
+					continue
+				}
+				providers = append(providers, p)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					// the comment of an unparenthesized declaration belongs to decl
+					doc := ts.Doc
+					if doc == nil && !decl.Lparen.IsValid() {
+						doc = decl.Doc
+					}
+					if !marked(doc, proxyDirective) {
+						continue
+					}
+					p, err := newProxy(pkg, ts)
+					if err != nil {
+						errs = append(errs, fmt.Errorf("%s: %w", pkg.Fset.Position(ts.Pos()), err))
+						continue
+					}
+					proxies = append(proxies, p)
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+	if len(providers) == 0 && len(proxies) == 0 {
+		return nil, nil, fmt.Errorf("nothing in %s is marked with %s or %s", pkg.PkgPath, directive, proxyDirective)
+	}
+	return providers, proxies, nil
+}
+
+// marked reports whether doc carries the directive
+func marked(doc *ast.CommentGroup, directive string) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == directive {
+			return true
+		}
+	}
+	return false
+}
+
+// newProvider checks the signature of the constructor declared by fd
+func newProvider(pkg *packages.Package, fd *ast.FuncDecl) (*provider, error) {
+	fn := pkg.TypesInfo.Defs[fd.Name].(*types.Func)
+	sig := fn.Type().(*types.Signature)
+	switch {
+	case sig.Recv() != nil:
+		return nil, fmt.Errorf("%s is a method, not a constructor", fn.Name())
+	case sig.TypeParams().Len() > 0:
+		return nil, fmt.Errorf("constructor %s must not be generic", fn.Name())
+	case sig.Variadic():
+		return nil, fmt.Errorf("constructor %s must not be variadic", fn.Name())
+	}
+
+	p := &provider{fn: fn}
+	res := sig.Results()
+	switch {
+	case res.Len() == 1:
+	case res.Len() == 2 && types.Identical(res.At(1).Type(), types.Universe.Lookup("error").Type()):
+		p.fallible = true
+	default:
+		return nil, fmt.Errorf("constructor %s must return T or (T, error)", fn.Name())
+	}
+	p.result = res.At(0).Type()
+
+	for i := range sig.Params().Len() {
+		t := sig.Params().At(i).Type()
+		if isContainer(t) {
+			t = nil
+		}
+		p.params = append(p.params, t)
+	}
+	return p, nil
+}
+
+// isContainer reports whether t is *di.Container
+func isContainer(t types.Type) bool {
+	ptr, ok := t.(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == diPath && obj.Name() == "Container"
+}
+
+// sortProviders orders providers so each one comes after the providers of its
+// dependencies, keeping source order otherwise
+func sortProviders(pkg *packages.Package, providers []*provider) ([]*provider, error) {
+	qualify := types.RelativeTo(pkg.Types)
+	var byType typeutil.Map
+	var errs []error
+	for _, p := range providers {
+		if other, ok := byType.At(p.result).(*provider); ok {
+			errs = append(errs, fmt.Errorf("%s is provided by both %s and %s", types.TypeString(p.result, qualify), other.fn.Name(), p.fn.Name()))
+			continue
+		}
+		byType.Set(p.result, p)
+	}
+	for _, p := range providers {
+		for _, t := range p.params {
+			if t != nil && byType.At(t) == nil {
+				errs = append(errs, fmt.Errorf("%s needs %s, which no constructor provides", p.fn.Name(), types.TypeString(t, qualify)))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[*provider]int)
+	var order []*provider
+	var path []string
+	var visit func(p *provider) error
+	visit = func(p *provider) error {
+		switch state[p] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s -> %s", strings.Join(path, " -> "), p.fn.Name())
+		}
+		state[p] = visiting
+		path = append(path, p.fn.Name())
+		for _, t := range p.params {
+			if t == nil {
+				continue
+			}
+			if err := visit(byType.At(t).(*provider)); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[p] = done
+		order = append(order, p)
+		return nil
+	}
+	for _, p := range providers {
+		if err := visit(p); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// render writes the wiring function name calling the providers in order,
+// when there are providers, and the proxies
+func render(pkg *packages.Package, order []*provider, proxies []*proxy, name string) ([]byte, error) {
+	qualify := types.RelativeTo(pkg.Types)
+	vars := newNamer(pkg.Types.Scope())
+	var byType typeutil.Map
+	var fallible bool
+
+	var body bytes.Buffer
+	for _, p := range order {
+		v := vars.name(p.result)
+		byType.Set(p.result, v)
+
+		args := make([]string, len(p.params))
+		for i, t := range p.params {
+			if t == nil {
+				args[i] = "c"
+			} else {
+				args[i] = byType.At(t).(string)
+			}
+		}
+		call := fmt.Sprintf("%s(%s)", p.fn.Name(), strings.Join(args, ", "))
+
+		if p.fallible {
+			fallible = true
+			fmt.Fprintf(&body, "\t%s, err := %s\n", v, call)
+			fmt.Fprintf(&body, "\tif err != nil {\n\t\treturn nil, fmt.Errorf(\"failed to build %s: %%w\", err)\n\t}\n", types.TypeString(p.result, qualify))
+		} else {
+			fmt.Fprintf(&body, "\t%s := %s\n", v, call)
+		}
+		fmt.Fprintf(&body, "\tdi.RegisterIn(c, %s)\n", v)
+	}
+
+	imports := newImports(pkg.Types)
+	if fallible {
+		imports.use("fmt")
+	}
+	var proxyBody bytes.Buffer
+	for _, p := range proxies {
+		renderProxy(&proxyBody, pkg, p, imports)
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by digen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg.Name)
+	imports.write(&src)
+	fmt.Fprintf(&src, ")\n")
+	if len(order) > 0 {
+		fmt.Fprintf(&src, "\n// %s builds every provider in dependency order and returns a container\n// holding the instances\n", name)
+		fmt.Fprintf(&src, "func %s() (*di.Container, error) {\n\tc := di.New()\n", name)
+		src.Write(body.Bytes())
+		fmt.Fprintf(&src, "\treturn c, nil\n}\n")
+	}
+	src.Write(proxyBody.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+// namer picks variable names for the generated function that do not shadow
+// the identifiers it uses
+type namer struct {
+	scope *types.Scope
+	used  map[string]bool
+}
+
+// newNamer returns a namer avoiding the identifiers declared in scope
+func newNamer(scope *types.Scope) *namer {
+	return &namer{scope: scope, used: map[string]bool{"c": true, "err": true, "di": true, "fmt": true}}
+}
+
+// name returns an unused variable name for a value of type t
+func (n *namer) name(t types.Type) string {
+	base := baseName(t)
+	candidate := base
+	for i := 2; n.taken(candidate); i++ {
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+	n.used[candidate] = true
+	return candidate
+}
+
+// taken reports whether name is unavailable as a variable name
+func (n *namer) taken(name string) bool {
+	return n.used[name] || token.IsKeyword(name) || n.scope.Lookup(name) != nil || types.Universe.Lookup(name) != nil
+}
+
+// baseName derives a variable name from the name of t
+func baseName(t types.Type) string {
+	for {
+		switch u := t.(type) {
+		case *types.Pointer:
+			t = u.Elem()
+			continue
+		case *types.Slice:
+			t = u.Elem()
+			continue
+		case *types.Named:
+			return lowerFirst(u.Obj().Name())
+		case *types.Alias:
+			return lowerFirst(u.Obj().Name())
+		case *types.Basic:
+			return u.Name() + "Value"
+		}
+		return "v"
+	}
+}
+
+// lowerFirst lowercases the leading run of capitals of s, so DBClient becomes dbClient
+func lowerFirst(s string) string {
+	r := []rune(s)
+	for i := range r {
+		if !unicode.IsUpper(r[i]) {
+			break
+		}
+		// keep the capital that starts the next word
+		if i > 0 && i+1 < len(r) && unicode.IsLower(r[i+1]) {
+			break
+		}
+		r[i] = unicode.ToLower(r[i])
+	}
+	return string(r)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// useFixtures runs the go command for the fixture module in testdata, which
+// builds against the di package of this repository
+func useFixtures(t *testing.T) {
+	t.Helper()
+	t.Setenv("GOWORK", "off")
+	t.Setenv("GOFLAGS", "-mod=mod")
+}
+
+func TestGenerateGolden(t *testing.T) {
+	useFixtures(t)
+	src, err := generate(filepath.Join("testdata", "app"), "digen_gen.go", "InitContainer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, filepath.Join("testdata", "app.golden"), src)
+}
+
+func TestGenerateProxies(t *testing.T) {
+	useFixtures(t)
+	dir := filepath.Join("testdata", "proxy")
+	src, err := generate(dir, "digen_gen.go", "InitContainer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the fixture keeps the generated file, so it must also compile
+	checkGolden(t, filepath.Join(dir, "digen_gen.go"), src)
+	if _, err := load(dir, "none"); err != nil {
+		t.Fatalf("generated proxies do not compile: %v", err)
+	}
+}
+
+// checkGolden compares src with the golden file, rewriting it with -update
+func checkGolden(t *testing.T, golden string, src []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(golden, src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, want) {
+		t.Fatalf("generated code differs from %s:\n%s", golden, src)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	useFixtures(t)
+	for dir, want := range map[string][]string{
+		"missing":   {"NewScheduler needs Clock, which no constructor provides"},
+		"cycle":     {"dependency cycle: NewA -> NewB -> NewA"},
+		"duplicate": {"*Cache is provided by both NewMemoryCache and NewRedisCache"},
+		"badsig": {
+			"Clone is a method, not a constructor",
+			"constructor NewPool must not be variadic",
+			"constructor NewPools must return T or (T, error)",
+		},
+		"badproxy": {
+			"Config is not an interface",
+			"interface Repository must not be generic",
+			"interface Empty has no methods",
+			"interface Number is a type constraint",
+		},
+	} {
+		_, err := generate(filepath.Join("testdata", dir), "digen_gen.go", "InitContainer")
+		if err == nil {
+			t.Errorf("%s: generated without error", dir)
+			continue
+		}
+		for _, w := range want {
+			if !strings.Contains(err.Error(), w) {
+				t.Errorf("%s: error %q does not contain %q", dir, err, w)
+			}
+		}
+	}
+}
+
+func TestLowerFirst(t *testing.T) {
+	for in, want := range map[string]string{
+		"Config":   "config",
+		"DBClient": "dbClient",
+		"DB":       "db",
+		"userRepo": "userRepo",
+	} {
+		if got := lowerFirst(in); got != want {
+			t.Errorf("lowerFirst(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
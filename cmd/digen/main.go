@@ -0,0 +1,56 @@
+// Command digen generates reflection-free wiring for a di container.
+//
+// Mark the constructors of a package with a digen:provide comment:
+//
+//	//digen:provide
+//	func NewUserRepository(db *sql.DB) *UserRepository { ... }
+//
+// and run digen in the package directory, typically from a go:generate
+// directive:
+//
+//	//go:generate go run github.com/ryanbekhen/di/cmd/digen
+//
+// digen writes a function, InitContainer by default, that calls every marked
+// constructor directly in dependency order and registers the results in a
+// new container. Constructors have the form func(D1, D2, ...) T or
+// func(D1, D2, ...) (T, error); a parameter of type *di.Container receives
+// the container being built. A dependency no constructor provides, two
+// constructors for the same type or a dependency cycle is reported when
+// generating, and a constructor whose signature later changes makes the
+// generated code fail to compile.
+//
+// Mark an interface with a digen:proxy comment to generate a proxy for it:
+//
+//	//digen:proxy
+//	type Mailer interface { ... }
+//
+// digen writes NewMailerProxy(target Mailer, interceptors ...di.Interceptor),
+// which returns a Mailer that passes every call through the interceptors
+// before it reaches target. di.Proxy applies it to every Mailer the container
+// builds.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package to generate wiring for")
+	out := flag.String("o", "digen_gen.go", "name of the generated file, in the package directory")
+	name := flag.String("func", "InitContainer", "name of the generated function")
+	flag.Parse()
+
+	log.SetFlags(0)
+	log.SetPrefix("digen: ")
+
+	src, err := generate(*dir, *out, *name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(*dir, *out), src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// proxy is an interface marked with the proxy directive
+type proxy struct {
+	named *types.Named
+	iface *types.Interface
+}
+
+// newProxy checks the interface declared by ts
+func newProxy(pkg *packages.Package, ts *ast.TypeSpec) (*proxy, error) {
+	obj := pkg.TypesInfo.Defs[ts.Name].(*types.TypeName)
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("%s is an alias, not an interface", obj.Name())
+	}
+	iface, ok := named.Underlying().(*types.Interface)
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("%s is not an interface", obj.Name())
+	case named.TypeParams().Len() > 0:
+		return nil, fmt.Errorf("interface %s must not be generic", obj.Name())
+	case !iface.IsMethodSet():
+		return nil, fmt.Errorf("interface %s is a type constraint", obj.Name())
+	case iface.NumMethods() == 0:
+		return nil, fmt.Errorf("interface %s has no methods", obj.Name())
+	}
+	return &proxy{named: named, iface: iface}, nil
+}
+
+// renderProxy writes the proxy type of p, its constructor and its methods
+func renderProxy(w *bytes.Buffer, pkg *packages.Package, p *proxy, imports *imports) {
+	name := p.named.Obj().Name()
+	typeName := lowerFirst(name) + "Proxy"
+	ctor := "New" + name + "Proxy"
+	if !token.IsExported(name) {
+		ctor = "new" + strings.ToUpper(name[:1]) + name[1:] + "Proxy"
+	}
+	qualified := pkg.Name + "." + name
+
+	fmt.Fprintf(w, "\n// %s runs interceptors around the calls to a %s\n", typeName, name)
+	fmt.Fprintf(w, "type %s struct {\n\ttarget %s\n\tinterceptors []di.Interceptor\n}\n", typeName, name)
+	fmt.Fprintf(w, "\n// %s returns a %s that runs interceptors around every call to target\n", ctor, name)
+	fmt.Fprintf(w, "func %s(target %s, interceptors ...di.Interceptor) %s {\n", ctor, name, name)
+	fmt.Fprintf(w, "\treturn &%s{target: target, interceptors: interceptors}\n}\n", typeName)
+
+	for i := range p.iface.NumMethods() {
+		renderMethod(w, typeName, qualified, p.iface.Method(i), imports)
+	}
+}
+
+// renderMethod writes the method m of the proxy type typeName, which passes
+// the call through di.Intercept
+func renderMethod(w *bytes.Buffer, typeName, iface string, m *types.Func, imports *imports) {
+	sig := m.Type().(*types.Signature)
+	typeOf := func(t types.Type) string { return types.TypeString(t, imports.qualify) }
+
+	var params, args, unpack, forward []string
+	for i := range sig.Params().Len() {
+		t := sig.Params().At(i).Type()
+		a := fmt.Sprintf("a%d", i)
+		args = append(args, a)
+		unpack = append(unpack, fmt.Sprintf("\t\t%s, _ := call.Args[%d].(%s)\n", a, i, typeOf(t)))
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			params = append(params, fmt.Sprintf("%s ...%s", a, typeOf(t.(*types.Slice).Elem())))
+			forward = append(forward, a+"...")
+		} else {
+			params = append(params, fmt.Sprintf("%s %s", a, typeOf(t)))
+			forward = append(forward, a)
+		}
+	}
+
+	var results, rets, convert []string
+	for i := range sig.Results().Len() {
+		t := sig.Results().At(i).Type()
+		r := fmt.Sprintf("r%d", i)
+		results = append(results, typeOf(t))
+		rets = append(rets, r)
+		convert = append(convert, fmt.Sprintf("\t%s, _ := call.Results[%d].(%s)\n", r, i, typeOf(t)))
+	}
+
+	fmt.Fprintf(w, "\nfunc (p *%s) %s(%s)", typeName, m.Name(), strings.Join(params, ", "))
+	switch len(results) {
+	case 0:
+	case 1:
+		fmt.Fprintf(w, " %s", results[0])
+	default:
+		fmt.Fprintf(w, " (%s)", strings.Join(results, ", "))
+	}
+	fmt.Fprintf(w, " {\n\tcall := &di.Call{Interface: %q, Method: %q", iface, m.Name())
+	if len(args) > 0 {
+		fmt.Fprintf(w, ", Args: []any{%s}", strings.Join(args, ", "))
+	}
+	if len(rets) > 0 {
+		fmt.Fprintf(w, ", Results: make([]any, %d)", len(rets))
+	}
+	fmt.Fprintf(w, "}\n\tdi.Intercept(call, p.interceptors, func(call *di.Call) {\n")
+	w.WriteString(strings.Join(unpack, ""))
+	target := fmt.Sprintf("p.target.%s(%s)", m.Name(), strings.Join(forward, ", "))
+	if len(rets) > 0 {
+		slots := make([]string, len(rets))
+		for i := range rets {
+			slots[i] = fmt.Sprintf("call.Results[%d]", i)
+		}
+		fmt.Fprintf(w, "\t\t%s := %s\n", strings.Join(rets, ", "), target)
+		fmt.Fprintf(w, "\t\t%s = %s\n", strings.Join(slots, ", "), strings.Join(rets, ", "))
+	} else {
+		fmt.Fprintf(w, "\t\t%s\n", target)
+	}
+	fmt.Fprintf(w, "\t})\n")
+	if len(rets) > 0 {
+		w.WriteString(strings.Join(convert, ""))
+		fmt.Fprintf(w, "\treturn %s\n", strings.Join(rets, ", "))
+	}
+	fmt.Fprintf(w, "}\n")
+}
+
+// imports tracks the packages the generated code refers to and the names
+// they are imported under
+type imports struct {
+	self  *types.Package
+	names map[string]string
+	used  map[string]bool
+	taken map[string]bool
+	// aliased lists the packages imported under a name other than their own
+	aliased []string
+}
+
+// newImports returns the imports of generated code in the package self. di
+// and fmt keep their names, and the names of the generated variables are not
+// used for packages.
+func newImports(self *types.Package) *imports {
+	return &imports{
+		self:  self,
+		names: map[string]string{diPath: "di", "fmt": "fmt"},
+		used:  map[string]bool{diPath: true},
+		taken: map[string]bool{"di": true, "fmt": true, "p": true, "call": true},
+	}
+}
+
+// qualify returns the name p is imported under, importing it on first use
+func (im *imports) qualify(p *types.Package) string {
+	if p == im.self {
+		return ""
+	}
+	im.used[p.Path()] = true
+	if name, ok := im.names[p.Path()]; ok {
+		return name
+	}
+	name := p.Name()
+	for i := 2; im.taken[name] || im.self.Scope().Lookup(name) != nil; i++ {
+		name = fmt.Sprintf("%s%d", p.Name(), i)
+	}
+	im.names[p.Path()] = name
+	im.taken[name] = true
+	if name != p.Name() {
+		im.aliased = append(im.aliased, p.Path())
+	}
+	return name
+}
+
+// use imports the package path, one of the packages with a reserved name
+func (im *imports) use(path string) {
+	im.used[path] = true
+}
+
+// write writes the import specs, the standard library first
+func (im *imports) write(w io.Writer) {
+	var std, other []string
+	for path := range im.used {
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	for i, group := range [][]string{std, other} {
+		if i > 0 && len(std) > 0 {
+			fmt.Fprintln(w)
+		}
+		for _, path := range group {
+			if slices.Contains(im.aliased, path) {
+				fmt.Fprintf(w, "\t%s %q\n", im.names[path], path)
+			} else {
+				fmt.Fprintf(w, "\t%q\n", path)
+			}
+		}
+	}
+}
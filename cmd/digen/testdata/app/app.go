@@ -0,0 +1,43 @@
+package app
+
+import (
+	"errors"
+
+	"github.com/ryanbekhen/di"
+)
+
+// config clashes with the variable name digen would pick for Config
+var config = "app.yaml"
+
+type Config struct{ Path string }
+
+type DB struct{ cfg Config }
+
+type UserRepository struct {
+	db *DB
+	c  *di.Container
+}
+
+type Service struct{ users *UserRepository }
+
+//digen:provide
+func NewService(users *UserRepository) *Service { return &Service{users: users} }
+
+//digen:provide
+func NewUserRepository(db *DB, c *di.Container) *UserRepository {
+	return &UserRepository{db: db, c: c}
+}
+
+//digen:provide
+func NewDB(cfg Config) (*DB, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("no config")
+	}
+	return &DB{cfg: cfg}, nil
+}
+
+//digen:provide
+func NewConfig() Config { return Config{Path: config} }
+
+// NewUnmarked is not wired
+func NewUnmarked() *Service { return nil }
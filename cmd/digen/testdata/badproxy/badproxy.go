@@ -0,0 +1,13 @@
+package badproxy
+
+//digen:proxy
+type Config struct{}
+
+//digen:proxy
+type Repository[T any] interface{ Get() T }
+
+//digen:proxy
+type Empty interface{}
+
+//digen:proxy
+type Number interface{ ~int | ~float64 }
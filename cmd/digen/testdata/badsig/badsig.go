@@ -0,0 +1,12 @@
+package badsig
+
+type Pool struct{}
+
+//digen:provide
+func (p *Pool) Clone() *Pool { return p }
+
+//digen:provide
+func NewPool(sizes ...int) *Pool { return &Pool{} }
+
+//digen:provide
+func NewPools() (*Pool, *Pool) { return nil, nil }
@@ -0,0 +1,11 @@
+package cycle
+
+type A struct{}
+
+type B struct{}
+
+//digen:provide
+func NewA(B) A { return A{} }
+
+//digen:provide
+func NewB(A) B { return B{} }
@@ -0,0 +1,9 @@
+package duplicate
+
+type Cache struct{}
+
+//digen:provide
+func NewMemoryCache() *Cache { return &Cache{} }
+
+//digen:provide
+func NewRedisCache() *Cache { return &Cache{} }
@@ -0,0 +1,7 @@
+module example.com/digen/testdata
+
+go 1.25
+
+require github.com/ryanbekhen/di v1.0.0
+
+replace github.com/ryanbekhen/di => ../../..
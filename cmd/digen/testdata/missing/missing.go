@@ -0,0 +1,8 @@
+package missing
+
+type Clock struct{}
+
+type Scheduler struct{}
+
+//digen:provide
+func NewScheduler(Clock) *Scheduler { return &Scheduler{} }
@@ -0,0 +1,28 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	stdtime "time"
+)
+
+// time clashes with the name the generated code would import time under
+var time = stdtime.Now
+
+//digen:proxy
+type Mailer interface {
+	Send(ctx context.Context, to string, attachments ...io.Reader) error
+	Pending() (int, stdtime.Duration)
+	Flush()
+}
+
+type (
+	//digen:proxy
+	store interface {
+		io.Closer
+		Get(key string) []byte
+	}
+
+	// Unmarked gets no proxy
+	Unmarked interface{ Run() }
+)
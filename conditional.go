@@ -0,0 +1,11 @@
+package di
+
+// RegisterWhen registers a factory for T only if cond reports true
+func RegisterWhen[T any](cond func() bool, f func() T, opts ...RegisterOption) {
+	RegisterWhenIn(Default(), cond, f, opts...)
+}
+
+// RegisterWhenIn registers a factory for T in c only if cond reports true
+func RegisterWhenIn[T any](c *Container, cond func() bool, f func() T, opts ...RegisterOption) {
+	RegisterFactoryIn(c, f, append(opts, WithCondition(cond))...)
+}
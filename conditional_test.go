@@ -0,0 +1,85 @@
+package di_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type condCache interface{ Name() string }
+
+type condMemory struct{}
+
+func (condMemory) Name() string { return "memory" }
+
+type condRedis struct{}
+
+func (condRedis) Name() string { return "redis" }
+
+func TestRegisterWhenFollowsCondition(t *testing.T) {
+	c := di.New()
+	di.RegisterWhenIn(c, func() bool { return false }, func() condCache { return condRedis{} })
+	if _, err := di.ResolveIn[condCache](c); !errors.Is(err, di.ErrNotRegistered) {
+		t.Fatalf("a registration with a false condition resolved: %v", err)
+	}
+
+	di.RegisterWhenIn(c, func() bool { return true }, func() condCache { return condMemory{} })
+	if got := di.MustResolveIn[condCache](c).Name(); got != "memory" {
+		t.Fatalf("resolved %q, want memory", got)
+	}
+}
+
+func TestFailedConditionKeepsExistingBinding(t *testing.T) {
+	c := di.New()
+	di.RegisterFactoryIn(c, func() condCache { return condMemory{} })
+	di.RegisterFactoryIn(c, func() condCache { return condRedis{} }, di.WithCondition(func() bool { return false }))
+
+	if got := di.MustResolveIn[condCache](c).Name(); got != "memory" {
+		t.Fatalf("resolved %q, want the existing binding", got)
+	}
+}
+
+func TestConditionEvaluatedOnceAtRegistration(t *testing.T) {
+	c := di.New()
+	calls := 0
+	enabled := true
+	di.RegisterFactoryIn(c, func() condCache { return condRedis{} }, di.WithCondition(func() bool {
+		calls++
+		return enabled
+	}))
+	enabled = false
+
+	for range 3 {
+		di.MustResolveIn[condCache](c)
+	}
+	if calls != 1 {
+		t.Fatalf("condition evaluated %d times, want once", calls)
+	}
+}
+
+func TestWithEnvSelectsImplementation(t *testing.T) {
+	t.Setenv("COND_CACHE", "redis")
+	c := di.New()
+	di.RegisterFactoryIn(c, func() condCache { return condMemory{} }, di.WithEnv("COND_CACHE", "memory"))
+	di.RegisterFactoryIn(c, func() condCache { return condRedis{} }, di.WithEnv("COND_CACHE", "redis"))
+
+	if got := di.MustResolveIn[condCache](c).Name(); got != "redis" {
+		t.Fatalf("resolved %q, want redis", got)
+	}
+}
+
+func TestFailedConditionLeavesGroupOut(t *testing.T) {
+	c := di.New()
+	di.RegisterFactoryIn(c, func() condCache { return condMemory{} }, di.WithGroup("caches"))
+	di.RegisterFactoryIn(c, func() condCache { return condRedis{} }, di.WithGroup("caches"),
+		di.WithCondition(func() bool { return false }))
+
+	caches, err := di.ResolveGroupIn[condCache](c, "caches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(caches) != 1 || caches[0].Name() != "memory" {
+		t.Fatalf("group holds %v, want only memory", caches)
+	}
+}
@@ -0,0 +1,159 @@
+package di
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationType is the reflected time.Duration type
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// RegisterConfig fills a config struct from environment variables and
+// registers it as a singleton. Fields are read from the variable named in
+// their `env:"PORT"` tag; `env:"PORT,default=8080"` supplies a default and
+// `env:"DSN,required"` fails if the variable is unset. Untagged struct fields
+// are filled recursively. Supported field types are strings, bools, integers,
+// floats, time.Duration and comma-separated []string.
+func RegisterConfig[T any](opts ...RegisterOption) error {
+	return RegisterConfigIn[T](Default(), opts...)
+}
+
+// RegisterConfigIn fills a config struct from environment variables and registers it in c
+func RegisterConfigIn[T any](c *Container, opts ...RegisterOption) error {
+	var cfg T
+	v := reflect.ValueOf(&cfg).Elem()
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("config must be a struct, got %v", v.Type())
+	}
+	if err := loadEnv(v); err != nil {
+		return fmt.Errorf("load %v: %w", v.Type(), err)
+	}
+
+	f := c.newFactory(typeKey[T](), Singleton, func(Resolver) (any, func(), error) {
+		return cfg, nil, nil
+	})
+	f.external = true
+	return c.store(f, opts)
+}
+
+// loadEnv fills the tagged fields of the struct v from the environment
+func loadEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup("env")
+		if !ok {
+			if field.Type.Kind() == reflect.Struct && field.Type != durationType {
+				if err := loadEnv(v.Field(i)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		name, def, required, err := parseEnvTag(tag)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		value, ok := os.LookupEnv(name)
+		switch {
+		case ok:
+		case required:
+			return fmt.Errorf("field %s: environment variable %s is required", field.Name, name)
+		case def != nil:
+			value = *def
+		default:
+			continue
+		}
+		if err := setEnvField(v.Field(i), value); err != nil {
+			return fmt.Errorf("field %s: %s: %w", field.Name, name, err)
+		}
+	}
+	return nil
+}
+
+// parseEnvTag reads the variable name, default and required flag of an env tag
+func parseEnvTag(tag string) (name string, def *string, required bool, err error) {
+	name, rest, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return "", nil, false, fmt.Errorf("env tag has no variable name")
+	}
+	for rest != "" {
+		var opt string
+		if strings.HasPrefix(rest, "default=") {
+			// the default runs to the end of the tag so it may contain commas
+			value := strings.TrimPrefix(rest, "default=")
+			def, rest = &value, ""
+			continue
+		}
+		opt, rest, _ = strings.Cut(rest, ",")
+		switch opt {
+		case "required":
+			required = true
+		default:
+			return "", nil, false, fmt.Errorf("unsupported tag option %q", opt)
+		}
+	}
+	return name, def, required, nil
+}
+
+// setEnvField parses value into the field v
+func setEnvField(v reflect.Value, value string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %v", v.Type())
+		}
+		var items []string
+		if value != "" {
+			items = strings.Split(value, ",")
+		}
+		v.Set(reflect.ValueOf(items).Convert(v.Type()))
+	default:
+		return fmt.Errorf("unsupported type %v", v.Type())
+	}
+	return nil
+}
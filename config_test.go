@@ -0,0 +1,88 @@
+package di_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanbekhen/di"
+)
+
+type cfgDatabase struct {
+	DSN      string `env:"CFG_DSN,required"`
+	MaxConns int    `env:"CFG_MAX_CONNS,default=10"`
+}
+
+type cfgServer struct {
+	Port     uint16        `env:"CFG_PORT,default=8080"`
+	Debug    bool          `env:"CFG_DEBUG"`
+	Timeout  time.Duration `env:"CFG_TIMEOUT,default=5s"`
+	Ratio    float64       `env:"CFG_RATIO"`
+	Hosts    []string      `env:"CFG_HOSTS,default=a,b"`
+	Database cfgDatabase
+	internal string
+}
+
+func TestRegisterConfigReadsEnvironment(t *testing.T) {
+	t.Setenv("CFG_DSN", "postgres://db")
+	t.Setenv("CFG_PORT", "9090")
+	t.Setenv("CFG_DEBUG", "true")
+	t.Setenv("CFG_RATIO", "0.25")
+	t.Setenv("CFG_HOSTS", "x,y,z")
+
+	c := di.New()
+	if err := di.RegisterConfigIn[cfgServer](c); err != nil {
+		t.Fatal(err)
+	}
+	cfg := di.MustResolveIn[cfgServer](c)
+
+	want := cfgServer{
+		Port:     9090,
+		Debug:    true,
+		Timeout:  5 * time.Second,
+		Ratio:    0.25,
+		Hosts:    []string{"x", "y", "z"},
+		Database: cfgDatabase{DSN: "postgres://db", MaxConns: 10},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("config %+v, want %+v", cfg, want)
+	}
+}
+
+func TestRegisterConfigDefaultMayContainCommas(t *testing.T) {
+	t.Setenv("CFG_DSN", "postgres://db")
+	c := di.New()
+	if err := di.RegisterConfigIn[cfgServer](c); err != nil {
+		t.Fatal(err)
+	}
+	if hosts := di.MustResolveIn[cfgServer](c).Hosts; !reflect.DeepEqual(hosts, []string{"a", "b"}) {
+		t.Fatalf("hosts %v, want the default [a b]", hosts)
+	}
+}
+
+func TestRegisterConfigErrors(t *testing.T) {
+	t.Setenv("CFG_DSN", "")
+	t.Setenv("CFG_PORT", "70000")
+
+	c := di.New()
+	err := di.RegisterConfigIn[cfgServer](c)
+	if err == nil || !strings.Contains(err.Error(), "CFG_PORT") {
+		t.Fatalf("got %v, want an error naming CFG_PORT", err)
+	}
+	if di.ContainsIn[cfgServer](c) {
+		t.Fatal("a config that failed to load was registered")
+	}
+
+	if err := di.RegisterConfigIn[string](c); err == nil {
+		t.Fatal("registered a non-struct config")
+	}
+}
+
+func TestRegisterConfigRequiresVariable(t *testing.T) {
+	c := di.New()
+	err := di.RegisterConfigIn[cfgDatabase](c)
+	if err == nil || !strings.Contains(err.Error(), "CFG_DSN is required") {
+		t.Fatalf("got %v, want the missing CFG_DSN reported", err)
+	}
+}
@@ -0,0 +1,186 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// errorType is the reflected error interface
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// cleanupType is the reflected type of the cleanup functions constructors may return
+var cleanupType = reflect.TypeOf(func() {})
+
+// RegisterConstructor registers a constructor whose parameters are resolved
+// from the container. fn must have one of the forms func(D1, D2, ...) T,
+// func(D1, D2, ...) (T, error), func(D1, D2, ...) (T, func()) or
+// func(D1, D2, ...) (T, func(), error), the shapes google/wire accepts; it is
+// registered as the factory for T. A returned cleanup function runs on Reset
+// and Shutdown. A variadic parameter ...O receives every binding of O, such as
+// the values added with RegisterOptions.
+func RegisterConstructor(fn any, opts ...RegisterOption) error {
+	return Default().RegisterConstructor(fn, opts...)
+}
+
+// RegisterConstructor registers a constructor in c whose parameters are resolved from c
+func (c *Container) RegisterConstructor(fn any, opts ...RegisterOption) error {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return fmt.Errorf("constructor must be a non-nil func, got %T", fn)
+	}
+	return c.addConstructor(v, "", Singleton, opts)
+}
+
+// addConstructor registers the constructor v under name with the given
+// lifetime. Its parameters are resolved from the container, or the scope for
+// scoped and transient instances built in a scope.
+func (c *Container) addConstructor(v reflect.Value, name string, lifetime Lifetime, opts []RegisterOption) error {
+	t := v.Type()
+	cleanup, fallible, err := constructorResults(t)
+	if err != nil {
+		return err
+	}
+
+	return c.addFactory(key{typ: t.Out(0), name: name}, lifetime, opts, func(r Resolver) (any, func(), error) {
+		out, err := call(r, v)
+		if err != nil {
+			return nil, nil, err
+		}
+		if fallible >= 0 && !out[fallible].IsNil() {
+			return nil, nil, out[fallible].Interface().(error)
+		}
+		var done func()
+		if cleanup >= 0 {
+			done = out[cleanup].Interface().(func())
+		}
+		return out[0].Interface(), done, nil
+	}, paramKeys(t)...)
+}
+
+// constructorResults returns the positions of the cleanup function and error
+// a constructor of type t returns, or -1 for those it does not return
+func constructorResults(t reflect.Type) (cleanup, fallible int, err error) {
+	cleanup, fallible = -1, -1
+	switch {
+	case t.NumOut() == 1:
+	case t.NumOut() == 2 && t.Out(1) == errorType:
+		fallible = 1
+	case t.NumOut() == 2 && t.Out(1) == cleanupType:
+		cleanup = 1
+	case t.NumOut() == 3 && t.Out(1) == cleanupType && t.Out(2) == errorType:
+		cleanup, fallible = 1, 2
+	default:
+		return -1, -1, fmt.Errorf("constructor %v must return T, (T, error), (T, func()) or (T, func(), error)", t)
+	}
+	return cleanup, fallible, nil
+}
+
+// ProviderSet groups constructors so they can be registered together, like a
+// google/wire provider set
+type ProviderSet []any
+
+// NewSet returns a provider set of constructors, other sets and the entries
+// built by Binding, Value, Struct and FieldsOf. Sets and []any slices of
+// providers are flattened.
+func NewSet(providers ...any) ProviderSet {
+	var set ProviderSet
+	for _, p := range providers {
+		switch nested := p.(type) {
+		case ProviderSet:
+			set = append(set, NewSet(nested...)...)
+		case []any:
+			set = append(set, NewSet(nested...)...)
+		default:
+			set = append(set, p)
+		}
+	}
+	return set
+}
+
+// RegisterConstructors registers every constructor, and every constructor of
+// the provider sets, with RegisterConstructor in the default container
+func RegisterConstructors(providers ...any) error {
+	return Default().RegisterConstructors(providers...)
+}
+
+// RegisterConstructors registers every constructor, and every constructor of
+// the provider sets, in c. The constructors may depend on each other in any
+// order. All of them are attempted; the errors are joined.
+func (c *Container) RegisterConstructors(providers ...any) error {
+	var errs []error
+	for _, p := range NewSet(providers...) {
+		switch p := p.(type) {
+		case setEntry:
+			errs = append(errs, p.registerIn(c))
+		default:
+			if isWireValue(p) {
+				errs = append(errs, fmt.Errorf("%T is empty at run time; list its providers with NewSet", p))
+				continue
+			}
+			errs = append(errs, c.RegisterConstructor(p))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// paramKeys returns the keys of the parameters of a func type. A variadic
+// parameter is left out, since it accepts any number of bindings.
+func paramKeys(t reflect.Type) []key {
+	n := t.NumIn()
+	if t.IsVariadic() {
+		n--
+	}
+	keys := make([]key, n)
+	for i := range keys {
+		keys[i] = depKeyOf(t.In(i))
+	}
+	return keys
+}
+
+// call resolves the parameters of fn from r and calls it. A variadic
+// parameter receives every binding of its element type.
+func call(r Resolver, fn reflect.Value) ([]reflect.Value, error) {
+	t := fn.Type()
+	args := make([]reflect.Value, t.NumIn())
+	for i := range args {
+		in := t.In(i)
+		if t.IsVariadic() && i == len(args)-1 {
+			all, err := resolveSlice(r, in)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = all
+			return fn.CallSlice(args), nil
+		}
+		v, err := resolveType(r, in, keyOf(in))
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			args[i] = reflect.Zero(in)
+		} else {
+			args[i] = reflect.ValueOf(v)
+		}
+	}
+	return fn.Call(args), nil
+}
+
+// resolveSlice resolves every binding of the element type of the slice type t
+// from r, by priority and then in registration order
+func resolveSlice(r Resolver, t reflect.Type) (reflect.Value, error) {
+	bindings := prioritized(r.owner().bindings(t.Elem()))
+	all := reflect.MakeSlice(t, 0, len(bindings))
+	for _, f := range bindings {
+		v, err := resolveKey(r, f.key)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if v == nil {
+			all = reflect.Append(all, reflect.Zero(t.Elem()))
+		} else {
+			all = reflect.Append(all, reflect.ValueOf(v))
+		}
+	}
+	return all, nil
+}
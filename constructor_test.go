@@ -0,0 +1,94 @@
+package di_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type ctorConfig struct{ dsn string }
+type ctorDB struct {
+	cfg    *ctorConfig
+	closed bool
+}
+type ctorRepo struct{ db *ctorDB }
+
+func TestRegisterConstructorResolvesParameters(t *testing.T) {
+	c := di.New()
+	di.RegisterIn(c, &ctorConfig{dsn: "postgres://"})
+	if err := c.RegisterConstructor(func(db *ctorDB) *ctorRepo { return &ctorRepo{db: db} }); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RegisterConstructor(func(cfg *ctorConfig) (*ctorDB, error) { return &ctorDB{cfg: cfg}, nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := di.MustResolveIn[*ctorRepo](c)
+	if repo.db == nil || repo.db.cfg.dsn != "postgres://" {
+		t.Fatalf("repo built with %+v", repo.db)
+	}
+	if di.MustResolveIn[*ctorRepo](c) != repo {
+		t.Fatal("the constructor of a singleton ran twice")
+	}
+}
+
+func TestRegisterConstructorReportsMissingDependency(t *testing.T) {
+	c := di.New()
+	if err := c.RegisterConstructor(func(cfg *ctorConfig) *ctorDB { return &ctorDB{cfg: cfg} }); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := di.ResolveIn[*ctorDB](c)
+	if !errors.Is(err, di.ErrNotRegistered) {
+		t.Fatalf("ResolveIn() error = %v, want ErrNotRegistered", err)
+	}
+	var missing *di.ResolveError
+	if !errors.As(err, &missing) || missing.Type != "*di_test.ctorConfig" {
+		t.Fatalf("ResolveIn() error = %v, want the missing *ctorConfig", err)
+	}
+}
+
+func TestRegisterConstructorReturnsFactoryError(t *testing.T) {
+	c := di.New()
+	boom := errors.New("boom")
+	if err := c.RegisterConstructor(func() (*ctorDB, error) { return nil, boom }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := di.ResolveIn[*ctorDB](c); !errors.Is(err, boom) {
+		t.Fatalf("ResolveIn() error = %v, want %v", err, boom)
+	}
+}
+
+func TestRegisterConstructorRunsCleanupOnReset(t *testing.T) {
+	c := di.New()
+	err := c.RegisterConstructor(func() (*ctorDB, func(), error) {
+		db := &ctorDB{}
+		return db, func() { db.closed = true }, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := di.MustResolveIn[*ctorDB](c)
+	c.Reset()
+	if !db.closed {
+		t.Fatal("Reset did not run the cleanup function")
+	}
+}
+
+func TestRegisterConstructorRejectsInvalidFunctions(t *testing.T) {
+	c := di.New()
+	for name, fn := range map[string]any{
+		"not a func":     &ctorDB{},
+		"nil func":       (func() *ctorDB)(nil),
+		"no results":     func() {},
+		"second result":  func() (*ctorDB, int) { return nil, 0 },
+		"too many":       func() (*ctorDB, func(), error, int) { return nil, nil, nil, 0 },
+		"error not last": func() (*ctorDB, error, func()) { return nil, nil, nil },
+	} {
+		if err := c.RegisterConstructor(fn); err == nil {
+			t.Errorf("%s: RegisterConstructor() accepted %T", name, fn)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package di
+
+import (
+	"context"
+	"log"
+)
+
+// contextKey is the context key under which a resolver is stored
+type contextKey struct{}
+
+// WithContainer returns a copy of ctx that carries c
+func WithContainer(ctx context.Context, c *Container) context.Context {
+	return context.WithValue(ctx, contextKey{}, Resolver(c))
+}
+
+// WithScope returns a copy of ctx that carries s, so scoped instances
+// resolved through ctx belong to it
+func WithScope(ctx context.Context, s *Scope) context.Context {
+	return context.WithValue(ctx, contextKey{}, Resolver(s))
+}
+
+// FromContext returns the container carried by ctx, or the container of the
+// scope it carries. It returns the default container if ctx carries neither.
+func FromContext(ctx context.Context) *Container {
+	return resolverFrom(ctx).owner()
+}
+
+// ScopeOf returns the scope carried by ctx, if any
+func ScopeOf(ctx context.Context) (*Scope, bool) {
+	s, ok := ctx.Value(contextKey{}).(*Scope)
+	return s, ok
+}
+
+// ScopeFromContext opens a scope on the default container that is closed when
+// ctx is done
+func ScopeFromContext(ctx context.Context) *Scope {
+	return Default().ScopeFromContext(ctx)
+}
+
+// ScopeFromContext opens a scope on c that is closed when ctx is done, so its
+// scoped instances are torn down even if the caller never calls Close.
+// Closing it earlier is allowed and releases the context. Errors from the
+// automatic close are logged.
+func (c *Container) ScopeFromContext(ctx context.Context) *Scope {
+	s := c.NewScope()
+	s.detach = context.AfterFunc(ctx, func() {
+		if err := s.Close(); err != nil {
+			if l := c.logger.Load(); l != nil {
+				l.Error("closing scope", "err", err)
+			} else {
+				log.Printf("di: closing scope: %v", err)
+			}
+		}
+	})
+	return s
+}
+
+// resolverFrom returns the container or scope carried by ctx, or the default container
+func resolverFrom(ctx context.Context) Resolver {
+	if r, ok := ctx.Value(contextKey{}).(Resolver); ok {
+		return r
+	}
+	return Default()
+}
+
+// ResolveCtx retrieves an instance from the scope or container carried by ctx,
+// falling back to the default container. Factories registered with
+// RegisterFactoryCtx and instrumentation receive ctx, so construction can
+// honor its deadline and cancellation.
+func ResolveCtx[T any](ctx context.Context) (T, error) {
+	return ResolveIn[T](bind(resolverFrom(ctx), ctx))
+}
+
+// MustResolveCtx is like ResolveCtx but panics if the instance cannot be resolved
+func MustResolveCtx[T any](ctx context.Context) T {
+	v, err := ResolveCtx[T](ctx)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// RegisterFactoryCtx registers a factory that receives the context of the
+// resolution that builds it
+func RegisterFactoryCtx[T any](f func(ctx context.Context) (T, error), opts ...RegisterOption) {
+	RegisterFactoryCtxIn(Default(), f, opts...)
+}
+
+// RegisterFactoryCtxIn registers a factory in c that receives the context of
+// the resolution that builds it: the ctx passed to ResolveCtx, Start or
+// InitializeAll, or context.Background for other resolutions. The context is
+// passed along by the resolver each factory receives, so it also reaches
+// factories built for the dependencies of other factories, except those
+// resolved from the *Scope a RegisterScoped factory receives. A singleton is
+// built with the context of the first resolution; concurrent resolutions wait
+// for it.
+func RegisterFactoryCtxIn[T any](c *Container, f func(ctx context.Context) (T, error), opts ...RegisterOption) {
+	c.registerFactory(typeKey[T](), Singleton, opts, func(r Resolver) (any, func(), error) {
+		v, err := f(contextOf(r))
+		return v, nil, err
+	})
+}
+
+// boundResolver is a container or scope that carries the context of the
+// resolution in progress. Factories receive it, so the resolutions they make
+// see the same context.
+type boundResolver struct {
+	Resolver
+	ctx context.Context
+}
+
+// bind returns the container or scope behind r carrying ctx
+func bind(r Resolver, ctx context.Context) Resolver {
+	r = unbind(r)
+	if ctx == context.Background() {
+		return r
+	}
+	return boundResolver{Resolver: r, ctx: ctx}
+}
+
+// unbind returns the container or scope behind r, without a context
+func unbind(r Resolver) Resolver {
+	if b, ok := r.(boundResolver); ok {
+		return b.Resolver
+	}
+	return r
+}
+
+// contextOf returns the context carried by r, or context.Background
+func contextOf(r Resolver) context.Context {
+	if b, ok := r.(boundResolver); ok {
+		return b.ctx
+	}
+	return context.Background()
+}
@@ -0,0 +1,118 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type ctxKey struct{}
+
+type ctxConn struct{ value any }
+
+// newCtxContainer returns a container whose *ctxConn records the context it was built with
+func newCtxContainer() *di.Container {
+	c := di.New()
+	di.RegisterFactoryCtxIn(c, func(ctx context.Context) (*ctxConn, error) {
+		return &ctxConn{value: ctx.Value(ctxKey{})}, nil
+	})
+	return c
+}
+
+func TestResolveCtxPassesContextToFactory(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+
+	merged := di.New()
+	if err := merged.Merge(newCtxContainer()); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, c := range map[string]*di.Container{
+		"container": newCtxContainer(),
+		"clone":     newCtxContainer().Clone(),
+		"child":     newCtxContainer().Child(),
+		"merged":    merged,
+	} {
+		t.Run(name, func(t *testing.T) {
+			conn, err := di.ResolveCtx[*ctxConn](di.WithContainer(ctx, c))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if conn.value != "request" {
+				t.Fatalf("factory got context value %v, want request", conn.value)
+			}
+		})
+	}
+}
+
+func TestInitializeAllPassesContextToFactory(t *testing.T) {
+	c := newCtxContainer()
+	ctx := context.WithValue(context.Background(), ctxKey{}, "warmup")
+	if err := c.InitializeAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if conn := di.MustResolveIn[*ctxConn](c); conn.value != "warmup" {
+		t.Fatalf("factory got context value %v, want warmup", conn.value)
+	}
+}
+
+func TestStartPassesContextToFactory(t *testing.T) {
+	c := di.New()
+	di.RegisterFactoryCtxIn(c, func(ctx context.Context) (*ctxConn, error) {
+		return &ctxConn{value: ctx.Value(ctxKey{})}, nil
+	}, di.OnStart(func(context.Context, *ctxConn) error { return nil }))
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "start")
+	if err := c.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if conn := di.MustResolveIn[*ctxConn](c); conn.value != "start" {
+		t.Fatalf("factory got context value %v, want start", conn.value)
+	}
+}
+
+type ctxRepo struct{ conn *ctxConn }
+
+func TestResolveCtxReachesDependencies(t *testing.T) {
+	c := newCtxContainer()
+	if err := c.RegisterConstructor(func(conn *ctxConn) *ctxRepo { return &ctxRepo{conn: conn} }); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(di.WithContainer(context.Background(), c), ctxKey{}, "request")
+	repo, err := di.ResolveCtx[*ctxRepo](ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.conn.value != "request" {
+		t.Fatalf("dependency got context value %v, want request", repo.conn.value)
+	}
+}
+
+func TestResolveCtxIsolatesConcurrentResolutions(t *testing.T) {
+	c := di.New()
+	started, release := make(chan struct{}), make(chan struct{})
+	di.RegisterFactoryCtxIn(c, func(ctx context.Context) (*ctxConn, error) {
+		close(started)
+		<-release
+		return &ctxConn{value: ctx.Value(ctxKey{})}, nil
+	})
+	di.RegisterTransientIn(c, func() *ctxRepo { return &ctxRepo{} })
+
+	done := make(chan *ctxConn)
+	go func() {
+		ctx := context.WithValue(di.WithContainer(context.Background(), c), ctxKey{}, "first")
+		done <- di.MustResolveCtx[*ctxConn](ctx)
+	}()
+	<-started
+
+	// a resolution on another goroutine while the first one is building
+	other := context.WithValue(di.WithContainer(context.Background(), c), ctxKey{}, "second")
+	di.MustResolveCtx[*ctxRepo](other)
+	close(release)
+
+	if conn := <-done; conn.value != "first" {
+		t.Fatalf("factory got context value %v, want first", conn.value)
+	}
+}
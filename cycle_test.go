@@ -0,0 +1,95 @@
+package di_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ryanbekhen/di"
+)
+
+type cycleA struct{}
+type cycleB struct{}
+
+func TestCycleOnOneGoroutine(t *testing.T) {
+	c := di.New()
+	di.RegisterFactoryEIn(c, func() (*cycleA, error) {
+		_, err := di.ResolveIn[*cycleB](c)
+		return &cycleA{}, err
+	})
+	di.RegisterFactoryEIn(c, func() (*cycleB, error) {
+		_, err := di.ResolveIn[*cycleA](c)
+		return &cycleB{}, err
+	})
+
+	if _, err := di.ResolveIn[*cycleA](c); !errors.Is(err, di.ErrCycle) {
+		t.Fatalf("ResolveIn() error = %v, want ErrCycle", err)
+	}
+}
+
+func TestCycleAcrossGoroutines(t *testing.T) {
+	c := di.New()
+	// both factories start before either resolves its dependency, so each
+	// goroutine holds one singleton while it asks for the other
+	var started sync.WaitGroup
+	var startedA, startedB sync.Once
+	started.Add(2)
+	di.RegisterFactoryEIn(c, func() (*cycleA, error) {
+		startedA.Do(started.Done)
+		started.Wait()
+		_, err := di.ResolveIn[*cycleB](c)
+		return &cycleA{}, err
+	})
+	di.RegisterFactoryEIn(c, func() (*cycleB, error) {
+		startedB.Do(started.Done)
+		started.Wait()
+		_, err := di.ResolveIn[*cycleA](c)
+		return &cycleB{}, err
+	})
+
+	errs := make(chan error, 2)
+	go func() {
+		_, err := di.ResolveIn[*cycleA](c)
+		errs <- err
+	}()
+	go func() {
+		_, err := di.ResolveIn[*cycleB](c)
+		errs <- err
+	}()
+
+	for range 2 {
+		select {
+		case err := <-errs:
+			if !errors.Is(err, di.ErrCycle) {
+				t.Errorf("ResolveIn() error = %v, want ErrCycle", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("concurrent resolutions of a cycle deadlocked")
+		}
+	}
+}
+
+func TestConcurrentResolutionsShareSingleton(t *testing.T) {
+	c := di.New()
+	release := make(chan struct{})
+	di.RegisterFactoryIn(c, func() *cycleB { return &cycleB{} })
+	di.RegisterFactoryIn(c, func() *cycleA {
+		<-release
+		di.MustResolveIn[*cycleB](c)
+		return &cycleA{}
+	})
+
+	results := make(chan *cycleA, 4)
+	for range 4 {
+		go func() { results <- di.MustResolveIn[*cycleA](c) }()
+	}
+	close(release)
+
+	first := <-results
+	for range 3 {
+		if a := <-results; a != first {
+			t.Fatal("concurrent resolutions built more than one singleton")
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package di
+
+// Decorate wraps every instance of T built by the default container with d
+// before it is cached. Instances resolved before the call are rebuilt.
+func Decorate[T any](d func(T) T) {
+	DecorateIn(Default(), d)
+}
+
+// DecorateIn wraps every instance of T built by c with d before it is cached
+func DecorateIn[T any](c *Container, d func(T) T) {
+	c.decorate(typeKey[T](), func(v any) any {
+		instance, _ := v.(T)
+		return d(instance)
+	})
+}
+
+// decorate adds d to the decorators of k and drops the cached instance so it is rebuilt
+func (c *Container) decorate(k key, d func(any) any) {
+	c.decoratorsMu.Lock()
+	c.decorators[k] = append(c.decorators[k], d)
+	c.decoratorsMu.Unlock()
+
+	if f, ok := c.factories.load(k); ok {
+		if once := f.once; once != nil {
+			once.Reset()
+		}
+	}
+	c.instances.delete(k)
+}
+
+// applyDecorators wraps v with the decorators of k in the order they were added.
+// Every binding of a multi-registered type shares the decorators of the type.
+func (c *Container) applyDecorators(k key, v any) any {
+	k.id = 0
+
+	c.decoratorsMu.RLock()
+	decorators := c.decorators[k]
+	c.decoratorsMu.RUnlock()
+
+	for _, d := range decorators {
+		v = d(v)
+	}
+	return v
+}
@@ -0,0 +1,18 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+func TestDecorateWrapsBuiltInstances(t *testing.T) {
+	c := di.New()
+	di.RegisterFactoryIn(c, func() string { return "base" })
+	di.DecorateIn(c, func(s string) string { return s + "+a" })
+	di.DecorateIn(c, func(s string) string { return s + "+b" })
+
+	if got := di.MustResolveIn[string](c); got != "base+a+b" {
+		t.Fatalf("MustResolveIn() = %q, want base+a+b", got)
+	}
+}
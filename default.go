@@ -0,0 +1,28 @@
+package di
+
+// RegisterDefault registers instance for T unless T is already registered.
+// Any later registration of T replaces the default, so libraries can ship
+// defaults that applications override regardless of registration order.
+func RegisterDefault[T any](instance T, opts ...RegisterOption) {
+	RegisterDefaultIn(Default(), instance, opts...)
+}
+
+// RegisterDefaultIn registers instance for T in c unless T is already registered
+func RegisterDefaultIn[T any](c *Container, instance T, opts ...RegisterOption) {
+	RegisterIn(c, instance, append(opts, asDefault)...)
+}
+
+// RegisterDefaultFactory registers a factory for T unless T is already registered
+func RegisterDefaultFactory[T any](f func() T, opts ...RegisterOption) {
+	RegisterDefaultFactoryIn(Default(), f, opts...)
+}
+
+// RegisterDefaultFactoryIn registers a factory for T in c unless T is already registered
+func RegisterDefaultFactoryIn[T any](c *Container, f func() T, opts ...RegisterOption) {
+	RegisterFactoryIn(c, f, append(opts, asDefault)...)
+}
+
+// asDefault marks a registration as a default
+func asDefault(f *factory) {
+	f.fallback = true
+}
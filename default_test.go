@@ -0,0 +1,59 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type defaultClock struct{ name string }
+
+func TestRegisterDefaultUsedWithoutRegistration(t *testing.T) {
+	c := di.New()
+	di.RegisterDefaultIn(c, &defaultClock{name: "system"})
+	if clock := di.MustResolveIn[*defaultClock](c); clock.name != "system" {
+		t.Fatalf("resolved clock %q, want system", clock.name)
+	}
+}
+
+func TestRegisterDefaultYieldsToRegistrations(t *testing.T) {
+	before := di.New()
+	di.RegisterIn(before, &defaultClock{name: "fake"})
+	di.RegisterDefaultIn(before, &defaultClock{name: "system"})
+
+	after := di.New()
+	di.RegisterDefaultIn(after, &defaultClock{name: "system"})
+	di.RegisterIn(after, &defaultClock{name: "fake"})
+
+	for name, c := range map[string]*di.Container{"registered before": before, "registered after": after} {
+		if clock := di.MustResolveIn[*defaultClock](c); clock.name != "fake" {
+			t.Errorf("%s: resolved clock %q, want fake", name, clock.name)
+		}
+	}
+}
+
+func TestRegisterDefaultFactoryIsLazy(t *testing.T) {
+	c := di.New()
+	built := 0
+	di.RegisterDefaultFactoryIn(c, func() *defaultClock {
+		built++
+		return &defaultClock{name: "system"}
+	})
+	di.RegisterIn(c, &defaultClock{name: "fake"})
+
+	if clock := di.MustResolveIn[*defaultClock](c); clock.name != "fake" {
+		t.Fatalf("resolved clock %q, want fake", clock.name)
+	}
+	if built != 0 {
+		t.Fatal("the replaced default factory ran")
+	}
+}
+
+func TestFirstDefaultWins(t *testing.T) {
+	c := di.New()
+	di.RegisterDefaultIn(c, &defaultClock{name: "first"})
+	di.RegisterDefaultIn(c, &defaultClock{name: "second"})
+	if clock := di.MustResolveIn[*defaultClock](c); clock.name != "first" {
+		t.Fatalf("resolved clock %q, want first", clock.name)
+	}
+}
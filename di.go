@@ -1,55 +1,621 @@
 package di
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
 	"reflect"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// instances stores singleton instances
-var instances sync.Map
+// Container holds registrations and the instances resolved from them
+type Container struct {
+	// instances stores singleton instances
+	instances shardedMap[any]
+	// factories stores factory functions for lazy initialization
+	factories shardedMap[*factory]
+	// slowFactoryThreshold is the factory run time above which a warning is logged
+	slowFactoryThreshold atomic.Int64
+	// transients counts transient constructions once WarnFrequentTransients is set
+	transients atomic.Pointer[transientWatch]
+	// seq numbers registrations in the order they were made
+	seq atomic.Uint64
 
-// factories stores factory functions for lazy initialization
-var factories sync.Map
+	// hooks holds the resolution hooks
+	hooks hooks
+	// instruments holds the instrumentation observing resolutions and factory runs
+	instruments instruments
+	// metrics receives measurements of resolutions and factory runs, if set
+	metrics atomic.Pointer[Metrics]
+	// logger receives container events, if set
+	logger atomic.Pointer[logger]
+	// logLevels are the levels set by WithLogLevels, if any
+	logLevels atomic.Pointer[LogLevels]
+	// errorFormatter renders the errors of the container, if set
+	errorFormatter atomic.Pointer[ErrorFormatter]
+	// componentLoggers is the attribute naming the component in derived loggers, if enabled
+	componentLoggers atomic.Pointer[string]
+	// subscribers receive the events of the container
+	subscribers subscribers
+	// stats counts resolutions and factory runs per key once enabled
+	stats atomic.Pointer[stats]
+	// tracker follows nested resolutions to record the dependency graph
+	tracker tracker
 
-// typeKey returns a unique string key for any generic type (including interfaces)
-func typeKey[T any]() string {
-	return reflect.TypeOf((*T)(nil)).Elem().String()
+	// decoratorsMu guards decorators
+	decoratorsMu sync.RWMutex
+	// decorators stores the decorators applied to newly built instances per key
+	decorators map[key][]func(any) any
+
+	// builtMu guards built
+	builtMu sync.Mutex
+	// built lists the singletons in the order their construction finished
+	built []*builtInstance
+
+	// changedMu guards changed
+	changedMu sync.Mutex
+	// changed is closed and replaced whenever a registration is added
+	changed chan struct{}
+
+	// parent is consulted for keys with no registration in the container
+	parent *Container
+	// strict rejects registrations for keys that are already registered
+	strict bool
+
+	// modulesMu guards modules
+	modulesMu sync.Mutex
+	// modules lists the installed modules in installation order
+	modules []installedModule
+	// installing is the module whose install function is running, if any
+	installing atomic.Pointer[Module]
+
+	// laterMu guards later
+	laterMu sync.Mutex
+	// later lists the registrations deferred to Build
+	later []laterRegistration
+}
+
+// defaultContainer backs the package-level functions
+var defaultContainer atomic.Pointer[Container]
+
+func init() {
+	defaultContainer.Store(New())
+}
+
+// Option configures a container created by New
+type Option func(c *Container)
+
+// WithStrict makes the container reject registrations for keys that are
+// already registered instead of replacing them. Functions that return an
+// error report the conflict; the others panic with it.
+func WithStrict() Option {
+	return func(c *Container) {
+		c.strict = true
+	}
+}
+
+// New creates an empty container
+func New(opts ...Option) *Container {
+	c := &Container{
+		decorators: make(map[key][]func(any) any),
+		changed:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Default returns the container used by the package-level functions
+func Default() *Container {
+	return defaultContainer.Load()
+}
+
+// SetDefault makes c the container used by the package-level functions and
+// returns a function that restores the previous one
+func SetDefault(c *Container) (restore func()) {
+	previous := defaultContainer.Swap(c)
+	return func() {
+		defaultContainer.Store(previous)
+	}
+}
+
+// key identifies a registration by type and optional name
+type key struct {
+	typ  reflect.Type
+	name string
+	// id distinguishes the anonymous bindings added by RegisterMulti
+	id uint64
+}
+
+// String returns the type name, followed by the registration name if any
+func (k key) String() string {
+	switch {
+	case k.id != 0:
+		return fmt.Sprintf("%s #%d", k.typ, k.id)
+	case k.name != "":
+		return fmt.Sprintf("%s named %q", k.typ, k.name)
+	default:
+		return k.typ.String()
+	}
+}
+
+// typeKey returns a unique key for any generic type (including interfaces)
+func typeKey[T any]() key {
+	return keyOf(typeOf[T]())
+}
+
+// typeOf returns the reflected type of T (including interfaces)
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// keyOf returns the key of a reflected type. Keys compare the type itself,
+// so building one does not allocate.
+func keyOf(t reflect.Type) key {
+	return key{typ: t}
+}
+
+// namedKey returns the key for the registration of T under name
+func namedKey[T any](name string) key {
+	k := typeKey[T]()
+	k.name = name
+	return k
 }
 
 // Register registers a singleton instance directly
-func Register[T any](instance T) {
-	key := typeKey[T]()
-	instances.Store(key, instance)
+func Register[T any](instance T, opts ...RegisterOption) {
+	RegisterIn(Default(), instance, opts...)
+}
+
+// RegisterIn registers a singleton instance directly in c
+func RegisterIn[T any](c *Container, instance T, opts ...RegisterOption) {
+	c.register(typeKey[T](), instance, opts)
+}
+
+// register stores instance under key, panicking if strict mode rejects it
+func (c *Container) register(k key, instance any, opts []RegisterOption) {
+	if err := c.addInstance(k, instance, opts); err != nil {
+		panic(err)
+	}
+}
+
+// addInstance stores instance under key
+func (c *Container) addInstance(k key, instance any, opts []RegisterOption) error {
+	f := c.newFactory(k, Singleton, func(Resolver) (any, func(), error) {
+		return instance, nil, nil
+	})
+	f.external = true
+	return c.store(f, opts)
+}
+
+// RegisterFactory registers a factory function for lazy initialization.
+// The factory runs once per cached instance, even when the first resolutions
+// are concurrent, and every caller receives that instance. It runs again after
+// the instance is invalidated, reset, expires under WithTTL, or is dropped
+// under WithWeak.
+func RegisterFactory[T any](f func() T, opts ...RegisterOption) {
+	RegisterFactoryIn(Default(), f, opts...)
+}
+
+// RegisterFactoryIn registers a factory function for lazy initialization in c
+func RegisterFactoryIn[T any](c *Container, f func() T, opts ...RegisterOption) {
+	c.registerFactory(typeKey[T](), Singleton, opts, func(Resolver) (any, func(), error) {
+		return f(), nil, nil
+	})
+}
+
+// RegisterFactoryE registers a factory that may fail for lazy initialization.
+// A failed construction is returned from Resolve and not cached.
+func RegisterFactoryE[T any](f func() (T, error), opts ...RegisterOption) {
+	RegisterFactoryEIn(Default(), f, opts...)
+}
+
+// RegisterFactoryEIn registers a factory that may fail for lazy initialization in c
+func RegisterFactoryEIn[T any](c *Container, f func() (T, error), opts ...RegisterOption) {
+	c.registerFactory(typeKey[T](), Singleton, opts, func(Resolver) (any, func(), error) {
+		v, err := f()
+		return v, nil, err
+	})
+}
+
+// RegisterFactoryCleanup registers a factory that also returns a cleanup
+// function, which runs on Reset and Shutdown
+func RegisterFactoryCleanup[T any](f func() (T, func()), opts ...RegisterOption) {
+	RegisterFactoryCleanupIn(Default(), f, opts...)
+}
+
+// RegisterFactoryCleanupIn registers a factory in c that also returns a cleanup function
+func RegisterFactoryCleanupIn[T any](c *Container, f func() (T, func()), opts ...RegisterOption) {
+	c.registerFactory(typeKey[T](), Singleton, opts, func(Resolver) (any, func(), error) {
+		v, cleanup := f()
+		return v, cleanup, nil
+	})
+}
+
+// registerFactory stores a factory with the given lifetime and declared
+// dependencies under key, panicking if strict mode rejects it
+func (c *Container) registerFactory(k key, lifetime Lifetime, opts []RegisterOption, create func(r Resolver) (any, func(), error), deps ...key) {
+	if err := c.addFactory(k, lifetime, opts, create, deps...); err != nil {
+		panic(err)
+	}
+}
+
+// addFactory stores a factory with the given lifetime and declared dependencies under key
+func (c *Container) addFactory(k key, lifetime Lifetime, opts []RegisterOption, create func(r Resolver) (any, func(), error), deps ...key) error {
+	f := c.newFactory(k, lifetime, create)
+	f.deps = deps
+	return c.store(f, opts)
+}
+
+// store applies opts to f and records it as the registration for its key,
+// replacing any previous registration and its cached instance. Registrations
+// whose condition failed are dropped. Defaults never replace a registration
+// and are always replaced; otherwise, in strict mode, an existing
+// registration is an error.
+func (c *Container) store(f *factory, opts []RegisterOption) error {
+	for _, opt := range opts {
+		opt(f)
+	}
+	if f.disabled {
+		return nil
+	}
+	for _, alias := range f.aliases {
+		if !f.key.typ.AssignableTo(alias.typ) {
+			return fmt.Errorf("%w: cannot register %v as %v: %v does not implement %v", ErrTypeMismatch, f.key, alias.typ, f.key.typ, alias.typ)
+		}
+	}
+	if len(f.groups) > 0 && f.key.name == "" && f.key.id == 0 {
+		// grouped bindings are collected, not replaced
+		f.key = c.multiKey(f.key)
+	}
+	if m := c.installing.Load(); m != nil {
+		f.module = m.name
+	}
+	if f.site.n == 0 {
+		f.site = captureSite()
+	}
+	for _, k := range append([]key{f.key}, f.aliases...) {
+		existing, ok := c.factories.load(k)
+		switch {
+		case !ok:
+		case f.fallback:
+			return nil
+		case existing.fallback:
+		case c.strict:
+			return fmt.Errorf("%w: %v is already registered", ErrConflict, k)
+		}
+	}
+
+	f.seq = c.seq.Add(1)
+	previous, replaced := c.factories.swap(f.key, f)
+	c.instances.delete(f.key)
+	if replaced && !previous.fallback {
+		f.overrides = previous.site.copy()
+	}
+	c.logRegistration(f, f.overrides != nil)
+	c.emitRegistration(f, replaced)
+
+	for _, alias := range f.aliases {
+		to := f.key
+		if err := c.store(&factory{key: alias, lifetime: f.lifetime, deps: []key{to}, target: &to, module: f.module, site: f.site}, nil); err != nil {
+			return err
+		}
+	}
+	c.notifyRegistered()
+	return nil
 }
 
-// RegisterFactory registers a factory function for lazy initialization
-func RegisterFactory[T any](f func() T) {
-	key := typeKey[T]()
-	factories.Store(key, f)
+// WarnSlowFactories logs every factory run that takes longer than threshold.
+// A zero threshold disables the warning.
+func WarnSlowFactories(threshold time.Duration) {
+	Default().WarnSlowFactories(threshold)
+}
+
+// WarnSlowFactories logs every factory run in c that takes longer than threshold.
+// A zero threshold disables the warning.
+func (c *Container) WarnSlowFactories(threshold time.Duration) {
+	c.slowFactoryThreshold.Store(int64(threshold))
+}
+
+// factory describes how an instance for a key is built
+type factory struct {
+	key      key
+	lifetime Lifetime
+	create   func(r Resolver) (any, func(), error)
+	// deps lists the keys the factory resolves before running, if declared
+	deps []key
+	// seq orders the registration relative to the others in the container
+	seq uint64
+	// groups lists the groups the registration belongs to
+	groups []string
+	// priority orders the registration among the bindings of its type
+	priority int
+	// target is the key an alias registration delegates to
+	target *key
+	// aliases lists the extra keys the registration can be resolved as
+	aliases []key
+	// external reports whether the instance was registered directly rather than built
+	external bool
+	// fallback reports whether the registration is a default that any other one replaces
+	fallback bool
+	// module is the name of the module that made the registration, if any
+	module string
+	// disabled reports whether a condition turned the registration off
+	disabled bool
+	// onStart and onStop are the lifecycle hooks of the registration
+	onStart []func(context.Context, any) error
+	onStop  []func(context.Context, any) error
+	// once caches the instance of a singleton factory
+	once *OnceValue[any]
+	// weak caches the instance of a weak singleton instead of once
+	weak *weakSlot
+	// ttl caches the instance of an expiring singleton instead of once
+	ttl *ttlSlot
+	// refreshAhead is how long before expiry a WithTTL instance is rebuilt in the background
+	refreshAhead time.Duration
+	// retry makes failed runs of the factory retry, if set
+	retry *retryPolicy
+	// site is where the registration was made
+	site callSite
+	// overrides is where the registration it replaced was made, if any
+	overrides *callSite
+	// used reports whether the registration was resolved
+	used atomic.Bool
+}
+
+// markUsed records that f was resolved
+func (f *factory) markUsed() {
+	if !f.used.Load() {
+		f.used.Store(true)
+	}
+}
+
+// newFactory wraps create for key so singletons run at most once and slow runs are reported
+func (c *Container) newFactory(k key, lifetime Lifetime, create func(r Resolver) (any, func(), error)) *factory {
+	f := &factory{key: k, lifetime: lifetime, create: create, site: captureSite()}
+	if lifetime == Singleton {
+		f.once = Once(func() (any, error) {
+			return c.buildSingleton(context.Background(), f)
+		})
+	}
+	return f
+}
+
+// buildSingleton runs the singleton factory f with ctx and records the
+// instance for the lifecycle
+func (c *Container) buildSingleton(ctx context.Context, f *factory) (any, error) {
+	v, cleanup, err := c.run(ctx, f, c)
+	if err == nil {
+		c.recordBuilt(f, v, cleanup)
+	}
+	return v, err
+}
+
+// run calls the factory in r with ctx and logs it if it exceeds the slow
+// factory threshold. It returns the decorated instance and the cleanup
+// function of the factory, if any.
+func (c *Container) run(ctx context.Context, f *factory, r Resolver) (any, func(), error) {
+	g := goid()
+	c.tracker.push(g, f.key)
+	defer c.tracker.pop(g)
+
+	start := time.Now()
+	var cleanup func()
+	v, err := c.instrument(ctx, ResolveInfo{Type: f.key.typ.String(), Name: f.key.name}, Instrumentation.StartFactory, func(ctx context.Context) (any, error) {
+		v, done, err := c.create(ctx, f, bind(r, ctx))
+		cleanup = done
+		return v, err
+	})
+
+	threshold := time.Duration(c.slowFactoryThreshold.Load())
+	elapsed := time.Since(start)
+	if threshold > 0 && elapsed > threshold {
+		if l := c.logger.Load(); l != nil {
+			l.Warn("slow factory", "key", f.key.String(), "elapsed", elapsed, "threshold", threshold)
+		} else {
+			log.Printf("di: slow factory for %s took %s (threshold %s)", f.key, elapsed, threshold)
+		}
+	}
+	if m := c.metrics.Load(); m != nil {
+		(*m).ObserveFactory(ResolveInfo{Type: f.key.typ.String(), Name: f.key.name, Duration: elapsed, Err: err})
+	}
+	if s := c.stats.Load(); s != nil {
+		s.built(f.key, elapsed)
+	}
+	if f.lifetime == Transient {
+		c.observeTransient(f)
+	}
+	c.emit(EventFactoryRun, f.key, elapsed, err)
+
+	if err != nil {
+		return nil, nil, c.buildError(g, f.key, err)
+	}
+	return c.applyDecorators(f.key, v), cleanup, nil
+}
+
+// create calls the factory in r, retrying failures as configured by WithRetry
+// until ctx is done
+func (c *Container) create(ctx context.Context, f *factory, r Resolver) (any, func(), error) {
+	v, cleanup, err := c.createOnce(f, r)
+	if f.retry == nil {
+		return v, cleanup, err
+	}
+
+	var panicked *PanicError
+	delay := f.retry.backoff
+	for attempt := 1; attempt < f.retry.attempts && err != nil && !errors.As(err, &panicked); attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil, nil, errors.Join(err, ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+		v, cleanup, err = c.createOnce(f, r)
+	}
+	return v, cleanup, err
+}
+
+// createOnce calls the factory in r, turning a panic into a PanicError
+func (c *Container) createOnce(f *factory, r Resolver) (v any, cleanup func(), err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			c.logPanic(f.key, p)
+			err = &PanicError{Key: f.key.String(), Value: p, Stack: debug.Stack(), format: c.errorFormatter.Load()}
+		}
+	}()
+	return f.create(r)
 }
 
 // Resolve retrieves an instance from the container
 func Resolve[T any]() (T, error) {
-	key := typeKey[T]()
+	return ResolveIn[T](Default())
+}
 
-	if v, ok := instances.Load(key); ok {
+// ResolveIn retrieves an instance from a container or scope
+func ResolveIn[T any](r Resolver) (T, error) {
+	return resolveAs[T](r, typeKey[T]())
+}
+
+// resolveAs retrieves the instance stored under k from r as a T
+func resolveAs[T any](r Resolver, k key) (T, error) {
+	var zero T
+	if builtinType[T]() && k.name == "" && !r.owner().has(k) {
+		v, err := any(zero).(builtin).build(r)
+		if err != nil {
+			return zero, err
+		}
 		return v.(T), nil
 	}
 
-	if f, ok := factories.Load(key); ok {
-		instance := f.(func() T)()
-		instances.Store(key, instance)
-		return instance, nil
+	v, err := resolveKey(r, k)
+	if err != nil {
+		return zero, err
+	}
+	if v == nil {
+		return zero, nil
+	}
+	instance, ok := v.(T)
+	if !ok {
+		return zero, fmt.Errorf("%w: instance for %v has type %T, which is not assignable to %v", ErrTypeMismatch, k, v, typeKey[T]())
+	}
+	return instance, nil
+}
+
+// resolve retrieves the instance stored under key, building singletons on
+// demand with ctx
+func (c *Container) resolve(ctx context.Context, k key) (any, error) {
+	if v, ok := c.instances.load(k); ok {
+		if m := c.metrics.Load(); m != nil {
+			(*m).ObserveCacheHit(ResolveInfo{Type: k.typ.String(), Name: k.name})
+		}
+		c.emit(EventResolveHit, k, 0, nil)
+		return v, nil
 	}
 
-	var zero T
-	return zero, fmt.Errorf("no instance found for type %v", key)
+	v, err := c.resolveUncached(ctx, k)
+	c.emit(EventResolveMiss, k, 0, err)
+	return v, err
+}
+
+// resolveUncached resolves k when no instance is cached in c
+func (c *Container) resolveUncached(ctx context.Context, k key) (any, error) {
+	if f, ok := c.factories.load(k); ok {
+		f.markUsed()
+		if f.target != nil {
+			return c.resolve(ctx, *f.target)
+		}
+		if f.lifetime == Scoped {
+			return nil, fmt.Errorf("%w: type %v is scoped and must be resolved from a scope", ErrScopeRequired, k)
+		}
+		if path := c.tracker.cycle(k); path != nil {
+			return nil, c.cycleError(path)
+		}
+		if f.lifetime == Transient {
+			v, _, err := c.run(ctx, f, c)
+			return v, err
+		}
+		if f.weak != nil || f.ttl != nil {
+			return c.singleton(ctx, f)
+		}
+		return c.cacheSingleton(ctx, f)
+	}
+
+	if c.parent != nil && c.parent.has(k) {
+		return c.parent.resolve(ctx, k)
+	}
+	return nil, c.newResolveError(k)
+}
+
+// singleton returns the instance of the singleton f, building it with ctx if
+// needed, without caching it for resolutions
+func (c *Container) singleton(ctx context.Context, f *factory) (any, error) {
+	switch {
+	case f.weak != nil:
+		return c.tracker.wait(f.key, func() (any, error) { return c.resolveWeak(ctx, f) }, c.cycleError)
+	case f.ttl != nil:
+		return c.tracker.wait(f.key, func() (any, error) { return c.resolveTTL(ctx, f) }, c.cycleError)
+	default:
+		return c.tracker.wait(f.key, func() (any, error) {
+			p, err := f.once.getWith(func() (any, error) { return c.buildSingleton(ctx, f) })
+			if err != nil {
+				return nil, err
+			}
+			return *p, nil
+		}, c.cycleError)
+	}
+}
+
+// cacheSingleton builds the singleton f if needed and caches its instance for
+// resolutions, unless the registration was replaced or the instance
+// invalidated meanwhile. Replacements swap the registration and invalidations
+// reset the OnceValue before removing the cached instance, so checking both
+// under the lock of the instance shard keeps a stale instance from being
+// cached after them.
+func (c *Container) cacheSingleton(ctx context.Context, f *factory) (any, error) {
+	var built *any
+	v, err := c.tracker.wait(f.key, func() (any, error) {
+		p, err := f.once.getWith(func() (any, error) { return c.buildSingleton(ctx, f) })
+		if err != nil {
+			return nil, err
+		}
+		built = p
+		return *p, nil
+	}, c.cycleError)
+	if err != nil {
+		return nil, err
+	}
+	c.instances.storeIf(f.key, v, func() bool {
+		current, ok := c.factories.load(f.key)
+		return ok && current == f && f.once.holds(built)
+	})
+	return v, nil
+}
+
+// warm builds f with ctx ahead of its first resolution. Singletons are built
+// without being counted as used or cached for resolutions, so the first
+// resolution by the application is what UnusedRegistrations sees.
+func (c *Container) warm(ctx context.Context, f *factory) error {
+	if f.lifetime != Singleton || f.target != nil {
+		_, err := resolveKey(bind(c, ctx), f.key)
+		return err
+	}
+	_, err := c.singleton(ctx, f)
+	return err
 }
 
 // MustResolve retrieves an instance or panics if not found
 func MustResolve[T any]() T {
-	v, err := Resolve[T]()
+	return MustResolveIn[T](Default())
+}
+
+// MustResolveIn retrieves an instance from a container or scope or panics if not found
+func MustResolveIn[T any](r Resolver) T {
+	v, err := ResolveIn[T](r)
 	if err != nil {
 		panic(err)
 	}
@@ -58,19 +624,59 @@ func MustResolve[T any]() T {
 
 // Unregister removes an instance or factory from the container
 func Unregister[T any]() {
-	key := typeKey[T]()
-	instances.Delete(key)
-	factories.Delete(key)
+	UnregisterIn[T](Default())
+}
+
+// UnregisterIn removes an instance or factory from c
+func UnregisterIn[T any](c *Container) {
+	c.unregister(typeKey[T]())
+}
+
+// unregister removes the instance and factory stored under key
+func (c *Container) unregister(k key) {
+	// the registration goes first so a resolution in flight does not cache
+	// its instance again
+	if f, ok := c.factories.load(k); ok && f.ttl != nil {
+		f.ttl.expire()
+	}
+	c.factories.delete(k)
+	c.instances.delete(k)
+	c.emit(EventUnregister, k, 0, nil)
 }
 
 // Reset clears all instances and factories (useful for testing)
 func Reset() {
-	instances.Range(func(k, v any) bool {
-		instances.Delete(k)
-		return true
-	})
-	factories.Range(func(k, v any) bool {
-		factories.Delete(k)
-		return true
-	})
+	Default().Reset()
+}
+
+// Reset clears all instances, factories, decorators and installed modules in c, running the
+// cleanup functions returned by factories in reverse construction order
+func (c *Container) Reset() {
+	c.logReset()
+	c.stopRefreshes()
+	c.factories.clear()
+	c.instances.clear()
+
+	c.modulesMu.Lock()
+	c.modules = nil
+	c.modulesMu.Unlock()
+
+	c.laterMu.Lock()
+	c.later = nil
+	c.laterMu.Unlock()
+
+	c.decoratorsMu.Lock()
+	c.decorators = make(map[key][]func(any) any)
+	c.decoratorsMu.Unlock()
+
+	for _, b := range c.takeBuilt() {
+		if b.cleanup != nil {
+			b.cleanup()
+		}
+	}
+
+	c.tracker.mu.Lock()
+	c.tracker.edges = nil
+	c.tracker.mu.Unlock()
+	c.emit(EventReset, key{}, 0, nil)
 }
@@ -0,0 +1,181 @@
+// Package diconfig wires a di container from a YAML or JSON manifest.
+//
+// A manifest picks, for each slot, which of the implementations known to a
+// Registry is bound, and sets scalar configuration values:
+//
+//	bindings:
+//	  mailer: smtp
+//	  cache: redis
+//	values:
+//	  http.port: 8080
+//	  smtp.host: mail.internal
+//
+// Switching an implementation per environment then only needs another
+// manifest, not another build.
+package diconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ryanbekhen/di"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is the decoded content of a wiring manifest
+type Manifest struct {
+	// Bindings maps each slot to the name of the implementation to bind
+	Bindings map[string]string `json:"bindings" yaml:"bindings"`
+	// Values holds scalar configuration values by name
+	Values map[string]any `json:"values" yaml:"values"`
+}
+
+// Load reads a manifest from a .json, .yaml or .yml file
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		return ParseJSON(data)
+	case ".yaml", ".yml":
+		return ParseYAML(data)
+	default:
+		return nil, fmt.Errorf("unsupported manifest format %q", ext)
+	}
+}
+
+// ParseJSON decodes a JSON manifest. Integral numbers become int values.
+func ParseJSON(data []byte) (*Manifest, error) {
+	var m Manifest
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("decode manifest: %w", err)
+	}
+
+	for name, v := range m.Values {
+		n, ok := v.(json.Number)
+		if !ok {
+			continue
+		}
+		if i, err := n.Int64(); err == nil {
+			m.Values[name] = int(i)
+		} else if f, err := n.Float64(); err == nil {
+			m.Values[name] = f
+		}
+	}
+	return &m, nil
+}
+
+// ParseYAML decodes a YAML manifest
+func ParseYAML(data []byte) (*Manifest, error) {
+	var m Manifest
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("decode manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// Registry holds the implementations a manifest can choose from
+type Registry struct {
+	slots map[string]map[string]func(c *di.Container) error
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{slots: make(map[string]map[string]func(c *di.Container) error)}
+}
+
+// Add makes the constructor ctor available as implementation impl of slot.
+// When a manifest selects it, ctor is registered with di.RegisterConstructor
+// and bound to I. ctor must have the form accepted by RegisterConstructor
+// and return a type that implements I.
+func Add[I any](r *Registry, slot, impl string, ctor any) {
+	if r.slots[slot] == nil {
+		r.slots[slot] = make(map[string]func(c *di.Container) error)
+	}
+	r.slots[slot][impl] = func(c *di.Container) error {
+		return c.RegisterConstructor(ctor, di.As[I]())
+	}
+}
+
+// Apply registers in c the implementations the manifest binds and its values.
+// Each value is registered as a named instance of its type (string, int,
+// float64 or bool), and all values together as a Values instance.
+func (r *Registry) Apply(c *di.Container, m *Manifest) error {
+	slots := make([]string, 0, len(m.Bindings))
+	for slot := range m.Bindings {
+		slots = append(slots, slot)
+	}
+	sort.Strings(slots)
+
+	for _, slot := range slots {
+		impl := m.Bindings[slot]
+		impls, ok := r.slots[slot]
+		if !ok {
+			return fmt.Errorf("unknown slot %q", slot)
+		}
+		apply, ok := impls[impl]
+		if !ok {
+			return fmt.Errorf("unknown implementation %q for slot %q", impl, slot)
+		}
+		if err := apply(c); err != nil {
+			return fmt.Errorf("bind %s to %s: %w", slot, impl, err)
+		}
+	}
+
+	for name, v := range m.Values {
+		switch v := v.(type) {
+		case string:
+			di.RegisterNamedIn(c, name, v)
+		case int:
+			di.RegisterNamedIn(c, name, v)
+		case float64:
+			di.RegisterNamedIn(c, name, v)
+		case bool:
+			di.RegisterNamedIn(c, name, v)
+		default:
+			return fmt.Errorf("value %q: unsupported type %T", name, v)
+		}
+	}
+	di.RegisterIn(c, Values(m.Values))
+	return nil
+}
+
+// Values holds the scalar values of an applied manifest by name
+type Values map[string]any
+
+// String returns the string value name, or fallback if it is not a string
+func (v Values) String(name, fallback string) string {
+	if s, ok := v[name].(string); ok {
+		return s
+	}
+	return fallback
+}
+
+// Int returns the int value name, or fallback if it is not an int
+func (v Values) Int(name string, fallback int) int {
+	if i, ok := v[name].(int); ok {
+		return i
+	}
+	return fallback
+}
+
+// Bool returns the bool value name, or fallback if it is not a bool
+func (v Values) Bool(name string, fallback bool) bool {
+	if b, ok := v[name].(bool); ok {
+		return b
+	}
+	return fallback
+}
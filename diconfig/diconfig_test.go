@@ -0,0 +1,112 @@
+package diconfig_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/diconfig"
+)
+
+type mailer interface{ Send(to string) string }
+
+type smtpMailer struct {
+	host string
+	port int
+	tls  bool
+}
+
+func (m *smtpMailer) Send(to string) string { return "smtp:" + to }
+
+type logMailer struct{}
+
+func (logMailer) Send(to string) string { return "log:" + to }
+
+func newRegistry() *diconfig.Registry {
+	r := diconfig.NewRegistry()
+	diconfig.Add[mailer](r, "mailer", "smtp", func(v diconfig.Values) *smtpMailer {
+		return &smtpMailer{host: v.String("smtp.host", ""), port: v.Int("smtp.port", 25), tls: v.Bool("smtp.tls", false)}
+	})
+	diconfig.Add[mailer](r, "mailer", "log", func() logMailer { return logMailer{} })
+	return r
+}
+
+func apply(t *testing.T, path string) (*di.Container, error) {
+	t.Helper()
+	m, err := diconfig.Load(filepath.Join("testdata", path))
+	if err != nil {
+		return nil, err
+	}
+	c := di.New()
+	return c, newRegistry().Apply(c, m)
+}
+
+func named[T any](t *testing.T, c *di.Container, name string) T {
+	t.Helper()
+	v, err := di.ResolveNamedIn[T](c, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestApplyYAMLManifest(t *testing.T) {
+	c, err := apply(t, "prod.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := di.MustResolveIn[mailer](c)
+	smtp, ok := m.(*smtpMailer)
+	if !ok {
+		t.Fatalf("bound %T, want *smtpMailer", m)
+	}
+	if smtp.host != "mail.internal" || smtp.port != 2525 || !smtp.tls {
+		t.Fatalf("mailer configured as %+v", smtp)
+	}
+	if host := named[string](t, c, "smtp.host"); host != "mail.internal" {
+		t.Fatalf("named value smtp.host is %q", host)
+	}
+}
+
+func TestApplyJSONManifest(t *testing.T) {
+	c, err := apply(t, "dev.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := di.MustResolveIn[mailer](c).Send("ops"); got != "log:ops" {
+		t.Fatalf("mailer sent %q, want the log implementation", got)
+	}
+	if port := named[int](t, c, "smtp.port"); port != 1025 {
+		t.Fatalf("integral JSON number decoded as %v", port)
+	}
+	if ratio := named[float64](t, c, "ratio"); ratio != 0.5 {
+		t.Fatalf("fractional JSON number decoded as %v", ratio)
+	}
+}
+
+func TestApplyRejectsUnknownChoices(t *testing.T) {
+	for file, want := range map[string]string{
+		"unknown_slot.yaml": `unknown slot "queue"`,
+		"unknown_impl.yaml": `unknown implementation "carrier-pigeon" for slot "mailer"`,
+	} {
+		if _, err := apply(t, file); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got %v, want an error containing %q", file, err, want)
+		}
+	}
+}
+
+func TestLoadRejectsInvalidManifests(t *testing.T) {
+	for _, file := range []string{"unknown_field.json", "wiring.toml"} {
+		if _, err := diconfig.Load(filepath.Join("testdata", file)); err == nil {
+			t.Errorf("%s: loaded without error", file)
+		}
+	}
+	if _, err := diconfig.Load(filepath.Join("testdata", "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing file: got %v, want os.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,7 @@
+module github.com/ryanbekhen/di/diconfig
+
+go 1.25
+
+require github.com/ryanbekhen/di v1.0.0
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,109 @@
+// Package didig bridges di containers and go.uber.org/dig containers, so an
+// application can move its providers to di a few at a time.
+//
+// Values still provided by dig are imported into a di container:
+//
+//	didig.Import[*sql.DB](c, digContainer)
+//
+// and values already moved to di are exported back for the dig providers
+// that depend on them:
+//
+//	didig.Export[*UserRepository](digContainer, c)
+package didig
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/ryanbekhen/di"
+	"go.uber.org/dig"
+)
+
+// errorType is the reflected error interface
+var errorType = reflect.TypeFor[error]()
+
+// Import registers T in c as a singleton resolved from d on first use
+func Import[T any](c *di.Container, d *dig.Container, opts ...di.RegisterOption) {
+	di.RegisterFactoryEIn(c, func() (T, error) {
+		var v T
+		err := d.Invoke(func(dep T) {
+			v = dep
+		})
+		return v, err
+	}, opts...)
+}
+
+// ImportType registers t in c as a singleton resolved from d on first use.
+// It is the non-generic form of Import.
+func ImportType(c *di.Container, d *dig.Container, t reflect.Type, opts ...di.RegisterOption) error {
+	return c.RegisterDynamicFactory(t, di.Singleton, func(di.Resolver) (any, error) {
+		var v any
+		fn := reflect.MakeFunc(reflect.FuncOf([]reflect.Type{t}, nil, false), func(args []reflect.Value) []reflect.Value {
+			v = args[0].Interface()
+			return nil
+		})
+		if err := d.Invoke(fn.Interface()); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}, opts...)
+}
+
+// Provide adds constructor to d and imports each of its results into c, so
+// the constructor is written once and resolvable from both containers.
+// Results that d records under a name or group, and fields of dig.Out
+// structs, are only available from d.
+func Provide(c *di.Container, d *dig.Container, constructor any, opts ...dig.ProvideOption) error {
+	var info dig.ProvideInfo
+	if err := d.Provide(constructor, append(opts, dig.FillProvideInfo(&info))...); err != nil {
+		return err
+	}
+
+	outputs := make(map[string]bool, len(info.Outputs))
+	for _, out := range info.Outputs {
+		outputs[out.String()] = true
+	}
+
+	t := reflect.TypeOf(constructor)
+	for i := range t.NumOut() {
+		out := t.Out(i)
+		if out == errorType || !outputs[out.String()] {
+			continue
+		}
+		if err := ImportType(c, d, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Export provides T to d, resolving it from r when a dig constructor or
+// invocation first needs it
+func Export[T any](d *dig.Container, r di.Resolver, opts ...dig.ProvideOption) error {
+	return d.Provide(func() (T, error) {
+		return di.ResolveIn[T](r)
+	}, opts...)
+}
+
+// ExportType provides t to d, resolving it from r when first needed. It is
+// the non-generic form of Export.
+func ExportType(d *dig.Container, r interface {
+	ResolveDynamic(t reflect.Type) (any, error)
+}, t reflect.Type, opts ...dig.ProvideOption) error {
+	if t == nil {
+		return errors.New("export needs a non-nil type")
+	}
+	fn := reflect.MakeFunc(reflect.FuncOf(nil, []reflect.Type{t, errorType}, false), func([]reflect.Value) []reflect.Value {
+		v, err := r.ResolveDynamic(t)
+		out := reflect.New(t).Elem()
+		if err == nil && v != nil {
+			out.Set(reflect.ValueOf(v))
+		}
+		errOut := reflect.Zero(errorType)
+		if err != nil {
+			errOut = reflect.ValueOf(&err).Elem()
+		}
+		return []reflect.Value{out, errOut}
+	})
+	return d.Provide(fn.Interface(), opts...)
+}
@@ -0,0 +1,116 @@
+package didig_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/didig"
+	"go.uber.org/dig"
+)
+
+type legacyDB struct{ dsn string }
+
+type userRepo struct{ db *legacyDB }
+
+type userHandler struct{ repo *userRepo }
+
+func TestImportResolvesFromDig(t *testing.T) {
+	d := dig.New()
+	builds := 0
+	if err := d.Provide(func() *legacyDB {
+		builds++
+		return &legacyDB{dsn: "postgres://legacy"}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := di.New()
+	didig.Import[*legacyDB](c, d)
+	if builds != 0 {
+		t.Fatal("Import built the dig value before it was resolved")
+	}
+	di.RegisterFactoryIn(c, func() *userRepo { return &userRepo{db: di.MustResolveIn[*legacyDB](c)} })
+
+	repo := di.MustResolveIn[*userRepo](c)
+	if repo.db.dsn != "postgres://legacy" {
+		t.Fatalf("imported %+v", repo.db)
+	}
+	if di.MustResolveIn[*legacyDB](c) != repo.db || builds != 1 {
+		t.Fatal("the imported value is not shared")
+	}
+}
+
+func TestExportServesDigProviders(t *testing.T) {
+	c := di.New()
+	db := &legacyDB{dsn: "postgres://di"}
+	di.RegisterIn(c, db)
+	di.RegisterFactoryIn(c, func() *userRepo { return &userRepo{db: db} })
+
+	d := dig.New()
+	if err := didig.Export[*userRepo](d, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := didig.ExportType(d, c, reflect.TypeOf(db)); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Provide(func(repo *userRepo) *userHandler { return &userHandler{repo: repo} }); err != nil {
+		t.Fatal(err)
+	}
+
+	err := d.Invoke(func(h *userHandler, got *legacyDB) {
+		if h.repo != di.MustResolveIn[*userRepo](c) || got != db {
+			t.Error("dig received other instances than the di container holds")
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProvideRegistersInBothContainers(t *testing.T) {
+	c := di.New()
+	d := dig.New()
+	err := didig.Provide(c, d, func() (*legacyDB, *userRepo, error) {
+		db := &legacyDB{dsn: "shared"}
+		return db, &userRepo{db: db}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := di.MustResolveIn[*userRepo](c)
+	if di.MustResolveIn[*legacyDB](c) != repo.db {
+		t.Fatal("results of one constructor call are not shared")
+	}
+	if err := d.Invoke(func(fromDig *userRepo) {
+		if fromDig != repo {
+			t.Error("dig and di hold different instances")
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestErrorsCrossContainers(t *testing.T) {
+	d := dig.New()
+	c := di.New()
+	didig.Import[*legacyDB](c, d)
+	if _, err := di.ResolveIn[*legacyDB](c); err == nil {
+		t.Fatal("imported a type dig does not provide")
+	}
+
+	boom := errors.New("boom")
+	di.RegisterFactoryEIn(c, func() (*userRepo, error) { return nil, boom })
+	if err := didig.Export[*userRepo](d, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Invoke(func(*userRepo) {}); !errors.Is(err, boom) {
+		t.Fatalf("dig returned %v, want the di factory error", err)
+	}
+
+	if err := didig.ExportType(d, c, nil); err == nil {
+		t.Fatal("exported a nil type")
+	}
+}
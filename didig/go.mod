@@ -0,0 +1,7 @@
+module github.com/ryanbekhen/di/didig
+
+go 1.25
+
+require github.com/ryanbekhen/di v1.0.0
+
+require go.uber.org/dig v1.19.0
@@ -0,0 +1,48 @@
+// Package diecho opens a di scope for every Echo request.
+//
+// Handlers behind Middleware resolve request-scoped instances with Resolve:
+//
+//	svc, err := diecho.Resolve[*UserService](ctx)
+package diecho
+
+import (
+	"log"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ryanbekhen/di"
+)
+
+// Middleware returns middleware that opens a scope on c for each request,
+// stores it in the request context and closes it when the handler returns.
+// A nil c uses the default container.
+func Middleware(c *di.Container) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			container := c
+			if container == nil {
+				container = di.Default()
+			}
+
+			scope := container.NewScope()
+			defer func() {
+				if err := scope.Close(); err != nil {
+					log.Printf("diecho: closing request scope: %v", err)
+				}
+			}()
+
+			req := ctx.Request()
+			ctx.SetRequest(req.WithContext(di.WithScope(req.Context(), scope)))
+			return next(ctx)
+		}
+	}
+}
+
+// Resolve retrieves an instance from the scope of the request
+func Resolve[T any](ctx echo.Context) (T, error) {
+	return di.ResolveCtx[T](ctx.Request().Context())
+}
+
+// MustResolve is like Resolve but panics if the instance cannot be resolved
+func MustResolve[T any](ctx echo.Context) T {
+	return di.MustResolveCtx[T](ctx.Request().Context())
+}
@@ -0,0 +1,78 @@
+package diecho_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/diecho"
+)
+
+type echoSession struct{ closed bool }
+
+func (s *echoSession) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestMiddlewareScopesEachRequest(t *testing.T) {
+	c := di.New()
+	var sessions []*echoSession
+	di.RegisterScopedIn(c, func(*di.Scope) *echoSession {
+		s := &echoSession{}
+		sessions = append(sessions, s)
+		return s
+	})
+
+	e := echo.New()
+	e.Use(diecho.Middleware(c))
+	e.GET("/", func(ctx echo.Context) error {
+		a, err := diecho.Resolve[*echoSession](ctx)
+		if err != nil {
+			return err
+		}
+		if diecho.MustResolve[*echoSession](ctx) != a {
+			return ctx.String(http.StatusInternalServerError, "two instances in one request")
+		}
+		return ctx.NoContent(http.StatusOK)
+	})
+
+	for range 2 {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("built %d request instances for 2 requests", len(sessions))
+	}
+	for _, s := range sessions {
+		if !s.closed {
+			t.Fatal("a request instance was not closed")
+		}
+	}
+}
+
+func TestMiddlewareWithoutContainerUsesDefault(t *testing.T) {
+	c := di.New()
+	restore := di.SetDefault(c)
+	defer restore()
+	di.RegisterScopedIn(c, func(*di.Scope) *echoSession { return &echoSession{} })
+
+	e := echo.New()
+	e.Use(diecho.Middleware(nil))
+	e.GET("/", func(ctx echo.Context) error {
+		if _, err := diecho.Resolve[*echoSession](ctx); err != nil {
+			return err
+		}
+		return ctx.NoContent(http.StatusOK)
+	})
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,165 @@
+// Package dievents delivers typed events to handlers built by a container.
+//
+// Modules subscribe handler constructors instead of resolving each other to
+// call notification methods:
+//
+//	dievents.Subscribe[OrderCreated](c, NewSendReceipt)
+//
+//	func NewSendReceipt(mailer Mailer) *SendReceipt { ... }
+//	func (h *SendReceipt) Handle(ctx context.Context, e OrderCreated) error { ... }
+//
+// Publishing an event opens a scope, builds every handler subscribed to the
+// event type from it and runs them in order:
+//
+//	err := dievents.PublishIn(ctx, c, OrderCreated{ID: id})
+package dievents
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/ryanbekhen/di"
+)
+
+// group is the group subscriptions are registered in
+const group = "dievents"
+
+// errorType is the reflected error interface
+var errorType = reflect.TypeFor[error]()
+
+// Handler handles events of type E
+type Handler[E any] interface {
+	Handle(ctx context.Context, event E) error
+}
+
+// HandlerFunc adapts a function to a Handler
+type HandlerFunc[E any] func(ctx context.Context, event E) error
+
+// Handle calls f
+func (f HandlerFunc[E]) Handle(ctx context.Context, event E) error {
+	return f(ctx, event)
+}
+
+// subscription builds a handler for events of type E from the event scope
+type subscription[E any] struct {
+	build func(s *di.Scope) (Handler[E], error)
+}
+
+// Subscribe subscribes the handlers built by ctor to events of type E in the
+// default container
+func Subscribe[E any](ctor any, opts ...di.RegisterOption) error {
+	return SubscribeIn[E](di.Default(), ctor, opts...)
+}
+
+// SubscribeIn subscribes the handlers built by ctor to events of type E in c.
+// ctor has the form func(D1, D2, ...) H or func(D1, D2, ...) (H, error),
+// where H implements Handler[E]; its parameters are resolved from the scope
+// of each published event. Handlers run by di.WithPriority and then in
+// subscription order.
+func SubscribeIn[E any](c *di.Container, ctor any, opts ...di.RegisterOption) error {
+	v := reflect.ValueOf(ctor)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return fmt.Errorf("handler constructor must be a non-nil func, got %T", ctor)
+	}
+	t := v.Type()
+	handlerType := reflect.TypeFor[Handler[E]]()
+	switch {
+	case t.NumOut() == 1:
+	case t.NumOut() == 2 && t.Out(1) == errorType:
+	default:
+		return fmt.Errorf("handler constructor %v must return H or (H, error)", t)
+	}
+	if !t.Out(0).Implements(handlerType) {
+		return fmt.Errorf("handler constructor %v: %v does not implement %v", t, t.Out(0), handlerType)
+	}
+
+	params := make([]reflect.Type, t.NumIn())
+	for i := range params {
+		params[i] = t.In(i)
+	}
+	invokeType := reflect.FuncOf(params, []reflect.Type{errorType}, t.IsVariadic())
+
+	build := func(s *di.Scope) (Handler[E], error) {
+		var h Handler[E]
+		fn := reflect.MakeFunc(invokeType, func(args []reflect.Value) []reflect.Value {
+			var out []reflect.Value
+			if t.IsVariadic() {
+				out = v.CallSlice(args)
+			} else {
+				out = v.Call(args)
+			}
+			err := reflect.Zero(errorType)
+			if len(out) == 2 && !out[1].IsNil() {
+				err = out[1]
+			} else if !isNil(out[0]) {
+				h = out[0].Interface().(Handler[E])
+			}
+			return []reflect.Value{err}
+		})
+		if err := s.Invoke(fn.Interface()); err != nil {
+			return nil, err
+		}
+		if h == nil {
+			return nil, fmt.Errorf("handler constructor %v returned nil", t)
+		}
+		return h, nil
+	}
+	di.RegisterIn(c, subscription[E]{build: build}, append(opts, di.WithGroup(group))...)
+	return nil
+}
+
+// SubscribeFunc subscribes fn to events of type E in c
+func SubscribeFunc[E any](c *di.Container, fn func(ctx context.Context, event E) error, opts ...di.RegisterOption) {
+	handler := HandlerFunc[E](fn)
+	di.RegisterIn(c, subscription[E]{build: func(*di.Scope) (Handler[E], error) {
+		return handler, nil
+	}}, append(opts, di.WithGroup(group))...)
+}
+
+// Publish delivers event to the handlers subscribed in the default container
+func Publish[E any](ctx context.Context, event E) error {
+	return PublishIn(ctx, di.Default(), event)
+}
+
+// PublishIn delivers event to the handlers subscribed to E in c. It opens a
+// scope for the event, stored in the context the handlers receive, builds
+// the handlers from it and runs them one after another. A failing handler
+// does not stop the others; the errors are joined. The scope is closed
+// before PublishIn returns.
+func PublishIn[E any](ctx context.Context, c *di.Container, event E) (err error) {
+	subs, err := di.ResolveGroupIn[subscription[E]](c, group)
+	if err != nil || len(subs) == 0 {
+		return err
+	}
+
+	scope := c.NewScope()
+	defer func() {
+		if closeErr := scope.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("closing event scope: %w", closeErr))
+		}
+	}()
+	ctx = di.WithScope(ctx, scope)
+
+	var errs []error
+	for _, sub := range subs {
+		h, err := sub.build(scope)
+		if err == nil {
+			err = h.Handle(ctx, event)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("handle %T: %w", event, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isNil reports whether v holds a nil pointer, interface, map, slice, func or channel
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package dievents_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/dievents"
+)
+
+type orderCreated struct{ id int }
+
+type orderCanceled struct{ id int }
+
+type eventTx struct{ closed bool }
+
+func (tx *eventTx) Close() error {
+	tx.closed = true
+	return nil
+}
+
+type outbox struct{ sent []string }
+
+type sendReceipt struct {
+	outbox *outbox
+	tx     *eventTx
+}
+
+func newSendReceipt(o *outbox, tx *eventTx) *sendReceipt {
+	return &sendReceipt{outbox: o, tx: tx}
+}
+
+func (h *sendReceipt) Handle(ctx context.Context, e orderCreated) error {
+	if di.MustResolveCtx[*eventTx](ctx) != h.tx {
+		return errors.New("the handler and its context use different scopes")
+	}
+	h.outbox.sent = append(h.outbox.sent, "receipt")
+	return nil
+}
+
+type audit struct{ outbox *outbox }
+
+func (a audit) Handle(_ context.Context, e orderCreated) error {
+	a.outbox.sent = append(a.outbox.sent, "audit")
+	return nil
+}
+
+func newContainer() (*di.Container, *outbox, *[]*eventTx) {
+	c := di.New()
+	o := &outbox{}
+	di.RegisterIn(c, o)
+	var txs []*eventTx
+	di.RegisterScopedIn(c, func(*di.Scope) *eventTx {
+		tx := &eventTx{}
+		txs = append(txs, tx)
+		return tx
+	})
+	return c, o, &txs
+}
+
+func TestPublishRunsSubscribedHandlersInEventScope(t *testing.T) {
+	c, o, txs := newContainer()
+	if err := dievents.SubscribeIn[orderCreated](c, newSendReceipt); err != nil {
+		t.Fatal(err)
+	}
+	if err := dievents.SubscribeIn[orderCreated](c, func(o *outbox) (audit, error) { return audit{outbox: o}, nil }, di.WithPriority(1)); err != nil {
+		t.Fatal(err)
+	}
+	dievents.SubscribeFunc(c, func(context.Context, orderCanceled) error {
+		return errors.New("canceled handler ran for a created order")
+	})
+
+	for id := range 2 {
+		if err := dievents.PublishIn(context.Background(), c, orderCreated{id: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := []string{"audit", "receipt", "audit", "receipt"}; !slices.Equal(o.sent, want) {
+		t.Fatalf("handlers ran as %v, want %v", o.sent, want)
+	}
+	if len(*txs) != 2 {
+		t.Fatalf("built %d event scoped instances for 2 events", len(*txs))
+	}
+	for _, tx := range *txs {
+		if !tx.closed {
+			t.Fatal("an event scope was not closed")
+		}
+	}
+}
+
+func TestPublishJoinsHandlerErrors(t *testing.T) {
+	c, o, _ := newContainer()
+	boom := errors.New("boom")
+	dievents.SubscribeFunc(c, func(context.Context, orderCreated) error { return boom })
+	if err := dievents.SubscribeIn[orderCreated](c, func(o *outbox) audit { return audit{outbox: o} }); err != nil {
+		t.Fatal(err)
+	}
+	if err := dievents.SubscribeIn[orderCreated](c, func(*sendReceipt) audit { return audit{} }); err != nil {
+		t.Fatal(err)
+	}
+
+	err := dievents.PublishIn(context.Background(), c, orderCreated{})
+	if !errors.Is(err, boom) || !errors.Is(err, di.ErrNotRegistered) {
+		t.Fatalf("got %v, want the handler error and the missing dependency", err)
+	}
+	if !slices.Equal(o.sent, []string{"audit"}) {
+		t.Fatalf("a failing handler stopped the others: %v", o.sent)
+	}
+}
+
+func TestPublishWithoutSubscribers(t *testing.T) {
+	c, _, txs := newContainer()
+	if err := dievents.PublishIn(context.Background(), c, orderCanceled{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*txs) != 0 {
+		t.Fatal("an event without subscribers built scoped instances")
+	}
+}
+
+func TestSubscribeRejectsInvalidConstructors(t *testing.T) {
+	c := di.New()
+	for _, ctor := range []any{
+		nil,
+		"not a func",
+		func() {},
+		func() (*sendReceipt, *outbox) { return nil, nil },
+		func() *outbox { return nil },
+	} {
+		if err := dievents.SubscribeIn[orderCreated](c, ctor); err == nil {
+			t.Errorf("subscribed %T", ctor)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Package diexpvar publishes di container metrics with expvar.
+//
+//	c := di.New(di.WithMetrics(diexpvar.New("di")))
+//
+// Importing it registers the /debug/vars handler of expvar on
+// http.DefaultServeMux, which is why it is not part of di itself.
+package diexpvar
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ryanbekhen/di"
+)
+
+// Metrics publishes container metrics as expvar maps keyed by registration:
+// resolves, errors, cache_hits, factory_runs and factory_nanoseconds (the
+// total time spent in factories)
+type Metrics struct {
+	resolves, errors, cacheHits, factoryRuns, factoryNanos *expvar.Map
+}
+
+// mu serializes the publication of expvar variables
+var mu sync.Mutex
+
+// New creates metrics published under the expvar variable name. Creating
+// metrics for a name that is already published reuses its maps.
+func New(name string) *Metrics {
+	mu.Lock()
+	defer mu.Unlock()
+
+	root, ok := expvar.Get(name).(*expvar.Map)
+	if !ok {
+		root = expvar.NewMap(name)
+	}
+	child := func(key string) *expvar.Map {
+		if m, ok := root.Get(key).(*expvar.Map); ok {
+			return m
+		}
+		m := new(expvar.Map)
+		root.Set(key, m)
+		return m
+	}
+	return &Metrics{
+		resolves:     child("resolves"),
+		errors:       child("errors"),
+		cacheHits:    child("cache_hits"),
+		factoryRuns:  child("factory_runs"),
+		factoryNanos: child("factory_nanoseconds"),
+	}
+}
+
+// ObserveResolve counts a resolution and its error, if any
+func (m *Metrics) ObserveResolve(info di.ResolveInfo) {
+	id := infoKey(info)
+	m.resolves.Add(id, 1)
+	if info.Err != nil {
+		m.errors.Add(id, 1)
+	}
+}
+
+// ObserveFactory counts a factory run and the time it took
+func (m *Metrics) ObserveFactory(info di.ResolveInfo) {
+	id := infoKey(info)
+	m.factoryRuns.Add(id, 1)
+	m.factoryNanos.Add(id, int64(info.Duration/time.Nanosecond))
+}
+
+// ObserveCacheHit counts a resolution served from the cache
+func (m *Metrics) ObserveCacheHit(info di.ResolveInfo) {
+	m.cacheHits.Add(infoKey(info), 1)
+}
+
+// infoKey names the registration described by info
+func infoKey(info di.ResolveInfo) string {
+	if info.Name != "" {
+		return fmt.Sprintf("%s named %q", info.Type, info.Name)
+	}
+	return info.Type
+}
@@ -0,0 +1,27 @@
+package diexpvar_test
+
+import (
+	"expvar"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/diexpvar"
+)
+
+type store struct{}
+
+func TestMetricsCountResolutions(t *testing.T) {
+	c := di.New(di.WithMetrics(diexpvar.New("di_test")))
+	di.RegisterFactoryIn(c, func() *store { return &store{} })
+
+	di.MustResolveIn[*store](c)
+	di.MustResolveIn[*store](c)
+
+	root := expvar.Get("di_test").(*expvar.Map)
+	for name, want := range map[string]string{"resolves": "2", "cache_hits": "1", "factory_runs": "1"} {
+		got := root.Get(name).(*expvar.Map).Get("*diexpvar_test.store")
+		if got == nil || got.String() != want {
+			t.Errorf("%s = %v, want %s", name, got, want)
+		}
+	}
+}
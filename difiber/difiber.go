@@ -0,0 +1,45 @@
+// Package difiber opens a di scope for every Fiber request.
+//
+// Handlers behind Middleware resolve request-scoped instances with Resolve:
+//
+//	svc, err := difiber.Resolve[*UserService](ctx)
+package difiber
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanbekhen/di"
+)
+
+// Middleware returns a handler that opens a scope on c for each request,
+// stores it in the user context and closes it when the request is done.
+// A nil c uses the default container.
+func Middleware(c *di.Container) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		container := c
+		if container == nil {
+			container = di.Default()
+		}
+
+		scope := container.NewScope()
+		defer func() {
+			if err := scope.Close(); err != nil {
+				log.Printf("difiber: closing request scope: %v", err)
+			}
+		}()
+
+		ctx.SetUserContext(di.WithScope(ctx.UserContext(), scope))
+		return ctx.Next()
+	}
+}
+
+// Resolve retrieves an instance from the scope of the request
+func Resolve[T any](ctx *fiber.Ctx) (T, error) {
+	return di.ResolveCtx[T](ctx.UserContext())
+}
+
+// MustResolve is like Resolve but panics if the instance cannot be resolved
+func MustResolve[T any](ctx *fiber.Ctx) T {
+	return di.MustResolveCtx[T](ctx.UserContext())
+}
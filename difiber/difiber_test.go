@@ -0,0 +1,62 @@
+package difiber_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/difiber"
+)
+
+type fiberSession struct{ closed bool }
+
+func (s *fiberSession) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestMiddlewareScopesEachRequest(t *testing.T) {
+	c := di.New()
+	var sessions []*fiberSession
+	di.RegisterScopedIn(c, func(*di.Scope) *fiberSession {
+		s := &fiberSession{}
+		sessions = append(sessions, s)
+		return s
+	})
+
+	app := fiber.New()
+	app.Use(difiber.Middleware(c))
+	app.Get("/", func(ctx *fiber.Ctx) error {
+		a, err := difiber.Resolve[*fiberSession](ctx)
+		if err != nil {
+			return err
+		}
+		if difiber.MustResolve[*fiberSession](ctx) != a {
+			return ctx.Status(http.StatusInternalServerError).SendString("two instances in one request")
+		}
+		return ctx.SendStatus(http.StatusOK)
+	})
+
+	for range 2 {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status %d: %s", resp.StatusCode, body)
+		}
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("built %d request instances for 2 requests", len(sessions))
+	}
+	for _, s := range sessions {
+		if !s.closed {
+			t.Fatal("a request instance was not closed")
+		}
+	}
+}
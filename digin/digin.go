@@ -0,0 +1,45 @@
+// Package digin opens a di scope for every Gin request.
+//
+// Handlers behind Middleware resolve request-scoped instances with Resolve:
+//
+//	svc, err := digin.Resolve[*UserService](ctx)
+package digin
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryanbekhen/di"
+)
+
+// Middleware returns a handler that opens a scope on c for each request,
+// stores it in the request context and closes it when the request is done.
+// A nil c uses the default container.
+func Middleware(c *di.Container) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		container := c
+		if container == nil {
+			container = di.Default()
+		}
+
+		scope := container.NewScope()
+		defer func() {
+			if err := scope.Close(); err != nil {
+				log.Printf("digin: closing request scope: %v", err)
+			}
+		}()
+
+		ctx.Request = ctx.Request.WithContext(di.WithScope(ctx.Request.Context(), scope))
+		ctx.Next()
+	}
+}
+
+// Resolve retrieves an instance from the scope of the request
+func Resolve[T any](ctx *gin.Context) (T, error) {
+	return di.ResolveCtx[T](ctx.Request.Context())
+}
+
+// MustResolve is like Resolve but panics if the instance cannot be resolved
+func MustResolve[T any](ctx *gin.Context) T {
+	return di.MustResolveCtx[T](ctx.Request.Context())
+}
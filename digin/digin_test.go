@@ -0,0 +1,78 @@
+package digin_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/digin"
+)
+
+type ginSession struct{ closed bool }
+
+func (s *ginSession) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestMiddlewareScopesEachRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c := di.New()
+	var sessions []*ginSession
+	di.RegisterScopedIn(c, func(*di.Scope) *ginSession {
+		s := &ginSession{}
+		sessions = append(sessions, s)
+		return s
+	})
+
+	router := gin.New()
+	router.Use(digin.Middleware(c))
+	router.GET("/", func(ctx *gin.Context) {
+		a, err := digin.Resolve[*ginSession](ctx)
+		if err != nil {
+			ctx.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		if digin.MustResolve[*ginSession](ctx) != a {
+			ctx.String(http.StatusInternalServerError, "two instances in one request")
+			return
+		}
+		ctx.Status(http.StatusOK)
+	})
+
+	for range 2 {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("built %d request instances for 2 requests", len(sessions))
+	}
+	for _, s := range sessions {
+		if !s.closed {
+			t.Fatal("a request instance was not closed")
+		}
+	}
+}
+
+func TestResolveOutsideMiddlewareFails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c := di.New()
+	di.RegisterScopedIn(c, func(*di.Scope) *ginSession { return &ginSession{} })
+
+	router := gin.New()
+	router.GET("/", func(ctx *gin.Context) {
+		if _, err := digin.Resolve[*ginSession](ctx); err == nil {
+			ctx.Status(http.StatusInternalServerError)
+		}
+	})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatal("resolved a scoped instance without a request scope")
+	}
+}
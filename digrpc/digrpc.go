@@ -0,0 +1,93 @@
+// Package digrpc opens a di scope for every gRPC call.
+//
+// Services behind the interceptors resolve call-scoped instances from the
+// call context:
+//
+//	svc, err := di.ResolveCtx[*UserService](ctx)
+package digrpc
+
+import (
+	"context"
+	"log"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/direcover"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor returns an interceptor that runs each unary call in
+// its own scope on c. A nil c uses the default container.
+func UnaryServerInterceptor(c *di.Container) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		scope := newScope(c)
+		defer closeScope(scope)
+		return handler(di.WithScope(ctx, scope), req)
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that runs each stream in its
+// own scope on c. A nil c uses the default container.
+func StreamServerInterceptor(c *di.Container) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		scope := newScope(c)
+		defer closeScope(scope)
+		return handler(srv, &scopedStream{ServerStream: ss, ctx: di.WithScope(ss.Context(), scope)})
+	}
+}
+
+// RecoveryUnaryServerInterceptor returns an interceptor that recovers panics
+// in unary handlers, reports them with direcover for c and fails the call
+// with codes.Internal. A nil c uses the default container.
+func RecoveryUnaryServerInterceptor(c *di.Container) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				direcover.Recovered(ctx, c, info.FullMethod, v)
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStreamServerInterceptor returns an interceptor that recovers panics
+// in stream handlers like RecoveryUnaryServerInterceptor
+func RecoveryStreamServerInterceptor(c *di.Container) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				direcover.Recovered(ss.Context(), c, info.FullMethod, v)
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// scopedStream is a server stream whose context carries the call scope
+type scopedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context carrying the call scope
+func (s *scopedStream) Context() context.Context {
+	return s.ctx
+}
+
+// newScope opens a scope on c or the default container
+func newScope(c *di.Container) *di.Scope {
+	if c == nil {
+		c = di.Default()
+	}
+	return c.NewScope()
+}
+
+// closeScope closes the scope of a finished call
+func closeScope(scope *di.Scope) {
+	if err := scope.Close(); err != nil {
+		log.Printf("digrpc: closing call scope: %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package digrpc_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/digrpc"
+	"github.com/ryanbekhen/di/direcover"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type callTracker struct {
+	mu     sync.Mutex
+	closed bool
+}
+
+func (t *callTracker) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	return nil
+}
+
+func (t *callTracker) isClosed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.closed
+}
+
+// healthService answers only when the call context carries a scope
+type healthService struct {
+	healthpb.UnimplementedHealthServer
+	mu    sync.Mutex
+	calls []*callTracker
+}
+
+func (s *healthService) record(ctx context.Context) error {
+	a, err := di.ResolveCtx[*callTracker](ctx)
+	if err != nil {
+		return err
+	}
+	if b := di.MustResolveCtx[*callTracker](ctx); a != b {
+		panic("two instances in one call")
+	}
+	s.mu.Lock()
+	s.calls = append(s.calls, a)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *healthService) Check(ctx context.Context, _ *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if err := s.record(ctx); err != nil {
+		return nil, err
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *healthService) Watch(_ *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	if err := s.record(stream.Context()); err != nil {
+		return err
+	}
+	return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING})
+}
+
+func startServer(t *testing.T, c *di.Container) (healthpb.HealthClient, *healthService) {
+	t.Helper()
+	svc := &healthService{}
+	return dial(t, svc,
+		grpc.UnaryInterceptor(digrpc.UnaryServerInterceptor(c)),
+		grpc.StreamInterceptor(digrpc.StreamServerInterceptor(c)),
+	), svc
+}
+
+// dial serves svc over an in-memory listener and returns a client for it
+func dial(t *testing.T, svc healthpb.HealthServer, opts ...grpc.ServerOption) healthpb.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(opts...)
+	healthpb.RegisterHealthServer(server, svc)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func TestUnaryCallsGetTheirOwnScope(t *testing.T) {
+	c := di.New()
+	di.RegisterScopedIn(c, func(*di.Scope) *callTracker { return &callTracker{} })
+	client, svc := startServer(t, c)
+
+	for range 2 {
+		if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(svc.calls) != 2 || svc.calls[0] == svc.calls[1] {
+		t.Fatalf("expected one instance per call, got %v", svc.calls)
+	}
+	for _, call := range svc.calls {
+		if !call.isClosed() {
+			t.Fatal("a call instance was not closed after the call")
+		}
+	}
+}
+
+func TestStreamCallsGetTheirOwnScope(t *testing.T) {
+	c := di.New()
+	di.RegisterScopedIn(c, func(*di.Scope) *callTracker { return &callTracker{} })
+	client, svc := startServer(t, c)
+
+	stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	stream.Recv() // wait for the handler to return
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if len(svc.calls) != 1 {
+		t.Fatalf("recorded %d stream calls", len(svc.calls))
+	}
+	if !svc.calls[0].isClosed() {
+		t.Fatal("the stream instance was not closed after the stream")
+	}
+}
+
+func TestInterceptorsWithoutContainerUseDefault(t *testing.T) {
+	c := di.New()
+	restore := di.SetDefault(c)
+	defer restore()
+	di.RegisterScopedIn(c, func(*di.Scope) *callTracker { return &callTracker{} })
+	client, _ := startServer(t, nil)
+
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// panickingService panics in every call
+type panickingService struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (panickingService) Check(context.Context, *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	panic("unary bug")
+}
+
+func (panickingService) Watch(*healthpb.HealthCheckRequest, healthpb.Health_WatchServer) error {
+	panic("stream bug")
+}
+
+func TestRecoveryInterceptorsReportPanics(t *testing.T) {
+	c := di.New()
+	reported := make(chan *direcover.Panic, 2)
+	direcover.HandleIn(c, func(_ context.Context, p *direcover.Panic) { reported <- p })
+	client := dial(t, panickingService{},
+		grpc.UnaryInterceptor(digrpc.RecoveryUnaryServerInterceptor(c)),
+		grpc.StreamInterceptor(digrpc.RecoveryStreamServerInterceptor(c)),
+	)
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("unary call failed with %v, want codes.Internal", err)
+	}
+	if p := <-reported; p.Operation != healthpb.Health_Check_FullMethodName || p.Value != "unary bug" {
+		t.Fatalf("reported %+v", p)
+	}
+
+	stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Internal {
+		t.Fatalf("stream failed with %v, want codes.Internal", err)
+	}
+	if p := <-reported; p.Operation != healthpb.Health_Watch_FullMethodName {
+		t.Fatalf("reported %+v", p)
+	}
+}
@@ -0,0 +1,118 @@
+// Package dihttp opens a di scope for every HTTP request.
+//
+// Handlers wrapped by Middleware resolve request-scoped instances from the
+// request context:
+//
+//	svc, err := di.ResolveCtx[*UserService](r.Context())
+//
+// or declare them as parameters with Inject:
+//
+//	mux.Handle("/users", dihttp.Inject(c, func(svc *UserService, w http.ResponseWriter, r *http.Request) {
+//		...
+//	}))
+package dihttp
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+
+	"github.com/ryanbekhen/di"
+)
+
+// Middleware returns middleware that opens a scope on c for each request,
+// stores it in the request context and closes it when the handler returns.
+// A nil c uses the default container.
+func Middleware(c *di.Container) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return Handler(c, next)
+	}
+}
+
+// Handler wraps next so each request runs in its own scope on c
+func Handler(c *di.Container, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		container := c
+		if container == nil {
+			container = di.Default()
+		}
+
+		scope := container.NewScope()
+		defer func() {
+			if err := scope.Close(); err != nil {
+				log.Printf("dihttp: closing request scope: %v", err)
+			}
+		}()
+
+		next.ServeHTTP(w, r.WithContext(di.WithScope(r.Context(), scope)))
+	})
+}
+
+// Scope returns the scope of the request, if it passed through Middleware
+func Scope(r *http.Request) (*di.Scope, bool) {
+	return di.ScopeOf(r.Context())
+}
+
+var (
+	// responseWriterType is the reflected http.ResponseWriter interface
+	responseWriterType = reflect.TypeFor[http.ResponseWriter]()
+	// requestType is the reflected *http.Request type
+	requestType = reflect.TypeFor[*http.Request]()
+)
+
+// Inject returns a handler that calls fn, a func(D1, D2, ..., http.ResponseWriter,
+// *http.Request), with its dependencies resolved from the scope of each
+// request. Requests that did not pass through Middleware get a scope on c,
+// or the default container if c is nil. A dependency that cannot be resolved
+// fails the request with 500 Internal Server Error. Inject panics if fn does
+// not have that form.
+//
+// Inject is separate from Handler, which wraps an existing http.Handler.
+func Inject(c *di.Container, fn any) http.Handler {
+	v := reflect.ValueOf(fn)
+	if !isHandlerFunc(v) {
+		panic(fmt.Sprintf("dihttp: Inject needs a func(deps..., http.ResponseWriter, *http.Request), got %T", fn))
+	}
+	t := v.Type()
+	deps := make([]reflect.Type, t.NumIn()-2)
+	for i := range deps {
+		deps[i] = t.In(i)
+	}
+
+	inject := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope, _ := Scope(r)
+		args := make([]reflect.Value, 0, len(deps)+2)
+		for _, dep := range deps {
+			d, err := scope.ResolveDynamic(dep)
+			if err != nil {
+				log.Printf("dihttp: resolving handler dependency: %v", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			if d == nil {
+				args = append(args, reflect.Zero(dep))
+			} else {
+				args = append(args, reflect.ValueOf(d))
+			}
+		}
+		v.Call(append(args, reflect.ValueOf(w), reflect.ValueOf(r)))
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := Scope(r); ok {
+			inject(w, r)
+			return
+		}
+		Handler(c, inject).ServeHTTP(w, r)
+	})
+}
+
+// isHandlerFunc reports whether v is a non-nil func(deps..., http.ResponseWriter, *http.Request)
+func isHandlerFunc(v reflect.Value) bool {
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return false
+	}
+	t := v.Type()
+	n := t.NumIn()
+	return !t.IsVariadic() && t.NumOut() == 0 && n >= 2 && t.In(n-2) == responseWriterType && t.In(n-1) == requestType
+}
@@ -0,0 +1,165 @@
+package dihttp_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/dihttp"
+)
+
+type requestLog struct {
+	id     int
+	closed bool
+}
+
+func (l *requestLog) Close() error {
+	l.closed = true
+	return nil
+}
+
+func TestMiddlewareOpensScopePerRequest(t *testing.T) {
+	c := di.New()
+	var built []*requestLog
+	di.RegisterScopedIn(c, func(*di.Scope) *requestLog {
+		l := &requestLog{id: len(built) + 1}
+		built = append(built, l)
+		return l
+	})
+
+	handler := dihttp.Middleware(c)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a, err := di.ResolveCtx[*requestLog](r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if b := di.MustResolveCtx[*requestLog](r.Context()); a != b {
+			http.Error(w, "two instances in one request", http.StatusInternalServerError)
+			return
+		}
+		if a.closed {
+			http.Error(w, "closed during the request", http.StatusInternalServerError)
+		}
+	}))
+
+	for range 2 {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+	}
+	if len(built) != 2 {
+		t.Fatalf("built %d request instances for 2 requests", len(built))
+	}
+	for _, l := range built {
+		if !l.closed {
+			t.Fatalf("the instance of request %d was not closed", l.id)
+		}
+	}
+}
+
+func TestScopeOfRequest(t *testing.T) {
+	var scope *di.Scope
+	var ok bool
+	handler := dihttp.Handler(di.New(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope, ok = dihttp.Scope(r)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !ok || scope == nil {
+		t.Fatal("the request carries no scope")
+	}
+
+	if _, ok := dihttp.Scope(httptest.NewRequest(http.MethodGet, "/", nil)); ok {
+		t.Fatal("a request outside the middleware carries a scope")
+	}
+}
+
+func TestMiddlewareWithoutContainerUsesDefault(t *testing.T) {
+	c := di.New()
+	restore := di.SetDefault(c)
+	defer restore()
+	di.RegisterScopedIn(c, func(*di.Scope) *requestLog { return &requestLog{} })
+
+	server := httptest.NewServer(dihttp.Middleware(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := di.ResolveCtx[*requestLog](r.Context()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+}
+
+type userService struct{ name string }
+
+func TestInjectResolvesHandlerParameters(t *testing.T) {
+	c := di.New()
+	di.RegisterIn(c, &userService{name: "users"})
+	var built []*requestLog
+	di.RegisterScopedIn(c, func(*di.Scope) *requestLog {
+		l := &requestLog{id: len(built) + 1}
+		built = append(built, l)
+		return l
+	})
+
+	handler := dihttp.Inject(c, func(svc *userService, l *requestLog, w http.ResponseWriter, r *http.Request) {
+		if di.MustResolveCtx[*requestLog](r.Context()) != l {
+			http.Error(w, "the parameter and the request scope differ", http.StatusInternalServerError)
+			return
+		}
+		io.WriteString(w, svc.name)
+	})
+
+	// with and without Middleware in front
+	for _, h := range []http.Handler{handler, dihttp.Middleware(c)(handler)} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != "users" {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+	}
+	if len(built) != 2 || !built[0].closed || !built[1].closed {
+		t.Fatal("each request should build and close its own scoped parameter")
+	}
+}
+
+func TestInjectFailsRequestOnMissingDependency(t *testing.T) {
+	called := false
+	handler := dihttp.Inject(di.New(), func(*userService, http.ResponseWriter, *http.Request) {
+		called = true
+	})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusInternalServerError || called {
+		t.Fatalf("status %d, handler called %v", rec.Code, called)
+	}
+}
+
+func TestInjectRejectsInvalidHandlers(t *testing.T) {
+	for _, fn := range []any{
+		func(http.ResponseWriter) {},
+		func(*http.Request, http.ResponseWriter) {},
+		func(w http.ResponseWriter, r *http.Request) error { return nil },
+		"not a func",
+		nil,
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Inject accepted %T", fn)
+				}
+			}()
+			dihttp.Inject(nil, fn)
+		}()
+	}
+}
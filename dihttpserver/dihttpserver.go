@@ -0,0 +1,145 @@
+// Package dihttpserver runs an HTTP server as part of a container's lifecycle.
+//
+// Installing Module registers an *http.Server that serves the routes and
+// middleware registered in the container. The server starts listening when
+// the container starts and shuts down gracefully when it stops:
+//
+//	di.RegisterIn(c, dihttpserver.Config{Addr: ":8080"})
+//	dihttpserver.Handle(c, "GET /users", usersHandler)
+//	dihttpserver.Use(c, logRequests)
+//
+//	if err := c.Install(dihttpserver.Module); err != nil {
+//		log.Fatal(err)
+//	}
+//	log.Fatal(di.NewApp(c).Run(context.Background()))
+//
+// Every request runs in its own scope, as with dihttp.Middleware.
+package dihttpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/dihttp"
+)
+
+// RoutesGroup is the group Handle registers routes in
+const RoutesGroup = "dihttpserver.routes"
+
+// MiddlewareGroup is the group Use registers middleware in
+const MiddlewareGroup = "dihttpserver.middleware"
+
+// Config configures the server. The fields can be read from the environment
+// with di.RegisterConfigIn[dihttpserver.Config]; without a registered Config
+// the server listens on :8080.
+type Config struct {
+	// Addr is the TCP address to listen on
+	Addr string `env:"HTTP_ADDR,default=:8080"`
+	// ReadHeaderTimeout limits the time to read request headers
+	ReadHeaderTimeout time.Duration `env:"HTTP_READ_HEADER_TIMEOUT,default=10s"`
+	// ReadTimeout limits the time to read a whole request, zero for no limit
+	ReadTimeout time.Duration `env:"HTTP_READ_TIMEOUT"`
+	// WriteTimeout limits the time to write a response, zero for no limit
+	WriteTimeout time.Duration `env:"HTTP_WRITE_TIMEOUT"`
+	// IdleTimeout limits the time keep-alive connections wait for the next request
+	IdleTimeout time.Duration `env:"HTTP_IDLE_TIMEOUT"`
+}
+
+// Route is a handler served for the requests matching an http.ServeMux pattern
+type Route struct {
+	Pattern string
+	Handler http.Handler
+}
+
+// Middleware wraps the handler of every route
+type Middleware func(next http.Handler) http.Handler
+
+// Module registers the *http.Server. It provides the "http" capability.
+var Module = di.NewModule("dihttpserver", install, di.Provides("http"))
+
+// Handle registers handler for the requests matching pattern
+func Handle(c *di.Container, pattern string, handler http.Handler) {
+	di.RegisterIn(c, Route{Pattern: pattern, Handler: handler}, di.WithGroup(RoutesGroup))
+}
+
+// HandleFunc registers handler for the requests matching pattern
+func HandleFunc(c *di.Container, pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	Handle(c, pattern, http.HandlerFunc(handler))
+}
+
+// Use registers middleware around every route. Middleware with a higher
+// di.WithPriority runs first; middleware of equal priority runs in
+// registration order.
+func Use(c *di.Container, middleware Middleware, opts ...di.RegisterOption) {
+	di.RegisterIn(c, middleware, append(opts, di.WithGroup(MiddlewareGroup))...)
+}
+
+// install registers the default config and the server with its lifecycle hooks
+func install(c *di.Container) {
+	di.RegisterDefaultIn(c, Config{Addr: ":8080", ReadHeaderTimeout: 10 * time.Second})
+	di.RegisterFactoryEIn(c, func() (*http.Server, error) {
+		return newServer(c)
+	}, di.OnStart(start), di.OnStop(stop))
+}
+
+// newServer builds a server for the config, routes and middleware registered in c
+func newServer(c *di.Container) (*http.Server, error) {
+	cfg, err := di.ResolveIn[Config](c)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := di.ResolveGroupIn[Route](c, RoutesGroup)
+	if err != nil {
+		return nil, err
+	}
+	middleware, err := di.ResolveGroupIn[Middleware](c, MiddlewareGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	for _, route := range routes {
+		mux.Handle(route.Pattern, route.Handler)
+	}
+	var handler http.Handler = mux
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           dihttp.Handler(c, handler),
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}, nil
+}
+
+// start listens on the address of srv, so a port in use fails the start, and
+// serves in the background. srv.Addr is set to the address listened on.
+func start(_ context.Context, srv *http.Server) error {
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", srv.Addr, err)
+	}
+	srv.Addr = ln.Addr().String()
+
+	go func() {
+		if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("dihttpserver: serving on %s: %v", srv.Addr, err)
+		}
+	}()
+	return nil
+}
+
+// stop waits for the active requests to finish until ctx is done
+func stop(ctx context.Context, srv *http.Server) error {
+	return srv.Shutdown(ctx)
+}
@@ -0,0 +1,147 @@
+package dihttpserver_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/dihttpserver"
+)
+
+type requestID struct{ value string }
+
+func newContainer(t *testing.T) *di.Container {
+	t.Helper()
+	c := di.New()
+	di.RegisterIn(c, dihttpserver.Config{Addr: "127.0.0.1:0"})
+	return c
+}
+
+func TestServerServesRoutesThroughMiddleware(t *testing.T) {
+	c := newContainer(t)
+	di.RegisterScopedIn(c, func(*di.Scope) *requestID { return &requestID{value: "req-1"} })
+	dihttpserver.HandleFunc(c, "GET /hello", func(w http.ResponseWriter, r *http.Request) {
+		id := di.MustResolveCtx[*requestID](r.Context())
+		io.WriteString(w, "hello "+id.value)
+	})
+	dihttpserver.Use(c, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Order", "inner")
+			next.ServeHTTP(w, r)
+		})
+	})
+	dihttpserver.Use(c, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Order", "outer")
+			next.ServeHTTP(w, r)
+		})
+	}, di.WithPriority(1))
+	if err := c.Install(dihttpserver.Module); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	srv := di.MustResolveIn[*http.Server](c)
+
+	resp, err := http.Get("http://" + srv.Addr + "/hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "hello req-1" {
+		t.Fatalf("body %q", body)
+	}
+	if order := strings.Join(resp.Header.Values("X-Order"), ","); order != "outer,inner" {
+		t.Fatalf("middleware ran as %s, want outer,inner", order)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := c.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := http.Get("http://" + srv.Addr + "/hello"); err == nil {
+		t.Fatal("the server still serves after shutdown")
+	}
+}
+
+func TestStartFailsWhenAddressIsInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	c := di.New()
+	di.RegisterIn(c, dihttpserver.Config{Addr: ln.Addr().String()})
+	if err := c.Install(dihttpserver.Module); err != nil {
+		t.Fatal(err)
+	}
+	err = c.Start(context.Background())
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		t.Fatalf("Start returned %v, want the listen error", err)
+	}
+}
+
+func TestShutdownWaitsForActiveRequests(t *testing.T) {
+	c := newContainer(t)
+	started := make(chan struct{})
+	dihttpserver.HandleFunc(c, "/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		io.WriteString(w, "done")
+	})
+	if err := c.Install(dihttpserver.Module); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	srv := di.MustResolveIn[*http.Server](c)
+
+	result := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + srv.Addr + "/slow")
+		if err != nil {
+			result <- err.Error()
+			return
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		result <- string(body)
+	}()
+	<-started
+
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-result; got != "done" {
+		t.Fatalf("the active request ended with %q", got)
+	}
+}
+
+func TestConfigFromEnvironment(t *testing.T) {
+	t.Setenv("HTTP_ADDR", "127.0.0.1:0")
+	c := di.New()
+	if err := di.RegisterConfigIn[dihttpserver.Config](c); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Install(dihttpserver.Module); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := di.MustResolveIn[*http.Server](c)
+	if srv.Addr != "127.0.0.1:0" || srv.ReadHeaderTimeout != 10*time.Second {
+		t.Fatalf("server configured with addr %q and read header timeout %v", srv.Addr, srv.ReadHeaderTimeout)
+	}
+}
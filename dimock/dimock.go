@@ -0,0 +1,71 @@
+// Package dimock connects gomock controllers and mockgen mocks to a di
+// container.
+//
+// Mock builds a mock with its generated constructor and makes it the
+// registration of its interface for the duration of the test:
+//
+//	func TestSignup(t *testing.T) {
+//		mailer := dimock.Mock[Mailer](t, NewMockMailer)
+//		mailer.EXPECT().Send("ada@example.com").Return(nil)
+//		...
+//	}
+//
+// The mocks of a test share one controller, whose expectations are checked
+// when the test ends.
+package dimock
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+	"go.uber.org/mock/gomock"
+)
+
+// RegisterController creates a controller for the test and registers it in
+// the default container until the test ends
+func RegisterController(t testing.TB) *gomock.Controller {
+	t.Helper()
+	return RegisterControllerIn(di.Default(), t)
+}
+
+// RegisterControllerIn creates a controller for the test and registers it in
+// c until the test ends. gomock checks the expectations of its mocks when the
+// test ends.
+func RegisterControllerIn(c *di.Container, t testing.TB) *gomock.Controller {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	di.OverrideIn(c, t, ctrl)
+	return ctrl
+}
+
+// Mock builds a mock with newMock, a constructor generated by mockgen, and
+// overrides I with it in the default container until the test ends
+func Mock[I, M any](t testing.TB, newMock func(ctrl *gomock.Controller) M) M {
+	t.Helper()
+	return MockIn[I](di.Default(), t, newMock)
+}
+
+// MockIn builds a mock with newMock and overrides I with it in c until the
+// test ends. The mock uses the controller registered in c for the test, which
+// is created if there is none. The test fails if the mock does not implement I.
+func MockIn[I, M any](c *di.Container, t testing.TB, newMock func(ctrl *gomock.Controller) M) M {
+	t.Helper()
+	ctrl, err := di.ResolveIn[*gomock.Controller](c)
+	if err != nil || ctrl.T != t {
+		ctrl = RegisterControllerIn(c, t)
+	}
+
+	m := newMock(ctrl)
+	impl, ok := any(m).(I)
+	if !ok {
+		t.Fatalf("dimock: %T does not implement %s", m, typeName[I]())
+	}
+	di.OverrideIn(c, t, impl)
+	return m
+}
+
+// typeName returns the name of the type I
+func typeName[I any]() string {
+	return fmt.Sprintf("%T", new(I))[1:]
+}
@@ -0,0 +1,145 @@
+package dimock_test
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/dimock"
+	"go.uber.org/mock/gomock"
+)
+
+type Mailer interface {
+	Send(to string) error
+}
+
+type smtpMailer struct{}
+
+func (smtpMailer) Send(string) error { return nil }
+
+// MockMailer is what mockgen generates for Mailer
+type MockMailer struct {
+	ctrl     *gomock.Controller
+	recorder *MockMailerMockRecorder
+}
+
+type MockMailerMockRecorder struct {
+	mock *MockMailer
+}
+
+func NewMockMailer(ctrl *gomock.Controller) *MockMailer {
+	mock := &MockMailer{ctrl: ctrl}
+	mock.recorder = &MockMailerMockRecorder{mock}
+	return mock
+}
+
+func (m *MockMailer) EXPECT() *MockMailerMockRecorder { return m.recorder }
+
+func (m *MockMailer) Send(to string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+func (mr *MockMailerMockRecorder) Send(to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockMailer)(nil).Send), to)
+}
+
+type Clock interface {
+	Now() int64
+}
+
+func TestMockIn(t *testing.T) {
+	c := di.New()
+	di.RegisterIn[Mailer](c, smtpMailer{})
+
+	t.Run("mocked", func(t *testing.T) {
+		mailer := dimock.MockIn[Mailer](c, t, NewMockMailer)
+		mailer.EXPECT().Send("ada@example.com").Return(nil)
+
+		got, err := di.ResolveIn[Mailer](c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != Mailer(mailer) {
+			t.Fatalf("resolved %T, want the mock", got)
+		}
+		if err := got.Send("ada@example.com"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	got, err := di.ResolveIn[Mailer](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got.(smtpMailer); !ok {
+		t.Fatalf("resolved %T after the test, want smtpMailer", got)
+	}
+	if _, err := di.ResolveIn[*gomock.Controller](c); err == nil {
+		t.Fatal("controller still registered after the test")
+	}
+}
+
+func TestMockInSharesController(t *testing.T) {
+	c := di.New()
+	ctrl := dimock.RegisterControllerIn(c, t)
+
+	mailer := dimock.MockIn[Mailer](c, t, NewMockMailer)
+	if mailer.ctrl != ctrl {
+		t.Fatal("mock does not use the registered controller")
+	}
+	got, err := di.ResolveIn[*gomock.Controller](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != ctrl {
+		t.Fatal("resolved a different controller")
+	}
+}
+
+func TestMockInNotImplemented(t *testing.T) {
+	c := di.New()
+	ft := &fatalT{TB: t}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		dimock.MockIn[Clock](c, ft, NewMockMailer)
+	}()
+	<-done
+
+	if !ft.failed {
+		t.Fatal("MockIn accepted a mock that does not implement the interface")
+	}
+}
+
+func TestMock(t *testing.T) {
+	restore := di.SetDefault(di.New())
+	defer restore()
+	di.Register[Mailer](smtpMailer{})
+
+	mailer := dimock.Mock[Mailer](t, NewMockMailer)
+	mailer.EXPECT().Send(gomock.Any()).Return(nil)
+
+	got := di.MustResolve[Mailer]()
+	if err := got.Send("grace@example.com"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fatalT records Fatalf and stops the goroutine like testing.T does
+type fatalT struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fatalT) Helper() {}
+
+func (f *fatalT) Fatalf(string, ...any) {
+	f.failed = true
+	runtime.Goexit()
+}
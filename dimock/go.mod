@@ -0,0 +1,8 @@
+module github.com/ryanbekhen/di/dimock
+
+go 1.25.0
+
+require (
+	github.com/ryanbekhen/di v1.0.0
+	go.uber.org/mock v0.6.0
+)
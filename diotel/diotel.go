@@ -0,0 +1,92 @@
+// Package diotel traces di resolutions and factory runs with OpenTelemetry.
+//
+// Resolutions made through di.ResolveCtx become children of the span in the
+// context, so slow lazy initialization shows up in the trace of the request
+// that triggered it.
+package diotel
+
+import (
+	"context"
+
+	"github.com/ryanbekhen/di"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the tracer of this package
+const instrumentationName = "github.com/ryanbekhen/di/diotel"
+
+// Option configures the instrumentation
+type Option func(i *instrumentation)
+
+// WithTracerProvider sets the tracer provider; the global one is used by default
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(i *instrumentation) {
+		i.provider = tp
+	}
+}
+
+// WithResolveSpans also records a span for every resolution, not only for
+// factory runs. Cached resolutions are cheap, so this is off by default.
+func WithResolveSpans() Option {
+	return func(i *instrumentation) {
+		i.resolves = true
+	}
+}
+
+// New returns instrumentation that records a span for every factory run
+func New(opts ...Option) di.Instrumentation {
+	i := &instrumentation{provider: otel.GetTracerProvider()}
+	for _, opt := range opts {
+		opt(i)
+	}
+	i.tracer = i.provider.Tracer(instrumentationName)
+	return i
+}
+
+// Instrument installs tracing in c, or in the default container if c is nil
+func Instrument(c *di.Container, opts ...Option) {
+	if c == nil {
+		c = di.Default()
+	}
+	c.Instrument(New(opts...))
+}
+
+// instrumentation starts spans for resolutions and factory runs
+type instrumentation struct {
+	provider trace.TracerProvider
+	tracer   trace.Tracer
+	resolves bool
+}
+
+// StartResolve starts a span for a resolution if resolve spans are enabled
+func (i *instrumentation) StartResolve(ctx context.Context, info di.ResolveInfo) (context.Context, func(error)) {
+	if !i.resolves {
+		return ctx, func(error) {}
+	}
+	return i.start(ctx, "di.resolve", info)
+}
+
+// StartFactory starts a span for a factory run
+func (i *instrumentation) StartFactory(ctx context.Context, info di.ResolveInfo) (context.Context, func(error)) {
+	return i.start(ctx, "di.factory", info)
+}
+
+// start starts a span named name for the registration in info
+func (i *instrumentation) start(ctx context.Context, name string, info di.ResolveInfo) (context.Context, func(error)) {
+	attrs := []attribute.KeyValue{attribute.String("di.type", info.Type)}
+	if info.Name != "" {
+		attrs = append(attrs, attribute.String("di.name", info.Name))
+	}
+
+	ctx, span := i.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,144 @@
+package diotel_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/diotel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type db struct{}
+type repo struct{ db *db }
+
+// newTraced returns a container traced into an in-memory exporter, whose
+// *repo depends on *db
+func newTraced(t *testing.T, opts ...diotel.Option) (*di.Container, *tracetest.InMemoryExporter, *sdktrace.TracerProvider) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	c := di.New()
+	diotel.Instrument(c, append([]diotel.Option{diotel.WithTracerProvider(tp)}, opts...)...)
+	di.RegisterFactoryIn(c, func() *db { return &db{} })
+	if err := c.RegisterConstructor(func(d *db) *repo { return &repo{db: d} }); err != nil {
+		t.Fatal(err)
+	}
+	return c, exporter, tp
+}
+
+// byName indexes the exported spans by name
+func byName(spans tracetest.SpanStubs) map[string][]tracetest.SpanStub {
+	m := make(map[string][]tracetest.SpanStub)
+	for _, s := range spans {
+		m[s.Name] = append(m[s.Name], s)
+	}
+	return m
+}
+
+func TestFactorySpansNestUnderRequest(t *testing.T) {
+	c, exporter, tp := newTraced(t)
+
+	ctx, request := tp.Tracer("test").Start(di.WithContainer(context.Background(), c), "request")
+	if _, err := di.ResolveCtx[*repo](ctx); err != nil {
+		t.Fatal(err)
+	}
+	request.End()
+
+	spans := byName(exporter.GetSpans())
+	factories := spans["di.factory"]
+	if len(factories) != 2 {
+		t.Fatalf("recorded %d factory spans, want 2", len(factories))
+	}
+	// the dependency ends first
+	dbSpan, repoSpan := factories[0], factories[1]
+	if repoSpan.Parent.SpanID() != request.SpanContext().SpanID() {
+		t.Fatal("the factory span is not a child of the request span")
+	}
+	if dbSpan.Parent.SpanID() != repoSpan.SpanContext.SpanID() {
+		t.Fatal("the dependency's factory span is not a child of its dependent's")
+	}
+	if len(spans["di.resolve"]) != 0 {
+		t.Fatal("resolve spans were recorded without WithResolveSpans")
+	}
+}
+
+func TestFactorySpanRecordsError(t *testing.T) {
+	c, exporter, _ := newTraced(t)
+	di.RegisterFactoryEIn(c, func() (*db, error) { return nil, errors.New("unreachable") })
+
+	if _, err := di.ResolveIn[*db](c); err == nil {
+		t.Fatal("the failing factory resolved")
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Status.Code != codes.Error || len(spans[0].Events) == 0 {
+		t.Fatalf("recorded %+v, want one span with the error", spans)
+	}
+}
+
+func TestResolveSpans(t *testing.T) {
+	c, exporter, _ := newTraced(t, diotel.WithResolveSpans())
+	di.MustResolveIn[*repo](c)
+
+	spans := byName(exporter.GetSpans())
+	resolves, factories := spans["di.resolve"], spans["di.factory"]
+	if len(resolves) != 2 || len(factories) != 2 {
+		t.Fatalf("recorded %d resolve and %d factory spans, want 2 of each", len(resolves), len(factories))
+	}
+	// *repo resolves, runs its factory, which resolves *db and runs its factory
+	dbResolve, repoResolve := resolves[0], resolves[1]
+	dbFactory, repoFactory := factories[0], factories[1]
+	if repoFactory.Parent.SpanID() != repoResolve.SpanContext.SpanID() ||
+		dbResolve.Parent.SpanID() != repoFactory.SpanContext.SpanID() ||
+		dbFactory.Parent.SpanID() != dbResolve.SpanContext.SpanID() {
+		t.Fatal("resolve and factory spans are not nested in resolution order")
+	}
+	if got := dbResolve.Attributes[0].Value.AsString(); got != "*diotel_test.db" {
+		t.Fatalf("di.type = %q", got)
+	}
+}
+
+func TestConcurrentResolutionsKeepTheirParents(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	c := di.New()
+	diotel.Instrument(c, diotel.WithTracerProvider(tp))
+	di.RegisterTransientIn(c, func() *db { return &db{} })
+
+	var wg sync.WaitGroup
+	parents := make(chan string, 8)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, span := tp.Tracer("test").Start(di.WithContainer(context.Background(), c), "request")
+			defer span.End()
+			di.MustResolveCtx[*db](ctx)
+			parents <- span.SpanContext().SpanID().String()
+		}()
+	}
+	wg.Wait()
+	close(parents)
+
+	want := make(map[string]bool)
+	for id := range parents {
+		want[id] = true
+	}
+	for _, s := range byName(exporter.GetSpans())["di.factory"] {
+		if !want[s.Parent.SpanID().String()] {
+			t.Fatalf("factory span has parent %s, not one of the requests", s.Parent.SpanID())
+		}
+		delete(want, s.Parent.SpanID().String())
+	}
+	if len(want) != 0 {
+		t.Fatalf("%d requests have no factory span", len(want))
+	}
+}
@@ -0,0 +1,92 @@
+// Package diplugin installs registrations from Go plugins, so optional
+// integrations can be shipped as shared objects and wired at startup.
+//
+// A plugin is a main package built with -buildmode=plugin that exports the
+// registration function:
+//
+//	func RegisterProviders(c *di.Container) {
+//		di.RegisterFactoryIn(c, NewS3Storage)
+//	}
+//
+// The plugin must be built against the same version of di as the program
+// that loads it.
+package diplugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"sort"
+	"strings"
+
+	"github.com/ryanbekhen/di"
+)
+
+// Symbol is the name of the function a plugin exports to make its registrations
+const Symbol = "RegisterProviders"
+
+// Load opens the plugin at path and installs its registrations in c as a
+// module named after the path, so they can be removed with c.Uninstall. A nil
+// c uses the default container. Loading the same plugin twice is an error.
+func Load(c *di.Container, path string) error {
+	if c == nil {
+		c = di.Default()
+	}
+
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("open plugin %s: %w", path, err)
+	}
+	sym, err := p.Lookup(Symbol)
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", path, err)
+	}
+
+	var register func(*di.Container)
+	switch fn := sym.(type) {
+	case func(*di.Container):
+		register = fn
+	case *func(*di.Container):
+		register = *fn
+	default:
+		return fmt.Errorf("plugin %s: %s has type %T, want func(*di.Container)", path, Symbol, sym)
+	}
+	if register == nil {
+		return fmt.Errorf("plugin %s: %s is nil", path, Symbol)
+	}
+
+	if err := c.Install(di.NewModule(ModuleName(path), register)); err != nil {
+		return fmt.Errorf("plugin %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadDir loads every plugin with the .so extension in dir, in lexical
+// order, stopping at the first one that fails
+func LoadDir(c *di.Container, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".so") {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if err := Load(c, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ModuleName returns the name of the module Load installs for the plugin at path
+func ModuleName(path string) string {
+	return "plugin:" + filepath.Clean(path)
+}
@@ -0,0 +1,102 @@
+package diplugin_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/diplugin"
+)
+
+// buildPlugin builds testdata/greeter into dir, skipping the test where
+// plugins cannot be built
+func buildPlugin(t *testing.T, dir string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("building a plugin is slow")
+	}
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skipf("plugins are not supported on %s", runtime.GOOS)
+	}
+
+	out := filepath.Join(dir, "greeter.so")
+	args := []string{"build", "-buildmode=plugin", "-o", out}
+	if raceEnabled {
+		args = append(args, "-race")
+	}
+	cmd := exec.Command("go", append(args, "./testdata/greeter")...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("cannot build plugin: %v\n%s", err, output)
+	}
+	return out
+}
+
+func TestLoadInstallsPluginRegistrations(t *testing.T) {
+	dir := t.TempDir()
+	buildPlugin(t, dir)
+	// files without the .so extension are ignored
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("plugins"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := di.New()
+	if err := diplugin.LoadDir(c, dir); err != nil {
+		t.Fatal(err)
+	}
+	greeting, err := di.ResolveNamedIn[string](c, "greeting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if greeting != "hello from a plugin" {
+		t.Fatalf("resolved %q", greeting)
+	}
+
+	path := filepath.Join(dir, "greeter.so")
+	if err := diplugin.Load(c, path); err == nil {
+		t.Fatal("loaded the same plugin twice")
+	}
+	if err := c.Uninstall(diplugin.ModuleName(path)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := di.ResolveNamedIn[string](c, "greeting"); err == nil {
+		t.Fatal("the registrations of an uninstalled plugin still resolve")
+	}
+}
+
+func TestLoadReportsInvalidPlugins(t *testing.T) {
+	dir := t.TempDir()
+	bogus := filepath.Join(dir, "bogus.so")
+	if err := os.WriteFile(bogus, []byte("not a shared object"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := di.New()
+	for _, path := range []string{bogus, filepath.Join(dir, "missing.so")} {
+		err := diplugin.Load(c, path)
+		if err == nil || !strings.Contains(err.Error(), "open plugin "+path) {
+			t.Errorf("Load(%s) returned %v, want an open error", path, err)
+		}
+	}
+	if err := diplugin.LoadDir(c, dir); err == nil {
+		t.Error("LoadDir succeeded with an invalid plugin in the directory")
+	}
+	if err := diplugin.LoadDir(c, filepath.Join(dir, "missing")); err == nil {
+		t.Error("LoadDir succeeded on a missing directory")
+	}
+}
+
+func TestLoadDirWithoutPlugins(t *testing.T) {
+	if err := diplugin.LoadDir(di.New(), t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestModuleNameCleansPath(t *testing.T) {
+	if got := diplugin.ModuleName("plugins/../plugins/s3.so"); got != "plugin:plugins/s3.so" {
+		t.Fatalf("ModuleName returned %q", got)
+	}
+}
@@ -0,0 +1,5 @@
+//go:build race
+
+package diplugin_test
+
+const raceEnabled = true
@@ -0,0 +1,11 @@
+// Command greeter is a plugin the diplugin tests build and load
+package main
+
+import "github.com/ryanbekhen/di"
+
+// RegisterProviders registers the greeting
+func RegisterProviders(c *di.Container) {
+	di.RegisterNamedIn(c, "greeting", "hello from a plugin")
+}
+
+func main() {}
@@ -0,0 +1,65 @@
+// Package diprom exports di container metrics to Prometheus.
+//
+//	c := di.New(di.WithMetrics(diprom.New(prometheus.DefaultRegisterer)))
+package diprom
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/ryanbekhen/di"
+)
+
+// Metrics records container metrics in Prometheus collectors labeled by
+// registered type and name
+type Metrics struct {
+	resolves        *prometheus.CounterVec
+	cacheHits       *prometheus.CounterVec
+	factoryDuration *prometheus.HistogramVec
+}
+
+// New creates the collectors and registers them with reg
+func New(reg prometheus.Registerer) *Metrics {
+	labels := []string{"type", "name", "outcome"}
+	m := &Metrics{
+		resolves: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "di",
+			Name:      "resolutions_total",
+			Help:      "Resolutions by registration and outcome.",
+		}, labels),
+		cacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "di",
+			Name:      "cache_hits_total",
+			Help:      "Resolutions served from the singleton cache.",
+		}, []string{"type", "name"}),
+		factoryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "di",
+			Name:      "factory_duration_seconds",
+			Help:      "Factory run time by registration and outcome.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+		}, labels),
+	}
+	reg.MustRegister(m.resolves, m.cacheHits, m.factoryDuration)
+	return m
+}
+
+// ObserveResolve counts a resolution
+func (m *Metrics) ObserveResolve(info di.ResolveInfo) {
+	m.resolves.WithLabelValues(info.Type, info.Name, outcome(info.Err)).Inc()
+}
+
+// ObserveFactory records the duration of a factory run
+func (m *Metrics) ObserveFactory(info di.ResolveInfo) {
+	m.factoryDuration.WithLabelValues(info.Type, info.Name, outcome(info.Err)).Observe(info.Duration.Seconds())
+}
+
+// ObserveCacheHit counts a resolution served from the cache
+func (m *Metrics) ObserveCacheHit(info di.ResolveInfo) {
+	m.cacheHits.WithLabelValues(info.Type, info.Name).Inc()
+}
+
+// outcome labels the result of an operation
+func outcome(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
@@ -0,0 +1,111 @@
+// Package direcover recovers panics in HTTP handlers and reports them the way
+// the container is configured to.
+//
+// Recovered panics are logged to the container's logger, or the standard
+// logger if it has none, and passed to every Handler registered with
+// HandleIn, for example to send them to an error tracker:
+//
+//	direcover.HandleIn(c, func(ctx context.Context, p *direcover.Panic) {
+//		tracker.Capture(ctx, p)
+//	})
+//
+// Installing Module adds the recovery middleware in front of every route of
+// dihttpserver. The gRPC interceptors are in the digrpc module.
+package direcover
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/dihttpserver"
+)
+
+// HandlersGroup is the group HandleIn registers handlers in
+const HandlersGroup = "direcover.handlers"
+
+// Panic describes a recovered panic
+type Panic struct {
+	// Value is the value passed to panic
+	Value any
+	// Stack is the stack of the panicking goroutine
+	Stack []byte
+	// Operation names what panicked, such as "GET /users" or a gRPC method
+	Operation string
+}
+
+// Error describes the panic and the operation it interrupted
+func (p *Panic) Error() string {
+	return fmt.Sprintf("panic in %s: %v", p.Operation, p.Value)
+}
+
+// Handler is called with every recovered panic
+type Handler func(ctx context.Context, p *Panic)
+
+// Module adds the recovery middleware to dihttpserver, ahead of all other
+// middleware. It provides the "recovery" capability.
+var Module = di.NewModule("direcover", func(c *di.Container) {
+	dihttpserver.Use(c, Middleware(c), di.WithPriority(math.MaxInt))
+}, di.Provides("recovery"))
+
+// HandleIn registers h to be called with the panics recovered for c
+func HandleIn(c *di.Container, h Handler, opts ...di.RegisterOption) {
+	di.RegisterIn(c, h, append(opts, di.WithGroup(HandlersGroup))...)
+}
+
+// Recovered builds the Panic for the value v returned by recover in
+// operation and reports it for c. A nil c uses the default container.
+func Recovered(ctx context.Context, c *di.Container, operation string, v any) *Panic {
+	p := &Panic{Value: v, Stack: debug.Stack(), Operation: operation}
+	Report(ctx, c, p)
+	return p
+}
+
+// Report logs p to the logger of c, or the standard logger if c has none,
+// and passes it to the handlers registered in c
+func Report(ctx context.Context, c *di.Container, p *Panic) {
+	if c == nil {
+		c = di.Default()
+	}
+	if l := c.Logger(); l != nil {
+		l.ErrorContext(ctx, "recovered panic", "operation", p.Operation, "panic", fmt.Sprint(p.Value), "stack", string(p.Stack))
+	} else {
+		log.Printf("direcover: %v\n%s", p, p.Stack)
+	}
+
+	handlers, err := di.ResolveGroupIn[Handler](c, HandlersGroup)
+	if err != nil {
+		log.Printf("direcover: resolving panic handlers: %v", err)
+		return
+	}
+	for _, h := range handlers {
+		h(ctx, p)
+	}
+}
+
+// Middleware returns middleware that recovers panics in the handlers it wraps,
+// reports them for c and answers 500 Internal Server Error. Panics with
+// http.ErrAbortHandler are passed on, as net/http expects. A nil c uses the
+// default container.
+func Middleware(c *di.Container) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				Recovered(r.Context(), c, r.Method+" "+r.URL.Path, v)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
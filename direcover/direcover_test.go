@@ -0,0 +1,94 @@
+package direcover_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/dihttpserver"
+	"github.com/ryanbekhen/di/direcover"
+)
+
+func panicking(w http.ResponseWriter, r *http.Request) {
+	panic("nil map write")
+}
+
+func TestMiddlewareReportsThroughContainer(t *testing.T) {
+	var logs bytes.Buffer
+	c := di.New(di.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	var reported []*direcover.Panic
+	direcover.HandleIn(c, func(_ context.Context, p *direcover.Panic) {
+		reported = append(reported, p)
+	})
+
+	rec := httptest.NewRecorder()
+	direcover.Middleware(c)(http.HandlerFunc(panicking)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d, want 500", rec.Code)
+	}
+	if len(reported) != 1 || reported[0].Operation != "GET /users" || reported[0].Value != "nil map write" {
+		t.Fatalf("reported %+v", reported)
+	}
+	if !bytes.Contains(reported[0].Stack, []byte("panicking")) {
+		t.Fatal("the stack does not show the panicking handler")
+	}
+	if !strings.Contains(logs.String(), "recovered panic") || !strings.Contains(logs.String(), "GET /users") {
+		t.Fatalf("the container logger did not receive the panic: %s", logs.String())
+	}
+}
+
+func TestMiddlewarePassesAbortHandlerOn(t *testing.T) {
+	handler := direcover.Middleware(di.New())(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Fatalf("recovered %v, want http.ErrAbortHandler", v)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestModuleRecoversDihttpserverRoutes(t *testing.T) {
+	c := di.New()
+	di.RegisterIn(c, dihttpserver.Config{Addr: "127.0.0.1:0"})
+	dihttpserver.HandleFunc(c, "/boom", panicking)
+	reported := make(chan *direcover.Panic, 1)
+	direcover.HandleIn(c, func(_ context.Context, p *direcover.Panic) { reported <- p })
+
+	if err := c.Use(dihttpserver.Module, direcover.Module); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Shutdown(context.Background())
+
+	resp, err := http.Get("http://" + di.MustResolveIn[*http.Server](c).Addr + "/boom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status %d, want 500", resp.StatusCode)
+	}
+	if p := <-reported; p.Operation != "GET /boom" {
+		t.Fatalf("reported %v", p)
+	}
+}
+
+func TestPanicError(t *testing.T) {
+	p := &direcover.Panic{Value: errors.New("boom"), Operation: "GET /"}
+	if got := p.Error(); got != "panic in GET /: boom" {
+		t.Fatalf("Error() = %q", got)
+	}
+}
@@ -0,0 +1,66 @@
+// Package disuite fills test suite structs, such as testify suites, from a
+// container.
+//
+// Setup injects the fields tagged `di:"inject"` from a scope opened for the
+// suite, so scoped instances are shared by the tests of the suite and torn
+// down after them:
+//
+//	type UserSuite struct {
+//		suite.Suite
+//		Repo *UserRepository `di:"inject"`
+//	}
+//
+//	func (s *UserSuite) SetupSuite() {
+//		s.Require().NoError(disuite.Setup(s, testContainer))
+//	}
+package disuite
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+// scopes holds the open scope of each suite set up without a T method
+var scopes sync.Map
+
+// Setup opens a scope on c for the suite s and fills its tagged fields from
+// it. If s has a T() *testing.T method, as testify suites do, the scope is
+// closed when that test ends; otherwise Teardown closes it. s must be a
+// pointer to a struct. A nil c uses the default container.
+func Setup(s any, c *di.Container) error {
+	if c == nil {
+		c = di.Default()
+	}
+	scope := c.NewScope()
+	if err := scope.InjectStruct(s); err != nil {
+		return errors.Join(fmt.Errorf("set up suite %T: %w", s, err), scope.Close())
+	}
+
+	if suite, ok := s.(interface{ T() *testing.T }); ok {
+		t := suite.T()
+		t.Cleanup(func() {
+			if err := scope.Close(); err != nil {
+				t.Errorf("disuite: closing the scope of %T: %v", s, err)
+			}
+		})
+		return nil
+	}
+	if previous, loaded := scopes.Swap(s, scope); loaded {
+		return previous.(*di.Scope).Close()
+	}
+	return nil
+}
+
+// Teardown closes the scope Setup opened for s if no test closes it. It does
+// nothing for suites that are not set up or have a T method.
+func Teardown(s any) error {
+	scope, ok := scopes.LoadAndDelete(s)
+	if !ok {
+		return nil
+	}
+	return scope.(*di.Scope).Close()
+}
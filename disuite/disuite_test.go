@@ -0,0 +1,83 @@
+package disuite_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/disuite"
+)
+
+type suiteDB struct{ closed bool }
+
+func (db *suiteDB) Close() error {
+	db.closed = true
+	return nil
+}
+
+type suiteClock struct{}
+
+// userSuite stands in for a testify suite, which has a T method
+type userSuite struct {
+	t       *testing.T
+	DB      *suiteDB    `di:"inject"`
+	Clock   *suiteClock `di:"inject,name=frozen"`
+	ignored *suiteDB
+}
+
+func (s *userSuite) T() *testing.T { return s.t }
+
+func newContainer() *di.Container {
+	c := di.New()
+	di.RegisterScopedIn(c, func(*di.Scope) *suiteDB { return &suiteDB{} })
+	di.RegisterNamedIn(c, "frozen", &suiteClock{})
+	return c
+}
+
+func TestSetupInjectsAndClosesWithTheSuiteTest(t *testing.T) {
+	c := newContainer()
+	s := &userSuite{}
+	t.Run("suite", func(t *testing.T) {
+		s.t = t
+		if err := disuite.Setup(s, c); err != nil {
+			t.Fatal(err)
+		}
+		if s.DB == nil || s.Clock == nil || s.ignored != nil {
+			t.Fatalf("suite filled as %+v", s)
+		}
+		if s.DB.closed {
+			t.Fatal("the suite scope closed before the suite ended")
+		}
+	})
+	if !s.DB.closed {
+		t.Fatal("the suite scope was not closed when the suite ended")
+	}
+}
+
+func TestTeardownClosesSuitesWithoutT(t *testing.T) {
+	c := newContainer()
+	s := &struct {
+		DB *suiteDB `di:"inject"`
+	}{}
+	if err := disuite.Setup(s, c); err != nil {
+		t.Fatal(err)
+	}
+	if err := disuite.Teardown(s); err != nil {
+		t.Fatal(err)
+	}
+	if !s.DB.closed {
+		t.Fatal("Teardown did not close the suite scope")
+	}
+	if err := disuite.Teardown(s); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetupReportsMissingDependencies(t *testing.T) {
+	s := &struct {
+		DB *suiteDB `di:"inject"`
+	}{}
+	if err := disuite.Setup(s, di.New()); !errors.Is(err, di.ErrNotRegistered) {
+		t.Fatalf("got %v, want ErrNotRegistered", err)
+	}
+}
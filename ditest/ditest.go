@@ -0,0 +1,68 @@
+// Package ditest shares an expensive container between the tests of a
+// package while keeping each test isolated.
+//
+// The base container is set up and its singletons built once per test
+// binary, the first time a test forks it:
+//
+//	var base = ditest.Base(func(c *di.Container) {
+//		di.RegisterFactoryEIn(c, startTestDatabase)
+//		di.RegisterFactoryIn(c, NewUserRepository)
+//	})
+//
+//	func TestSignup(t *testing.T) {
+//		c := base.Fork(t)
+//		di.OverrideIn[Mailer](c, t, &fakeMailer{})
+//		...
+//	}
+//
+// A fork is a child of the base: it shares the singletons the base built and
+// takes registrations and overrides of its own without changing the base.
+package ditest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+// BaseContainer is a container set up once and forked by every test
+type BaseContainer struct {
+	setup func(c *di.Container)
+
+	once sync.Once
+	c    *di.Container
+	err  error
+}
+
+// Base returns a base container that runs setup and builds every singleton
+// it registers when it is first forked
+func Base(setup func(c *di.Container)) *BaseContainer {
+	return &BaseContainer{setup: setup}
+}
+
+// Container returns the base container, setting it up if needed
+func (b *BaseContainer) Container() (*di.Container, error) {
+	b.once.Do(func() {
+		b.c = di.New()
+		b.setup(b.c)
+		b.err = b.c.InitializeAll(context.Background())
+	})
+	return b.c, b.err
+}
+
+// Fork returns a child of the base container for the duration of the test.
+// Registrations and overrides made in the fork stay in it, and the instances
+// it built itself are torn down when the test ends. The test fails if the
+// base container could not be set up.
+func (b *BaseContainer) Fork(t testing.TB) *di.Container {
+	t.Helper()
+	base, err := b.Container()
+	if err != nil {
+		t.Fatalf("ditest: setting up the base container: %v", err)
+	}
+	c := base.Child()
+	t.Cleanup(c.Reset)
+	return c
+}
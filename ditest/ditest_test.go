@@ -0,0 +1,91 @@
+package ditest_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/ditest"
+)
+
+type schema struct{ tables int }
+
+type mailer interface{ Send(to string) string }
+
+type smtpMailer struct{}
+
+func (smtpMailer) Send(to string) string { return "smtp:" + to }
+
+type fakeMailer struct{}
+
+func (fakeMailer) Send(to string) string { return "fake:" + to }
+
+type signup struct {
+	db     *schema
+	mailer mailer
+}
+
+var setups atomic.Int32
+
+var base = ditest.Base(func(c *di.Container) {
+	setups.Add(1)
+	di.RegisterFactoryIn(c, func() *schema { return &schema{tables: 12} })
+	di.RegisterFactoryIn[mailer](c, func() mailer { return smtpMailer{} })
+})
+
+func TestForksShareTheWarmBase(t *testing.T) {
+	a := base.Fork(t)
+	b := base.Fork(t)
+
+	if di.MustResolveIn[*schema](a) != di.MustResolveIn[*schema](b) {
+		t.Fatal("forks built their own copy of a base singleton")
+	}
+	if setups.Load() != 1 {
+		t.Fatalf("the base was set up %d times", setups.Load())
+	}
+}
+
+func TestForkOverridesStayInTheFork(t *testing.T) {
+	c := base.Fork(t)
+	di.OverrideIn[mailer](c, t, fakeMailer{})
+	di.RegisterTransientIn(c, func() *signup {
+		return &signup{db: di.MustResolveIn[*schema](c), mailer: di.MustResolveIn[mailer](c)}
+	})
+
+	s := di.MustResolveIn[*signup](c)
+	if s.mailer.Send("ada") != "fake:ada" || s.db.tables != 12 {
+		t.Fatalf("signup wired with %+v", s)
+	}
+
+	other := base.Fork(t)
+	if got := di.MustResolveIn[mailer](other).Send("ada"); got != "smtp:ada" {
+		t.Fatalf("an override leaked into another fork: %q", got)
+	}
+	if di.ContainsIn[*signup](other) {
+		t.Fatal("a registration leaked into another fork")
+	}
+}
+
+func TestForkResetWhenTestEnds(t *testing.T) {
+	var closed bool
+	t.Run("fork", func(t *testing.T) {
+		c := base.Fork(t)
+		di.RegisterFactoryCleanupIn(c, func() (*signup, func()) {
+			return &signup{}, func() { closed = true }
+		})
+		di.MustResolveIn[*signup](c)
+	})
+	if !closed {
+		t.Fatal("the fork was not torn down when its test ended")
+	}
+}
+
+func TestBaseSetupError(t *testing.T) {
+	failing := ditest.Base(func(c *di.Container) {
+		di.RegisterFactoryEIn(c, func() (*schema, error) { return nil, errors.New("no database") })
+	})
+	if _, err := failing.Container(); err == nil {
+		t.Fatal("a base whose singletons fail to build reported no error")
+	}
+}
@@ -0,0 +1,126 @@
+// Package diuow runs units of work in a database transaction shared by every
+// repository resolved within them.
+//
+// Repositories take the DB handle instead of *sql.DB:
+//
+//	func NewOrderRepository(db diuow.DB) *OrderRepository { ... }
+//
+// Inside Do the handle is the transaction of the unit of work, which is
+// committed if the function succeeds and rolled back otherwise:
+//
+//	diuow.Register(c, nil)
+//	di.RegisterScopedIn(c, func(s *di.Scope) *OrderRepository {
+//		return NewOrderRepository(di.MustResolveIn[diuow.DB](s))
+//	})
+//
+//	err := diuow.Do(ctx, c, func(ctx context.Context) error {
+//		orders := di.MustResolveCtx[*OrderRepository](ctx)
+//		return orders.Create(ctx, order)
+//	})
+package diuow
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/ryanbekhen/di"
+)
+
+// DB is the part of *sql.DB and *sql.Tx repositories use
+type DB interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// unit is the unit of work of a scope
+type unit struct {
+	tx *sql.Tx
+}
+
+// settings holds the transaction options given to Register
+type settings struct {
+	opts *sql.TxOptions
+}
+
+// Register registers DB and *sql.Tx as scoped in c. Within a scope opened by
+// Do they resolve to its transaction, begun with opts; in other scopes DB
+// resolves to the *sql.DB registered in c and *sql.Tx fails to resolve.
+func Register(c *di.Container, opts *sql.TxOptions) {
+	di.RegisterIn(c, settings{opts: opts})
+	di.RegisterScopedIn(c, func(*di.Scope) *unit { return &unit{} })
+	di.RegisterScopedIn(c, func(s *di.Scope) DB {
+		if tx := di.MustResolveIn[*unit](s).tx; tx != nil {
+			return tx
+		}
+		return di.MustResolveIn[*sql.DB](s)
+	})
+	err := c.RegisterDynamicFactory(reflect.TypeFor[*sql.Tx](), di.Scoped, func(r di.Resolver) (any, error) {
+		u, err := di.ResolveIn[*unit](r)
+		if err != nil {
+			return nil, err
+		}
+		if u.tx == nil {
+			return nil, errors.New("no unit of work in progress")
+		}
+		return u.tx, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Do runs fn in a new scope on c whose DB is a transaction on the *sql.DB
+// registered in c. The scope is stored in the context fn receives. The
+// transaction is committed if fn returns nil and rolled back if it returns an
+// error or panics; the scope is closed afterwards. Register must have been
+// called on c.
+func Do(ctx context.Context, c *di.Container, fn func(ctx context.Context) error) (err error) {
+	cfg, err := di.ResolveIn[settings](c)
+	if err != nil {
+		return fmt.Errorf("diuow.Register was not called: %w", err)
+	}
+	db, err := di.ResolveIn[*sql.DB](c)
+	if err != nil {
+		return err
+	}
+
+	scope := c.NewScope()
+	defer func() {
+		if closeErr := scope.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("closing unit of work scope: %w", closeErr))
+		}
+	}()
+
+	tx, err := db.BeginTx(ctx, cfg.opts)
+	if err != nil {
+		return fmt.Errorf("begin unit of work: %w", err)
+	}
+	u, err := di.ResolveIn[*unit](scope)
+	if err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	u.tx = tx
+
+	committed := false
+	defer func() {
+		if !committed {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				err = errors.Join(err, fmt.Errorf("roll back unit of work: %w", rbErr))
+			}
+		}
+	}()
+
+	if err := fn(di.WithScope(ctx, scope)); err != nil {
+		return err
+	}
+	committed = true
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit unit of work: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package diuow_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/diuow"
+)
+
+// journal records what the fake driver was asked to do
+type journal struct {
+	mu  sync.Mutex
+	ops []string
+}
+
+func (j *journal) add(op string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.ops = append(j.ops, op)
+}
+
+func (j *journal) list() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return slices.Clone(j.ops)
+}
+
+type fakeConnector struct{ j *journal }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn(c), nil }
+func (c fakeConnector) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ j *journal }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) {
+	c.j.add("begin")
+	return fakeTx(c), nil
+}
+
+func (c fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.j.add(query)
+	return driver.RowsAffected(1), nil
+}
+
+type fakeTx struct{ j *journal }
+
+func (tx fakeTx) Commit() error {
+	tx.j.add("commit")
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.j.add("rollback")
+	return nil
+}
+
+type orderRepo struct{ db diuow.DB }
+
+func (r *orderRepo) create(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "insert order")
+	return err
+}
+
+type stockRepo struct{ db diuow.DB }
+
+func (r *stockRepo) reserve(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "update stock")
+	return err
+}
+
+func newContainer(t *testing.T) (*di.Container, *journal) {
+	t.Helper()
+	j := &journal{}
+	db := sql.OpenDB(fakeConnector{j: j})
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	c := di.New()
+	di.RegisterIn(c, db)
+	diuow.Register(c, nil)
+	di.RegisterScopedIn(c, func(s *di.Scope) *orderRepo { return &orderRepo{db: di.MustResolveIn[diuow.DB](s)} })
+	di.RegisterScopedIn(c, func(s *di.Scope) *stockRepo { return &stockRepo{db: di.MustResolveIn[diuow.DB](s)} })
+	return c, j
+}
+
+func placeOrder(ctx context.Context) error {
+	if err := di.MustResolveCtx[*orderRepo](ctx).create(ctx); err != nil {
+		return err
+	}
+	return di.MustResolveCtx[*stockRepo](ctx).reserve(ctx)
+}
+
+func TestDoCommitsSharedTransaction(t *testing.T) {
+	c, j := newContainer(t)
+	err := diuow.Do(context.Background(), c, func(ctx context.Context) error {
+		if di.MustResolveCtx[*orderRepo](ctx).db != di.MustResolveCtx[*stockRepo](ctx).db {
+			return errors.New("repositories got different handles")
+		}
+		if _, err := di.ResolveCtx[*sql.Tx](ctx); err != nil {
+			return err
+		}
+		return placeOrder(ctx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"begin", "insert order", "update stock", "commit"}; !slices.Equal(j.list(), want) {
+		t.Fatalf("driver saw %v, want %v", j.list(), want)
+	}
+}
+
+func TestDoRollsBackOnError(t *testing.T) {
+	c, j := newContainer(t)
+	boom := errors.New("out of stock")
+	err := diuow.Do(context.Background(), c, func(ctx context.Context) error {
+		if err := placeOrder(ctx); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("Do returned %v", err)
+	}
+	if want := []string{"begin", "insert order", "update stock", "rollback"}; !slices.Equal(j.list(), want) {
+		t.Fatalf("driver saw %v, want %v", j.list(), want)
+	}
+}
+
+func TestDoRollsBackOnPanic(t *testing.T) {
+	c, j := newContainer(t)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("the panic was swallowed")
+			}
+		}()
+		diuow.Do(context.Background(), c, func(ctx context.Context) error {
+			panic("handler bug")
+		})
+	}()
+	if want := []string{"begin", "rollback"}; !slices.Equal(j.list(), want) {
+		t.Fatalf("driver saw %v, want %v", j.list(), want)
+	}
+}
+
+func TestScopesOutsideUnitOfWorkUseDB(t *testing.T) {
+	c, j := newContainer(t)
+	scope := c.NewScope()
+	defer scope.Close()
+
+	repo := di.MustResolveIn[*orderRepo](scope)
+	if _, ok := repo.db.(*sql.DB); !ok {
+		t.Fatalf("repository got %T outside a unit of work, want *sql.DB", repo.db)
+	}
+	if _, err := di.ResolveIn[*sql.Tx](scope); err == nil {
+		t.Fatal("resolved a transaction outside a unit of work")
+	}
+	if err := repo.create(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"insert order"}; !slices.Equal(j.list(), want) {
+		t.Fatalf("driver saw %v, want %v", j.list(), want)
+	}
+}
+
+func TestDoWithoutRegister(t *testing.T) {
+	if err := diuow.Do(context.Background(), di.New(), func(context.Context) error { return nil }); err == nil {
+		t.Fatal("Do ran without Register")
+	}
+}
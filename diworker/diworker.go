@@ -0,0 +1,194 @@
+// Package diworker runs background jobs in their own di scope, the way
+// dihttp runs HTTP requests.
+//
+// Each job gets a fresh scope in its context. Handlers resolve job-scoped
+// instances from it, and the scope is closed when the handler returns or
+// panics:
+//
+//	pool := diworker.NewPool(c, func(ctx context.Context, msg Message) error {
+//		svc, err := di.ResolveCtx[*BillingService](ctx)
+//		if err != nil {
+//			return err
+//		}
+//		return svc.Handle(ctx, msg)
+//	}, diworker.WithWorkers(8))
+package diworker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/ryanbekhen/di"
+)
+
+// ErrStopped is returned by Submit once the pool is stopped or before it is started
+var ErrStopped = errors.New("worker pool is not running")
+
+// Handler processes one job
+type Handler[J any] func(ctx context.Context, job J) error
+
+// Process runs handle for job in a new scope on c, stored in the context
+// handle receives. A panic in handle is returned as an error. The scope is
+// closed before Process returns; an error closing it is joined to the result.
+// A nil c uses the default container.
+func Process[J any](ctx context.Context, c *di.Container, job J, handle Handler[J]) (err error) {
+	if c == nil {
+		c = di.Default()
+	}
+	scope := c.NewScope()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+		if closeErr := scope.Close(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("closing job scope: %w", closeErr))
+		}
+	}()
+	return handle(di.WithScope(ctx, scope), job)
+}
+
+// PoolOption configures a Pool
+type PoolOption func(o *poolOptions)
+
+// poolOptions holds the settings of a Pool
+type poolOptions struct {
+	workers int
+	queue   int
+	onError func(err error)
+}
+
+// WithWorkers sets how many jobs run at once, one by default
+func WithWorkers(n int) PoolOption {
+	return func(o *poolOptions) {
+		o.workers = n
+	}
+}
+
+// WithQueue sets how many submitted jobs wait for a worker before Submit
+// blocks, none by default
+func WithQueue(n int) PoolOption {
+	return func(o *poolOptions) {
+		o.queue = n
+	}
+}
+
+// WithErrorHandler sets the function called with the error of each failed
+// job. Failed jobs are logged by default.
+func WithErrorHandler(f func(err error)) PoolOption {
+	return func(o *poolOptions) {
+		o.onError = f
+	}
+}
+
+// Pool runs submitted jobs on a fixed number of workers, each job in its own
+// scope. Its Start and Stop methods fit di.OnStart and di.OnStop, so a pool
+// registered in a container runs with it.
+type Pool[J any] struct {
+	c      *di.Container
+	handle Handler[J]
+	opts   poolOptions
+
+	// mu guards jobs and running; Submit holds it for reading while it sends
+	mu      sync.RWMutex
+	jobs    chan J
+	running bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewPool creates a stopped pool that processes jobs with handle in scopes
+// on c. A nil c uses the default container.
+func NewPool[J any](c *di.Container, handle Handler[J], opts ...PoolOption) *Pool[J] {
+	if c == nil {
+		c = di.Default()
+	}
+	p := &Pool[J]{c: c, handle: handle, opts: poolOptions{workers: 1}}
+	for _, opt := range opts {
+		opt(&p.opts)
+	}
+	if p.opts.onError == nil {
+		p.opts.onError = func(err error) {
+			log.Printf("diworker: %v", err)
+		}
+	}
+	return p
+}
+
+// Start starts the workers. Jobs run with a context that carries the values
+// of ctx but is only canceled when Stop gives up waiting for them. Starting a
+// running pool does nothing.
+func (p *Pool[J]) Start(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.running {
+		return nil
+	}
+
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	p.jobs = make(chan J, p.opts.queue)
+	p.running = true
+	p.cancel = cancel
+	for range p.opts.workers {
+		p.wg.Add(1)
+		go p.work(jobCtx, p.jobs)
+	}
+	return nil
+}
+
+// work processes jobs until the channel is closed
+func (p *Pool[J]) work(ctx context.Context, jobs <-chan J) {
+	defer p.wg.Done()
+	for job := range jobs {
+		if err := Process(ctx, p.c, job, p.handle); err != nil {
+			p.opts.onError(err)
+		}
+	}
+}
+
+// Submit queues job, waiting for room until ctx is done. It fails with
+// ErrStopped if the pool is not running.
+func (p *Pool[J]) Submit(ctx context.Context, job J) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if !p.running {
+		return ErrStopped
+	}
+	select {
+	case p.jobs <- job:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop stops accepting jobs and waits for the queued and running ones to
+// finish. If ctx is done first, the context of the running jobs is canceled
+// and ctx.Err() is returned.
+func (p *Pool[J]) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.running {
+		p.mu.Unlock()
+		return nil
+	}
+	p.running = false
+	close(p.jobs)
+	cancel := p.cancel
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		cancel()
+		return nil
+	case <-ctx.Done():
+		cancel()
+		return ctx.Err()
+	}
+}
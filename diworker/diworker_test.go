@@ -0,0 +1,155 @@
+package diworker_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/diworker"
+)
+
+type jobTx struct {
+	mu     sync.Mutex
+	closed bool
+}
+
+func (tx *jobTx) Close() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.closed = true
+	return nil
+}
+
+func (tx *jobTx) isClosed() bool {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	return tx.closed
+}
+
+func TestProcessRunsJobInItsOwnScope(t *testing.T) {
+	c := di.New()
+	di.RegisterScopedIn(c, func(*di.Scope) *jobTx { return &jobTx{} })
+
+	var seen []*jobTx
+	handle := func(ctx context.Context, job int) error {
+		a := di.MustResolveCtx[*jobTx](ctx)
+		if di.MustResolveCtx[*jobTx](ctx) != a {
+			return errors.New("two instances in one job")
+		}
+		seen = append(seen, a)
+		return nil
+	}
+	for job := range 2 {
+		if err := diworker.Process(context.Background(), c, job, handle); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(seen) != 2 || seen[0] == seen[1] {
+		t.Fatal("jobs shared a scoped instance")
+	}
+	for _, tx := range seen {
+		if !tx.isClosed() {
+			t.Fatal("a job scope was not closed")
+		}
+	}
+}
+
+func TestProcessClosesScopeOnPanic(t *testing.T) {
+	c := di.New()
+	di.RegisterScopedIn(c, func(*di.Scope) *jobTx { return &jobTx{} })
+
+	var tx *jobTx
+	err := diworker.Process(context.Background(), c, "job", func(ctx context.Context, _ string) error {
+		tx = di.MustResolveCtx[*jobTx](ctx)
+		panic("handler bug")
+	})
+	if err == nil || !strings.Contains(err.Error(), "handler bug") {
+		t.Fatalf("Process returned %v, want the panic as an error", err)
+	}
+	if !tx.isClosed() {
+		t.Fatal("the scope of a panicking job was not closed")
+	}
+}
+
+func TestPoolRunsWithContainerLifecycle(t *testing.T) {
+	c := di.New()
+	di.RegisterScopedIn(c, func(*di.Scope) *jobTx { return &jobTx{} })
+
+	var mu sync.Mutex
+	var processed []int
+	var failures []error
+	pool := diworker.NewPool(c, func(ctx context.Context, job int) error {
+		if _, err := di.ResolveCtx[*jobTx](ctx); err != nil {
+			return err
+		}
+		if job < 0 {
+			return errors.New("negative job")
+		}
+		mu.Lock()
+		processed = append(processed, job)
+		mu.Unlock()
+		return nil
+	}, diworker.WithWorkers(3), diworker.WithQueue(4), diworker.WithErrorHandler(func(err error) {
+		mu.Lock()
+		failures = append(failures, err)
+		mu.Unlock()
+	}))
+	di.RegisterIn(c, pool,
+		di.OnStart(func(ctx context.Context, p *diworker.Pool[int]) error { return p.Start(ctx) }),
+		di.OnStop(func(ctx context.Context, p *diworker.Pool[int]) error { return p.Stop(ctx) }))
+
+	if err := pool.Submit(context.Background(), 1); !errors.Is(err, diworker.ErrStopped) {
+		t.Fatalf("Submit before start returned %v", err)
+	}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for job := -1; job < 10; job++ {
+		if err := pool.Submit(context.Background(), job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(processed) != 10 || len(failures) != 1 {
+		t.Fatalf("processed %d jobs with %v failures, want 10 and 1", len(processed), failures)
+	}
+	if err := pool.Submit(context.Background(), 1); !errors.Is(err, diworker.ErrStopped) {
+		t.Fatalf("Submit after stop returned %v", err)
+	}
+}
+
+func TestStopCancelsJobsAfterDeadline(t *testing.T) {
+	canceled := make(chan struct{})
+	started := make(chan struct{})
+	pool := diworker.NewPool(di.New(), func(ctx context.Context, _ int) error {
+		close(started)
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	}, diworker.WithErrorHandler(func(error) {}))
+	if err := pool.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Submit(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pool.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Stop returned %v, want context.DeadlineExceeded", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("the running job was not canceled")
+	}
+}
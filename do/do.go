@@ -0,0 +1,257 @@
+// Package do mirrors the API of github.com/samber/do on top of a di
+// container, so projects can switch by changing the import path and then move
+// to the native di API gradually:
+//
+//	import "github.com/ryanbekhen/di/do"
+//
+//	do.Provide(injector, func(i *do.Injector) (*Engine, error) {
+//		return &Engine{}, nil
+//	})
+//	engine := do.MustInvoke[*Engine](injector)
+//
+// Services provided through the shim are ordinary di registrations: the
+// container returned by Injector.Container resolves them with the di API.
+package do
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ryanbekhen/di"
+)
+
+// Provider builds a service from the injector
+type Provider[T any] func(i *Injector) (T, error)
+
+// Shutdownable is implemented by services that release resources on Shutdown
+type Shutdownable interface {
+	Shutdown() error
+}
+
+// Healthcheckable is implemented by services that report their health to HealthCheck
+type Healthcheckable interface {
+	HealthCheck() error
+}
+
+// Injector is a samber/do style view of a di container
+type Injector struct {
+	// c is the backing container; nil means the default di container
+	c *di.Container
+
+	mu sync.Mutex
+	// provided holds the names of the services declared through the injector
+	provided map[string]bool
+	// checks holds the health checks of the services built so far, in build order
+	checks []healthCheck
+}
+
+// healthCheck is the health check of a built service
+type healthCheck struct {
+	name  string
+	check func() error
+}
+
+// DefaultInjector is used by the functions given a nil injector. It is backed
+// by the default di container.
+var DefaultInjector = &Injector{}
+
+// New returns an injector backed by a new container
+func New() *Injector {
+	return FromContainer(di.New())
+}
+
+// FromContainer returns an injector backed by c, so services provided through
+// it and through the di API are resolvable from both
+func FromContainer(c *di.Container) *Injector {
+	return &Injector{c: c}
+}
+
+// Container returns the container backing the injector
+func (i *Injector) Container() *di.Container {
+	if i.c == nil {
+		return di.Default()
+	}
+	return i.c
+}
+
+// Shutdown shuts down the services built so far in reverse build order,
+// calling Shutdown on those that implement Shutdownable, and closes the
+// container. Errors are joined.
+func (i *Injector) Shutdown() error {
+	err := i.Container().Shutdown(context.Background())
+	i.mu.Lock()
+	i.checks = nil
+	i.mu.Unlock()
+	return err
+}
+
+// HealthCheck runs the health checks of the services built so far that
+// implement Healthcheckable, keyed by service name
+func (i *Injector) HealthCheck() map[string]error {
+	i.mu.Lock()
+	checks := append([]healthCheck(nil), i.checks...)
+	i.mu.Unlock()
+
+	results := make(map[string]error, len(checks))
+	for _, hc := range checks {
+		results[hc.name] = hc.check()
+	}
+	return results
+}
+
+// Provide declares a lazily built singleton of T. Declaring T twice panics.
+func Provide[T any](i *Injector, provider Provider[T]) {
+	i = orDefault(i)
+	i.declare(serviceName[T](""))
+	provide(i, "", provider)
+}
+
+// ProvideNamed declares a lazily built singleton of T under name.
+// Declaring the name twice panics.
+func ProvideNamed[T any](i *Injector, name string, provider Provider[T]) {
+	i = orDefault(i)
+	i.declare(serviceName[T](name))
+	provide(i, name, provider)
+}
+
+// ProvideValue declares value as the service of T. Declaring T twice panics.
+func ProvideValue[T any](i *Injector, value T) {
+	Provide(i, valueProvider(value))
+}
+
+// ProvideNamedValue declares value as the service of T under name.
+// Declaring the name twice panics.
+func ProvideNamedValue[T any](i *Injector, name string, value T) {
+	ProvideNamed(i, name, valueProvider(value))
+}
+
+// Override replaces the declaration of T, declaring it if needed
+func Override[T any](i *Injector, provider Provider[T]) {
+	i = orDefault(i)
+	i.redeclare(serviceName[T](""))
+	di.UnregisterIn[T](i.Container())
+	provide(i, "", provider)
+}
+
+// OverrideNamed replaces the declaration of T under name, declaring it if needed
+func OverrideNamed[T any](i *Injector, name string, provider Provider[T]) {
+	i = orDefault(i)
+	i.redeclare(serviceName[T](name))
+	di.UnregisterNamedIn[T](i.Container(), name)
+	provide(i, name, provider)
+}
+
+// OverrideValue replaces the declaration of T with value
+func OverrideValue[T any](i *Injector, value T) {
+	Override(i, valueProvider(value))
+}
+
+// OverrideNamedValue replaces the declaration of T under name with value
+func OverrideNamedValue[T any](i *Injector, name string, value T) {
+	OverrideNamed(i, name, valueProvider(value))
+}
+
+// Invoke returns the service of T, building it on first use
+func Invoke[T any](i *Injector) (T, error) {
+	return di.ResolveIn[T](orDefault(i).Container())
+}
+
+// InvokeNamed returns the service of T declared under name, building it on first use
+func InvokeNamed[T any](i *Injector, name string) (T, error) {
+	return di.ResolveNamedIn[T](orDefault(i).Container(), name)
+}
+
+// MustInvoke returns the service of T or panics
+func MustInvoke[T any](i *Injector) T {
+	v, err := Invoke[T](i)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// MustInvokeNamed returns the service of T declared under name or panics
+func MustInvokeNamed[T any](i *Injector, name string) T {
+	v, err := InvokeNamed[T](i, name)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// orDefault returns i, or DefaultInjector if i is nil
+func orDefault(i *Injector) *Injector {
+	if i == nil {
+		return DefaultInjector
+	}
+	return i
+}
+
+// serviceName returns the name samber/do gives the service of T declared under name
+func serviceName[T any](name string) string {
+	if name != "" {
+		return name
+	}
+	var zero T
+	return fmt.Sprintf("%T", &zero)[1:]
+}
+
+// declare records the service name, panicking if it is already declared
+func (i *Injector) declare(name string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.provided[name] {
+		panic(fmt.Errorf("service %s has already been declared", name))
+	}
+	if i.provided == nil {
+		i.provided = make(map[string]bool)
+	}
+	i.provided[name] = true
+}
+
+// redeclare records the service name, whether or not it is already declared
+func (i *Injector) redeclare(name string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.provided == nil {
+		i.provided = make(map[string]bool)
+	}
+	i.provided[name] = true
+}
+
+// provide registers provider in the container of i as a singleton that is
+// shut down with the container and health checked once built
+func provide[T any](i *Injector, name string, provider Provider[T]) {
+	build := func() (T, error) {
+		v, err := provider(i)
+		if err != nil {
+			return v, err
+		}
+		if hc, ok := any(v).(Healthcheckable); ok {
+			i.mu.Lock()
+			i.checks = append(i.checks, healthCheck{name: serviceName[T](name), check: hc.HealthCheck})
+			i.mu.Unlock()
+		}
+		return v, nil
+	}
+	stop := di.OnStop(func(_ context.Context, v T) error {
+		if s, ok := any(v).(Shutdownable); ok {
+			return s.Shutdown()
+		}
+		return nil
+	})
+
+	if name == "" {
+		di.RegisterFactoryEIn(i.Container(), build, stop)
+	} else {
+		di.RegisterNamedFactoryEIn(i.Container(), name, build, stop)
+	}
+}
+
+// valueProvider returns a provider that always returns value
+func valueProvider[T any](value T) Provider[T] {
+	return func(*Injector) (T, error) {
+		return value, nil
+	}
+}
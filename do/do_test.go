@@ -0,0 +1,142 @@
+package do_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+	"github.com/ryanbekhen/di/do"
+)
+
+type engine struct {
+	started   int
+	shutdown  []string
+	unhealthy error
+}
+
+func (e *engine) Shutdown() error {
+	e.shutdown = append(e.shutdown, "engine")
+	return nil
+}
+
+func (e *engine) HealthCheck() error { return e.unhealthy }
+
+type car struct {
+	engine *engine
+	log    *[]string
+}
+
+func (c *car) Shutdown() error {
+	*c.log = append(*c.log, "car")
+	return nil
+}
+
+func TestProvideIsLazySingleton(t *testing.T) {
+	i := do.New()
+	builds := 0
+	do.Provide(i, func(*do.Injector) (*engine, error) {
+		builds++
+		return &engine{}, nil
+	})
+	if builds != 0 {
+		t.Fatal("Provide built the service eagerly")
+	}
+
+	a := do.MustInvoke[*engine](i)
+	b := do.MustInvoke[*engine](i)
+	if a != b || builds != 1 {
+		t.Fatalf("built %d services for 2 invocations", builds)
+	}
+	if di.MustResolveIn[*engine](i.Container()) != a {
+		t.Fatal("the backing container resolves another instance")
+	}
+}
+
+func TestProvideTwicePanics(t *testing.T) {
+	i := do.New()
+	do.ProvideValue(i, &engine{})
+	defer func() {
+		if recover() == nil {
+			t.Fatal("declaring a service twice did not panic")
+		}
+	}()
+	do.ProvideValue(i, &engine{})
+}
+
+func TestNamedServicesAndOverride(t *testing.T) {
+	i := do.New()
+	do.ProvideNamedValue(i, "primary", "db-1")
+	do.ProvideNamedValue(i, "replica", "db-2")
+	if got := do.MustInvokeNamed[string](i, "replica"); got != "db-2" {
+		t.Fatalf("replica is %q", got)
+	}
+
+	do.OverrideNamedValue(i, "replica", "db-3")
+	if got := do.MustInvokeNamed[string](i, "replica"); got != "db-3" {
+		t.Fatalf("overridden replica is %q", got)
+	}
+
+	do.ProvideValue(i, &engine{started: 1})
+	do.OverrideValue(i, &engine{started: 2})
+	if got := do.MustInvoke[*engine](i).started; got != 2 {
+		t.Fatal("Override did not replace the service")
+	}
+}
+
+func TestInvokeErrors(t *testing.T) {
+	i := do.New()
+	if _, err := do.Invoke[*engine](i); !errors.Is(err, di.ErrNotRegistered) {
+		t.Fatalf("got %v, want ErrNotRegistered", err)
+	}
+
+	boom := errors.New("boom")
+	do.Provide(i, func(*do.Injector) (*engine, error) { return nil, boom })
+	if _, err := do.Invoke[*engine](i); !errors.Is(err, boom) {
+		t.Fatalf("got %v, want the provider error", err)
+	}
+}
+
+func TestShutdownInReverseBuildOrder(t *testing.T) {
+	i := do.New()
+	var log []string
+	e := &engine{}
+	do.ProvideValue(i, e)
+	do.Provide(i, func(i *do.Injector) (*car, error) {
+		return &car{engine: do.MustInvoke[*engine](i), log: &log}, nil
+	})
+	do.MustInvoke[*car](i)
+
+	if err := i.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	log = append(log, e.shutdown...)
+	if len(log) != 2 || log[0] != "car" || log[1] != "engine" {
+		t.Fatalf("shutdown order %v, want car before engine", log)
+	}
+}
+
+func TestHealthCheckOfBuiltServices(t *testing.T) {
+	i := do.New()
+	sick := errors.New("overheating")
+	do.ProvideValue(i, &engine{unhealthy: sick})
+	if checks := i.HealthCheck(); len(checks) != 0 {
+		t.Fatalf("checked services that were never built: %v", checks)
+	}
+
+	do.MustInvoke[*engine](i)
+	checks := i.HealthCheck()
+	if err, ok := checks["*do_test.engine"]; !ok || !errors.Is(err, sick) {
+		t.Fatalf("health checks %v, want the engine reported unhealthy", checks)
+	}
+}
+
+func TestNilInjectorUsesDefaultContainer(t *testing.T) {
+	c := di.New()
+	restore := di.SetDefault(c)
+	defer restore()
+	di.RegisterIn(c, "from default")
+
+	if got := do.MustInvoke[string](nil); got != "from default" {
+		t.Fatalf("resolved %q", got)
+	}
+}
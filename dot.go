@@ -0,0 +1,52 @@
+package di
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// ExportDOT writes the dependency graph of the default container in Graphviz DOT format
+func ExportDOT(w io.Writer) error {
+	return Default().ExportDOT(w)
+}
+
+// ExportDOT writes the dependency graph of c in Graphviz DOT format.
+// Unresolved registrations are dashed and missing dependencies are red.
+func (c *Container) ExportDOT(w io.Writer) error {
+	g := c.Graph()
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "digraph di {")
+	fmt.Fprintln(bw, "\trankdir=LR;")
+	fmt.Fprintln(bw, "\tnode [shape=box, fontname=\"Helvetica\"];")
+
+	for _, n := range g.Nodes {
+		label := n.Type
+		if n.Name != "" {
+			label += "\n" + strconv.Quote(n.Name)
+		}
+
+		var style string
+		switch n.State {
+		case Resolved:
+			label += "\n" + n.Lifetime.String()
+			style = `style=solid`
+		case Registered:
+			label += "\n" + n.Lifetime.String()
+			style = `style=dashed`
+		case Missing:
+			label += "\nmissing"
+			style = `style="dashed,filled", color=red, fillcolor="#ffe5e5"`
+		}
+		fmt.Fprintf(bw, "\t%s [label=%s, %s];\n", strconv.Quote(n.ID), strconv.Quote(label), style)
+	}
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(bw, "\t%s -> %s;\n", strconv.Quote(e.From), strconv.Quote(e.To))
+	}
+
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
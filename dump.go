@@ -0,0 +1,134 @@
+package di
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// callSite is the call stack of a registration, resolved to a location only
+// when it is shown
+type callSite struct {
+	pcs [16]uintptr
+	n   int
+}
+
+// captureSite returns the call stack of its caller
+func captureSite() callSite {
+	var s callSite
+	s.n = runtime.Callers(2, s.pcs[:])
+	return s
+}
+
+// copy returns a copy of s that does not keep its owner alive
+func (s callSite) copy() *callSite {
+	return &s
+}
+
+// String returns the file and line of the innermost caller outside this
+// package, or an empty string if there is none
+func (s callSite) String() string {
+	frames := runtime.CallersFrames(s.pcs[:s.n])
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !strings.HasPrefix(frame.Function, "github.com/ryanbekhen/di.") {
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// Dump writes the registrations of the default container to w
+func Dump(w io.Writer) error {
+	return Default().Dump(w)
+}
+
+// Dump writes every registration of c to w, grouped into registrations that
+// replaced another one, the ones with an instance available and the ones whose
+// factory has not run yet, with the place each was made. The registrations
+// of the parent containers follow.
+func (c *Container) Dump(w io.Writer) error {
+	var overridden, resolved, pending []*factory
+	for _, f := range c.sortedFactories(func(*factory) bool { return true }) {
+		switch {
+		case f.overrides != nil || c.parent != nil && c.parent.has(f.key):
+			overridden = append(overridden, f)
+		case c.state(f) == Resolved:
+			resolved = append(resolved, f)
+		default:
+			pending = append(pending, f)
+		}
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "container: %d registrations\n", len(overridden)+len(resolved)+len(pending))
+	c.dumpGroup(b, "overridden", overridden)
+	c.dumpGroup(b, "resolved", resolved)
+	c.dumpGroup(b, "pending", pending)
+
+	if c.parent != nil {
+		var parent strings.Builder
+		if err := c.parent.Dump(&parent); err != nil {
+			return err
+		}
+		b.WriteString("parent ")
+		b.WriteString(parent.String())
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dumpGroup writes a titled group of registrations to b
+func (c *Container) dumpGroup(b *strings.Builder, title string, group []*factory) {
+	if len(group) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s:\n", title)
+	for _, f := range group {
+		fmt.Fprintf(b, "  %v (%s", f.key, f.lifetime)
+		switch {
+		case f.target != nil:
+			fmt.Fprintf(b, ", alias of %v", *f.target)
+		case f.external:
+			b.WriteString(", instance")
+		default:
+			b.WriteString(", factory")
+		}
+		if f.fallback {
+			b.WriteString(", default")
+		}
+		if len(f.groups) > 0 {
+			fmt.Fprintf(b, ", groups %s", strings.Join(f.groups, ","))
+		}
+		if f.priority != 0 {
+			fmt.Fprintf(b, ", priority %d", f.priority)
+		}
+		if f.module != "" {
+			fmt.Fprintf(b, ", module %s", f.module)
+		}
+		if title == "overridden" && c.state(f) == Resolved {
+			b.WriteString(", resolved")
+		}
+		b.WriteString(")")
+
+		if site := f.site.String(); site != "" {
+			fmt.Fprintf(b, " at %s", site)
+		}
+		switch {
+		case f.overrides != nil:
+			if site := f.overrides.String(); site != "" {
+				fmt.Fprintf(b, ", replacing the registration at %s", site)
+			} else {
+				b.WriteString(", replacing an earlier registration")
+			}
+		case title == "overridden":
+			b.WriteString(", shadowing the parent registration")
+		}
+		b.WriteString("\n")
+	}
+}
@@ -0,0 +1,108 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// RegisterDynamic registers v as the singleton instance of t in the default
+// container. It is the non-generic form of Register, for code that discovers
+// types at runtime. v must be assignable to t.
+func RegisterDynamic(t reflect.Type, v any, opts ...RegisterOption) error {
+	return Default().RegisterDynamic(t, v, opts...)
+}
+
+// RegisterDynamic registers v as the singleton instance of t in c
+func (c *Container) RegisterDynamic(t reflect.Type, v any, opts ...RegisterOption) error {
+	if err := checkDynamic(t, v); err != nil {
+		return err
+	}
+	return c.addInstance(keyOf(t), v, opts)
+}
+
+// RegisterDynamicFactory registers a factory for t with the given lifetime in
+// the default container. It is the non-generic form of RegisterFactoryE,
+// RegisterScoped and RegisterTransient: f receives a resolver for the
+// container, or the scope for scoped registrations and transients resolved
+// from a scope, that carries the context of the resolution, and must return a
+// value assignable to t.
+func RegisterDynamicFactory(t reflect.Type, lifetime Lifetime, f func(r Resolver) (any, error), opts ...RegisterOption) error {
+	return Default().RegisterDynamicFactory(t, lifetime, f, opts...)
+}
+
+// RegisterDynamicFactory registers a factory for t with the given lifetime in c
+func (c *Container) RegisterDynamicFactory(t reflect.Type, lifetime Lifetime, f func(r Resolver) (any, error), opts ...RegisterOption) error {
+	if t == nil {
+		return errors.New("dynamic registration needs a non-nil type")
+	}
+	if f == nil {
+		return fmt.Errorf("factory for %v must be a non-nil func", t)
+	}
+	switch lifetime {
+	case Singleton, Scoped, Transient:
+	default:
+		return fmt.Errorf("unknown lifetime %v for %v", lifetime, t)
+	}
+
+	return c.addFactory(keyOf(t), lifetime, opts, func(r Resolver) (any, func(), error) {
+		v, err := f(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := checkDynamic(t, v); err != nil {
+			return nil, nil, err
+		}
+		return v, nil, nil
+	})
+}
+
+// ResolveDynamic retrieves the instance of t from the default container. It is
+// the non-generic form of Resolve and fails with the same errors.
+func ResolveDynamic(t reflect.Type) (any, error) {
+	return Default().ResolveDynamic(t)
+}
+
+// ResolveDynamic retrieves the instance of t from c
+func (c *Container) ResolveDynamic(t reflect.Type) (any, error) {
+	return resolveDynamic(c, t)
+}
+
+// ResolveDynamic retrieves the instance of t from the scope
+func (s *Scope) ResolveDynamic(t reflect.Type) (any, error) {
+	return resolveDynamic(s, t)
+}
+
+// resolveDynamic resolves the instance of t from r, checking its type
+func resolveDynamic(r Resolver, t reflect.Type) (any, error) {
+	if t == nil {
+		return nil, errors.New("dynamic resolution needs a non-nil type")
+	}
+	k := keyOf(t)
+	v, err := resolveType(r, t, k)
+	if err != nil {
+		return nil, err
+	}
+	if v != nil && !reflect.TypeOf(v).AssignableTo(t) {
+		return nil, fmt.Errorf("%w: instance for %v has type %T, which is not assignable to %v", ErrTypeMismatch, k, v, t)
+	}
+	return v, nil
+}
+
+// checkDynamic reports an error unless v can be stored as an instance of t
+func checkDynamic(t reflect.Type, v any) error {
+	if t == nil {
+		return errors.New("dynamic registration needs a non-nil type")
+	}
+	if v == nil {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+			return nil
+		}
+		return fmt.Errorf("%w: nil is not a valid %v", ErrTypeMismatch, t)
+	}
+	if !reflect.TypeOf(v).AssignableTo(t) {
+		return fmt.Errorf("%w: value of type %T is not assignable to %v", ErrTypeMismatch, v, t)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package di_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type dynGreeter interface{ Greet() string }
+
+type dynEnglish struct{ name string }
+
+func (g *dynEnglish) Greet() string { return "hello " + g.name }
+
+var (
+	dynGreeterType = reflect.TypeOf((*dynGreeter)(nil)).Elem()
+	dynEnglishType = reflect.TypeOf(&dynEnglish{})
+)
+
+func TestDynamicAndGenericRegistrationsAgree(t *testing.T) {
+	c := di.New()
+	if err := c.RegisterDynamic(dynEnglishType, &dynEnglish{name: "ada"}); err != nil {
+		t.Fatal(err)
+	}
+	g := di.MustResolveIn[*dynEnglish](c)
+
+	di.RegisterIn[dynGreeter](c, g)
+	v, err := c.ResolveDynamic(dynGreeterType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.(dynGreeter) != g {
+		t.Fatal("dynamic resolution returned another instance than the generic registration")
+	}
+}
+
+func TestRegisterDynamicFactoryLifetimes(t *testing.T) {
+	c := di.New()
+	calls := 0
+	err := c.RegisterDynamicFactory(dynGreeterType, di.Transient, func(r di.Resolver) (any, error) {
+		calls++
+		return &dynEnglish{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, _ := c.ResolveDynamic(dynGreeterType)
+	b, _ := c.ResolveDynamic(dynGreeterType)
+	if a == b || calls != 2 {
+		t.Fatalf("a transient registration built %d instances for 2 resolutions", calls)
+	}
+
+	if err := c.RegisterDynamicFactory(dynGreeterType, di.Scoped, func(r di.Resolver) (any, error) {
+		return &dynEnglish{}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	scope := c.NewScope()
+	defer scope.Close()
+	a, _ = scope.ResolveDynamic(dynGreeterType)
+	b, _ = scope.ResolveDynamic(dynGreeterType)
+	if a == nil || a != b {
+		t.Fatal("a scoped registration is not shared within its scope")
+	}
+}
+
+func TestDynamicTypeMismatch(t *testing.T) {
+	c := di.New()
+	if err := c.RegisterDynamic(dynGreeterType, "not a greeter"); !errors.Is(err, di.ErrTypeMismatch) {
+		t.Fatalf("RegisterDynamic returned %v, want ErrTypeMismatch", err)
+	}
+
+	if err := c.RegisterDynamicFactory(dynGreeterType, di.Singleton, func(di.Resolver) (any, error) {
+		return 42, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ResolveDynamic(dynGreeterType); !errors.Is(err, di.ErrTypeMismatch) {
+		t.Fatalf("ResolveDynamic returned %v, want ErrTypeMismatch", err)
+	}
+}
+
+func TestDynamicInvalidArguments(t *testing.T) {
+	c := di.New()
+	if _, err := c.ResolveDynamic(nil); err == nil {
+		t.Fatal("resolved a nil type")
+	}
+	if err := c.RegisterDynamicFactory(dynGreeterType, di.Lifetime(99), func(di.Resolver) (any, error) { return nil, nil }); err == nil {
+		t.Fatal("registered an unknown lifetime")
+	}
+	if err := c.RegisterDynamicFactory(dynGreeterType, di.Singleton, nil); err == nil {
+		t.Fatal("registered a nil factory")
+	}
+	if err := c.RegisterDynamic(dynEnglishType, nil); err != nil {
+		t.Fatalf("a nil pointer was rejected: %v", err)
+	}
+	if _, err := c.ResolveDynamic(reflect.TypeOf(0)); !errors.Is(err, di.ErrNotRegistered) {
+		t.Fatalf("got %v, want ErrNotRegistered", err)
+	}
+}
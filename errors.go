@@ -0,0 +1,251 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrNotRegistered is matched by errors for types with no registration
+	ErrNotRegistered = errors.New("not registered")
+	// ErrTypeMismatch is matched by errors for instances that are not of the requested type
+	ErrTypeMismatch = errors.New("type mismatch")
+	// ErrCycle is matched by errors for circular dependencies
+	ErrCycle = errors.New("circular dependency")
+	// ErrScopeRequired is matched by errors for scoped types resolved outside a scope
+	ErrScopeRequired = errors.New("scope required")
+	// ErrScopeClosed is matched by errors for resolutions from a closed scope
+	ErrScopeClosed = errors.New("scope closed")
+	// ErrConflict is matched by errors for keys that are already registered
+	ErrConflict = errors.New("conflicting registration")
+	// ErrMissingRequirement is matched by errors for modules whose required capabilities no module provides
+	ErrMissingRequirement = errors.New("missing requirement")
+)
+
+// ResolveError describes a failed resolution. It matches ErrNotRegistered.
+type ResolveError struct {
+	// Type is the name of the type that could not be resolved
+	Type string
+	// Name is the registration name that was requested, if any
+	Name string
+	// Registered lists the keys known to the container at the time of failure
+	Registered []string
+
+	// key is the requested key, unset in errors not made by a container
+	key key
+	// format is the formatter of the container that made the error, if any
+	format *ErrorFormatter
+}
+
+// ErrorFormatter renders an error of the container as text. It receives a
+// *ResolveError, *DependencyError, *CycleError or *PanicError and returns
+// an empty string to keep the default rendering. It must not call Error on
+// the error it renders, but may on the errors wrapped inside it.
+type ErrorFormatter func(err error) string
+
+// WithErrorFormatter makes the container render its errors with f
+func WithErrorFormatter(f ErrorFormatter) Option {
+	return func(c *Container) {
+		c.SetErrorFormatter(f)
+	}
+}
+
+// SetErrorFormatter makes the default container render its errors with f
+func SetErrorFormatter(f ErrorFormatter) {
+	Default().SetErrorFormatter(f)
+}
+
+// SetErrorFormatter makes c render the errors it returns from then on with
+// f. Passing nil restores the default formatting.
+func (c *Container) SetErrorFormatter(f ErrorFormatter) {
+	if f == nil {
+		c.errorFormatter.Store(nil)
+		return
+	}
+	c.errorFormatter.Store(&f)
+}
+
+// formatError renders err with format, if set, falling back to render
+func formatError(format *ErrorFormatter, err error, render func() string) string {
+	if format != nil {
+		if msg := (*format)(err); msg != "" {
+			return msg
+		}
+	}
+	return render()
+}
+
+// Error renders the error with the formatter of its container
+func (e *ResolveError) Error() string {
+	return formatError(e.format, e, e.message)
+}
+
+// message renders the error without a formatter
+func (e *ResolveError) message() string {
+	msg := fmt.Sprintf("no instance found for type %v", e.Type)
+	if e.Name != "" {
+		msg = fmt.Sprintf("no instance found for type %v named %q", e.Type, e.Name)
+	}
+	if suggestions := e.Suggestions(); len(suggestions) > 0 {
+		msg += "; did you mean " + strings.Join(suggestions, " or ") + "?"
+	}
+	return msg
+}
+
+// Suggestions returns the registered keys that closely match the requested
+// one. They are computed on each call, so failed resolutions whose error is
+// never rendered do not pay for them.
+func (e *ResolveError) Suggestions() []string {
+	want := e.Type
+	if e.key.typ != nil {
+		want = e.key.String()
+	} else if e.Name != "" {
+		want = fmt.Sprintf("%s named %q", e.Type, e.Name)
+	}
+	return suggest(want, e.Registered)
+}
+
+// CycleError describes a circular dependency. It matches ErrCycle.
+type CycleError struct {
+	// Path lists the keys of the cycle, starting and ending with the same one
+	Path []string
+
+	// format is the formatter of the container that made the error, if any
+	format *ErrorFormatter
+}
+
+// Error describes the cycle with the formatter of its container
+func (e *CycleError) Error() string {
+	return formatError(e.format, e, func() string {
+		return fmt.Sprintf("circular dependency: %s", strings.Join(e.Path, " → "))
+	})
+}
+
+// Is reports whether target is ErrCycle
+func (e *CycleError) Is(target error) bool {
+	return target == ErrCycle
+}
+
+// DependencyError describes a resolution that failed while building the
+// dependencies of another registration. It unwraps to the error of the
+// resolution that failed.
+type DependencyError struct {
+	// Chain lists the keys from the outermost resolution to the one that failed
+	Chain []string
+	// Err is the error of the resolution that failed
+	Err error
+
+	// format is the formatter of the container that made the error, if any
+	format *ErrorFormatter
+}
+
+// Error describes the failure with its chain of resolutions with the
+// formatter of its container
+func (e *DependencyError) Error() string {
+	return formatError(e.format, e, func() string {
+		return fmt.Sprintf("resolving %s: %v", strings.Join(e.Chain, " → "), e.Err)
+	})
+}
+
+// Unwrap returns the error of the resolution that failed
+func (e *DependencyError) Unwrap() error {
+	return e.Err
+}
+
+// PanicError describes a factory that panicked. The panic is recovered and
+// returned from Resolve instead of crashing the resolving goroutine; the
+// failed construction is not cached.
+type PanicError struct {
+	// Key identifies the registration whose factory panicked
+	Key string
+	// Value is the value passed to panic
+	Value any
+	// Stack is the stack trace of the panicking goroutine
+	Stack []byte
+
+	// format is the formatter of the container that made the error, if any
+	format *ErrorFormatter
+}
+
+// Error describes the panic with the formatter of its container
+func (e *PanicError) Error() string {
+	return formatError(e.format, e, func() string {
+		return fmt.Sprintf("factory for %s panicked: %v", e.Key, e.Value)
+	})
+}
+
+// Unwrap returns the panic value if it is an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// cycleError reports a circular dependency along path
+func (c *Container) cycleError(path []key) error {
+	return &CycleError{Path: keyNames(path), format: c.errorFormatter.Load()}
+}
+
+// buildError describes the failure of the factory for k run on goroutine g.
+// A failure among the dependencies of k reports the chain of resolutions
+// leading to it, from the outermost one being built on g.
+func (c *Container) buildError(g uint64, k key, err error) error {
+	var dep *DependencyError
+	if errors.As(err, &dep) && slices.Contains(dep.Chain, k.String()) {
+		// the chain was recorded where the failure happened
+		return err
+	}
+
+	chain := c.tracker.path(g)
+	var missing *ResolveError
+	if dep == nil && errors.As(err, &missing) && missing.key.typ != nil {
+		chain = append(chain, missing.key)
+	}
+	if len(chain) < 2 {
+		return fmt.Errorf("failed to build %v: %w", k, err)
+	}
+	return &DependencyError{Chain: keyNames(chain), Err: err, format: c.errorFormatter.Load()}
+}
+
+// keyNames returns the names of keys
+func keyNames(keys []key) []string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k.String()
+	}
+	return names
+}
+
+// Is reports whether target is ErrNotRegistered
+func (e *ResolveError) Is(target error) bool {
+	return target == ErrNotRegistered
+}
+
+// newResolveError builds a ResolveError for key with a snapshot of registered keys
+func (c *Container) newResolveError(k key) *ResolveError {
+	seen := make(map[string]bool)
+	c.instances.each(func(k key, _ any) bool {
+		seen[k.String()] = true
+		return true
+	})
+	c.factories.each(func(k key, _ *factory) bool {
+		seen[k.String()] = true
+		return true
+	})
+
+	registered := make([]string, 0, len(seen))
+	for name := range seen {
+		registered = append(registered, name)
+	}
+	sort.Strings(registered)
+
+	return &ResolveError{
+		Type:       k.typ.String(),
+		Name:       k.name,
+		Registered: registered,
+		key:        k,
+		format:     c.errorFormatter.Load(),
+	}
+}
@@ -0,0 +1,114 @@
+package di_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type errServer struct{}
+type errService struct{}
+type errDB struct{}
+
+// newChainContainer returns a container where *errServer needs *errService,
+// which needs the unregistered *errDB
+func newChainContainer() *di.Container {
+	c := di.New()
+	di.RegisterFactoryEIn(c, func() (*errServer, error) {
+		_, err := di.ResolveIn[*errService](c)
+		return &errServer{}, err
+	})
+	di.RegisterFactoryEIn(c, func() (*errService, error) {
+		_, err := di.ResolveIn[*errDB](c)
+		return &errService{}, err
+	})
+	return c
+}
+
+func TestDependencyErrorNamesChain(t *testing.T) {
+	_, err := di.ResolveIn[*errServer](newChainContainer())
+
+	var dep *di.DependencyError
+	if !errors.As(err, &dep) {
+		t.Fatalf("ResolveIn() error = %v, want a DependencyError", err)
+	}
+	want := []string{"*di_test.errServer", "*di_test.errService", "*di_test.errDB"}
+	if strings.Join(dep.Chain, ",") != strings.Join(want, ",") {
+		t.Fatalf("Chain = %v, want %v", dep.Chain, want)
+	}
+	if !errors.Is(err, di.ErrNotRegistered) {
+		t.Fatal("DependencyError does not unwrap to ErrNotRegistered")
+	}
+	if !strings.HasPrefix(err.Error(), "resolving *di_test.errServer → *di_test.errService → *di_test.errDB: ") {
+		t.Fatalf("Error() = %q", err.Error())
+	}
+}
+
+func TestErrorFormatterRendersEveryError(t *testing.T) {
+	format := di.WithErrorFormatter(func(err error) string {
+		var dep *di.DependencyError
+		var cycle *di.CycleError
+		var panicked *di.PanicError
+		switch {
+		case errors.As(err, &dep):
+			// hide the chain, keep the cause
+			return dep.Err.Error()
+		case errors.As(err, &cycle):
+			return "cycle"
+		case errors.As(err, &panicked):
+			return "panic"
+		}
+		return ""
+	})
+
+	c := newChainContainer()
+	format(c)
+	_, err := di.ResolveIn[*errServer](c)
+	if got := err.Error(); got != "no instance found for type *di_test.errDB" {
+		t.Fatalf("formatted DependencyError = %q", got)
+	}
+
+	c = di.New(format)
+	di.RegisterFactoryIn(c, func() *errDB { panic("boom") })
+	if _, err := di.ResolveIn[*errDB](c); !strings.Contains(err.Error(), "panic") || strings.Contains(err.Error(), "boom") {
+		t.Fatalf("formatted PanicError = %q", err.Error())
+	}
+	c = di.New(format)
+	c.RegisterConstructor(func(*errService) *errDB { return &errDB{} })
+	c.RegisterConstructor(func(*errDB) *errService { return &errService{} })
+	if _, err := di.ResolveIn[*errDB](c); err == nil || err.Error() != "cycle" {
+		t.Fatalf("formatted CycleError = %v", err)
+	}
+
+	// other containers keep the default rendering
+	_, err = di.ResolveIn[*errServer](newChainContainer())
+	if !strings.HasPrefix(err.Error(), "resolving ") {
+		t.Fatalf("a container without the formatter rendered %q", err.Error())
+	}
+}
+
+type errUserService struct{}
+
+func TestResolveErrorSuggestsCloseMatches(t *testing.T) {
+	c := di.New()
+	di.RegisterIn(c, errUserService{})
+
+	_, err := di.ResolveIn[*errUserService](c)
+	var missing *di.ResolveError
+	if !errors.As(err, &missing) {
+		t.Fatalf("ResolveIn() error = %v, want a ResolveError", err)
+	}
+	if s := missing.Suggestions(); len(s) != 1 || s[0] != "di_test.errUserService" {
+		t.Fatalf("Suggestions() = %v", s)
+	}
+	if !strings.HasSuffix(err.Error(), `did you mean di_test.errUserService?`) {
+		t.Fatalf("Error() = %q", err.Error())
+	}
+
+	manual := &di.ResolveError{Type: "*app.Store", Registered: []string{"*app.Stor", "*app.Queue"}}
+	if s := manual.Suggestions(); len(s) != 1 || s[0] != "*app.Stor" {
+		t.Fatalf("Suggestions() of a ResolveError made by hand = %v", s)
+	}
+}
@@ -0,0 +1,168 @@
+package di
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventKind identifies what an Event reports
+type EventKind int
+
+const (
+	// EventRegister reports a new registration
+	EventRegister EventKind = iota
+	// EventOverwrite reports a registration that replaced another one
+	EventOverwrite
+	// EventResolveHit reports a resolution served by an instance already built
+	EventResolveHit
+	// EventResolveMiss reports a resolution that found no instance built and
+	// went to the factory or the parent container, with its error if it failed
+	EventResolveMiss
+	// EventFactoryRun reports a factory run, with its duration and error
+	EventFactoryRun
+	// EventUnregister reports a removed registration
+	EventUnregister
+	// EventReset reports a container reset; it has no type
+	EventReset
+)
+
+// String returns the name of the event kind
+func (k EventKind) String() string {
+	switch k {
+	case EventRegister:
+		return "register"
+	case EventOverwrite:
+		return "overwrite"
+	case EventResolveHit:
+		return "resolve-hit"
+	case EventResolveMiss:
+		return "resolve-miss"
+	case EventFactoryRun:
+		return "factory-run"
+	case EventUnregister:
+		return "unregister"
+	case EventReset:
+		return "reset"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes something that happened in a container
+type Event struct {
+	Kind EventKind
+	// Time is when the event happened
+	Time time.Time
+	// Type is the name of the type concerned, empty for EventReset
+	Type string
+	// Name is the registration name, if any
+	Name string
+	// Module is the module that made the registration, for EventRegister and
+	// EventOverwrite
+	Module string
+	// Site is the file and line of the code that made the registration, for
+	// EventRegister and EventOverwrite, when known
+	Site string
+	// Duration is how long the factory ran, for EventFactoryRun
+	Duration time.Duration
+	// Err is the error of a factory run or a failed resolution
+	Err error
+}
+
+// subscribers holds the event subscribers of a container. The list is
+// published as an immutable slice so resolutions read it without locking.
+type subscribers struct {
+	// mu serializes the updates of list and next
+	mu   sync.Mutex
+	list atomic.Pointer[[]subscriber]
+	next uint64
+}
+
+// subscriber is a subscribed function with the id used to unsubscribe it
+type subscriber struct {
+	id uint64
+	fn func(Event)
+}
+
+// add subscribes fn and returns its id
+func (s *subscribers) add(fn func(Event)) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.next++
+	var next []subscriber
+	if l := s.list.Load(); l != nil {
+		next = slices.Clone(*l)
+	}
+	next = append(next, subscriber{id: s.next, fn: fn})
+	s.list.Store(&next)
+	return s.next
+}
+
+// remove unsubscribes the subscriber with the given id
+func (s *subscribers) remove(id uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l := s.list.Load()
+	if l == nil {
+		return
+	}
+	next := slices.DeleteFunc(slices.Clone(*l), func(sub subscriber) bool { return sub.id == id })
+	if len(next) == 0 {
+		s.list.Store(nil)
+		return
+	}
+	s.list.Store(&next)
+}
+
+// Subscribe calls fn with every event of the default container
+func Subscribe(fn func(Event)) (unsubscribe func()) {
+	return Default().Subscribe(fn)
+}
+
+// Subscribe calls fn with every registration, overwrite, resolution, factory
+// run, removal and reset in c until unsubscribe is called. fn runs
+// synchronously on the goroutine that caused the event, possibly
+// concurrently with itself, so it should hand the event off quickly. Events
+// of parent containers are delivered to their own subscribers.
+func (c *Container) Subscribe(fn func(Event)) (unsubscribe func()) {
+	id := c.subscribers.add(fn)
+	var once sync.Once
+	return func() {
+		once.Do(func() { c.subscribers.remove(id) })
+	}
+}
+
+// emit delivers an event about k to the subscribers of c, if any
+func (c *Container) emit(kind EventKind, k key, d time.Duration, err error) {
+	l := c.subscribers.list.Load()
+	if l == nil {
+		return
+	}
+	e := Event{Kind: kind, Time: time.Now(), Name: k.name, Duration: d, Err: err}
+	if k.typ != nil {
+		e.Type = k.typ.String()
+	}
+	for _, s := range *l {
+		s.fn(e)
+	}
+}
+
+// emitRegistration delivers the registration of f to the subscribers of c, if any
+func (c *Container) emitRegistration(f *factory, replaced bool) {
+	l := c.subscribers.list.Load()
+	if l == nil {
+		return
+	}
+	kind := EventRegister
+	if replaced {
+		kind = EventOverwrite
+	}
+	e := Event{Kind: kind, Time: time.Now(), Type: f.key.typ.String(), Name: f.key.name, Module: f.module, Site: f.site.String()}
+	for _, s := range *l {
+		s.fn(e)
+	}
+}
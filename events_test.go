@@ -0,0 +1,87 @@
+package di_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type eventService struct{}
+
+// eventLog records the kinds of the events delivered to it
+type eventLog struct {
+	mu    sync.Mutex
+	kinds []di.EventKind
+}
+
+func (l *eventLog) record(e di.Event) {
+	l.mu.Lock()
+	l.kinds = append(l.kinds, e.Kind)
+	l.mu.Unlock()
+}
+
+func (l *eventLog) get() []di.EventKind {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]di.EventKind(nil), l.kinds...)
+}
+
+func TestSubscribeReceivesEvents(t *testing.T) {
+	c := di.New()
+	log := &eventLog{}
+	unsubscribe := c.Subscribe(log.record)
+	defer unsubscribe()
+
+	di.RegisterFactoryIn(c, func() *eventService { return &eventService{} })
+	di.RegisterFactoryIn(c, func() *eventService { return &eventService{} })
+	di.MustResolveIn[*eventService](c)
+	di.MustResolveIn[*eventService](c)
+	di.UnregisterIn[*eventService](c)
+	c.Reset()
+
+	want := []di.EventKind{
+		di.EventRegister, di.EventOverwrite,
+		di.EventFactoryRun, di.EventResolveMiss, di.EventResolveHit,
+		di.EventUnregister, di.EventReset,
+	}
+	got := log.get()
+	if len(got) != len(want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("events = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSubscribeEventDetails(t *testing.T) {
+	c := di.New()
+	var events []di.Event
+	unsubscribe := c.Subscribe(func(e di.Event) { events = append(events, e) })
+	defer unsubscribe()
+
+	di.RegisterNamedIn(c, "primary", &eventService{})
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	e := events[0]
+	if e.Type != "*di_test.eventService" || e.Name != "primary" || e.Site == "" || e.Time.IsZero() {
+		t.Fatalf("event = %+v", e)
+	}
+}
+
+func TestUnsubscribeStopsEvents(t *testing.T) {
+	c := di.New()
+	log := &eventLog{}
+	unsubscribe := c.Subscribe(log.record)
+	di.RegisterIn(c, &eventService{})
+	unsubscribe()
+	unsubscribe()
+	di.RegisterIn(c, &eventService{})
+
+	if got := log.get(); len(got) != 1 {
+		t.Fatalf("events = %v, want one before unsubscribing", got)
+	}
+}
@@ -0,0 +1,89 @@
+package di
+
+// RegisterFactory1 registers a factory whose dependency is resolved by the container
+func RegisterFactory1[T, D1 any](f func(D1) T, opts ...RegisterOption) {
+	RegisterFactory1In(Default(), f, opts...)
+}
+
+// RegisterFactory1In registers a factory in c whose dependency is resolved by the container
+func RegisterFactory1In[T, D1 any](c *Container, f func(D1) T, opts ...RegisterOption) {
+	c.registerFactory(typeKey[T](), Singleton, opts, func(r Resolver) (any, func(), error) {
+		d1, err := ResolveIn[D1](r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return f(d1), nil, nil
+	}, depKey[D1]())
+}
+
+// RegisterFactory2 registers a factory whose dependencies are resolved by the container
+func RegisterFactory2[T, D1, D2 any](f func(D1, D2) T, opts ...RegisterOption) {
+	RegisterFactory2In(Default(), f, opts...)
+}
+
+// RegisterFactory2In registers a factory in c whose dependencies are resolved by the container
+func RegisterFactory2In[T, D1, D2 any](c *Container, f func(D1, D2) T, opts ...RegisterOption) {
+	c.registerFactory(typeKey[T](), Singleton, opts, func(r Resolver) (any, func(), error) {
+		d1, err := ResolveIn[D1](r)
+		if err != nil {
+			return nil, nil, err
+		}
+		d2, err := ResolveIn[D2](r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return f(d1, d2), nil, nil
+	}, depKey[D1](), depKey[D2]())
+}
+
+// RegisterFactory3 registers a factory whose dependencies are resolved by the container
+func RegisterFactory3[T, D1, D2, D3 any](f func(D1, D2, D3) T, opts ...RegisterOption) {
+	RegisterFactory3In(Default(), f, opts...)
+}
+
+// RegisterFactory3In registers a factory in c whose dependencies are resolved by the container
+func RegisterFactory3In[T, D1, D2, D3 any](c *Container, f func(D1, D2, D3) T, opts ...RegisterOption) {
+	c.registerFactory(typeKey[T](), Singleton, opts, func(r Resolver) (any, func(), error) {
+		d1, err := ResolveIn[D1](r)
+		if err != nil {
+			return nil, nil, err
+		}
+		d2, err := ResolveIn[D2](r)
+		if err != nil {
+			return nil, nil, err
+		}
+		d3, err := ResolveIn[D3](r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return f(d1, d2, d3), nil, nil
+	}, depKey[D1](), depKey[D2](), depKey[D3]())
+}
+
+// RegisterFactory4 registers a factory whose dependencies are resolved by the container
+func RegisterFactory4[T, D1, D2, D3, D4 any](f func(D1, D2, D3, D4) T, opts ...RegisterOption) {
+	RegisterFactory4In(Default(), f, opts...)
+}
+
+// RegisterFactory4In registers a factory in c whose dependencies are resolved by the container
+func RegisterFactory4In[T, D1, D2, D3, D4 any](c *Container, f func(D1, D2, D3, D4) T, opts ...RegisterOption) {
+	c.registerFactory(typeKey[T](), Singleton, opts, func(r Resolver) (any, func(), error) {
+		d1, err := ResolveIn[D1](r)
+		if err != nil {
+			return nil, nil, err
+		}
+		d2, err := ResolveIn[D2](r)
+		if err != nil {
+			return nil, nil, err
+		}
+		d3, err := ResolveIn[D3](r)
+		if err != nil {
+			return nil, nil, err
+		}
+		d4, err := ResolveIn[D4](r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return f(d1, d2, d3, d4), nil, nil
+	}, depKey[D1](), depKey[D2](), depKey[D3](), depKey[D4]())
+}
@@ -0,0 +1,320 @@
+package di
+
+import (
+	"bytes"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// NodeState is the state of a registration in the dependency graph
+type NodeState int
+
+const (
+	// Registered nodes have a registration that has not been built yet
+	Registered NodeState = iota
+	// Resolved nodes have a cached instance
+	Resolved
+	// Missing nodes are depended upon but have no registration
+	Missing
+)
+
+// String returns the name of the state
+func (s NodeState) String() string {
+	switch s {
+	case Registered:
+		return "registered"
+	case Resolved:
+		return "resolved"
+	case Missing:
+		return "missing"
+	default:
+		return "NodeState(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// GraphNode is a registration, or a missing dependency, in the dependency graph
+type GraphNode struct {
+	// ID identifies the node in edges
+	ID string
+	// Type is the name of the registered type
+	Type string
+	// Name is the registration name, if any
+	Name     string
+	Lifetime Lifetime
+	State    NodeState
+}
+
+// GraphEdge records that the From node depends on the To node
+type GraphEdge struct {
+	From string
+	To   string
+}
+
+// Graph is a snapshot of the registrations of a container and their dependencies
+type Graph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// tracker follows the factories being run on each goroutine so nested
+// resolutions can be attributed to the registration that requested them
+type tracker struct {
+	// active counts the factory runs in progress, to skip tracking when idle
+	active atomic.Int64
+
+	mu sync.Mutex
+	// stacks holds the keys being built per goroutine
+	stacks map[uint64][]key
+	// building holds the goroutine building each key
+	building map[key]uint64
+	// waiting holds the key each goroutine waits for another goroutine to build
+	waiting map[uint64]key
+	// edges holds the dependencies observed at runtime
+	edges map[key]map[key]struct{}
+}
+
+// goid returns the id of the calling goroutine
+func goid() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+// push marks k as being built on goroutine g
+func (t *tracker) push(g uint64, k key) {
+	t.active.Add(1)
+	t.mu.Lock()
+	if t.stacks == nil {
+		t.stacks = make(map[uint64][]key)
+		t.building = make(map[key]uint64)
+	}
+	t.stacks[g] = append(t.stacks[g], k)
+	t.building[k] = g
+	t.mu.Unlock()
+}
+
+// pop marks the innermost build on goroutine g as finished
+func (t *tracker) pop(g uint64) {
+	t.mu.Lock()
+	stack := t.stacks[g]
+	if top := stack[len(stack)-1]; t.building[top] == g {
+		delete(t.building, top)
+	}
+	if len(stack) <= 1 {
+		delete(t.stacks, g)
+	} else {
+		t.stacks[g] = stack[:len(stack)-1]
+	}
+	t.mu.Unlock()
+	t.active.Add(-1)
+}
+
+// cycle returns the dependency path from k back to k if k is already being
+// built on the calling goroutine, or nil
+func (t *tracker) cycle(k key) []key {
+	if t.active.Load() == 0 {
+		return nil
+	}
+
+	g := goid()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stack := t.stacks[g]
+	for i, building := range stack {
+		if building == k {
+			return append(append([]key(nil), stack[i:]...), k)
+		}
+	}
+	return nil
+}
+
+// wait calls get, which blocks while another goroutine builds k, unless that
+// goroutine waits, directly or through others, for a key being built on the
+// calling goroutine. Waiting would then never end, so the circular
+// dependency is reported instead, as the error cycle makes of its path.
+func (t *tracker) wait(k key, get func() (any, error), cycle func(path []key) error) (any, error) {
+	if t.active.Load() == 0 {
+		return get()
+	}
+
+	g := goid()
+	t.mu.Lock()
+	if path := t.deadlock(g, k); path != nil {
+		t.mu.Unlock()
+		return nil, cycle(path)
+	}
+	if t.waiting == nil {
+		t.waiting = make(map[uint64]key)
+	}
+	t.waiting[g] = k
+	t.mu.Unlock()
+
+	defer func() {
+		t.mu.Lock()
+		delete(t.waiting, g)
+		t.mu.Unlock()
+	}()
+	return get()
+}
+
+// deadlock follows the goroutines building k and the keys they wait for and
+// returns the dependency path back to a key being built on goroutine g, or
+// nil if the chain ends elsewhere. t.mu must be held.
+func (t *tracker) deadlock(g uint64, k key) []key {
+	var chain []key
+	seen := make(map[uint64]bool)
+	for want := k; ; {
+		chain = append(chain, want)
+		owner, ok := t.building[want]
+		if !ok || seen[owner] {
+			return nil
+		}
+		seen[owner] = true
+
+		stack := t.stacks[owner]
+		i := slices.Index(stack, want)
+		if i < 0 {
+			return nil
+		}
+		if owner == g {
+			return append(slices.Clone(stack[i:]), chain...)
+		}
+		chain = append(chain, stack[i+1:]...)
+		if want, ok = t.waiting[owner]; !ok {
+			return nil
+		}
+	}
+}
+
+// path returns a copy of the keys being built on goroutine g, outermost first
+func (t *tracker) path(g uint64) []key {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.stacks[g])
+}
+
+// requester returns the key being built on the calling goroutine, if any
+func (t *tracker) requester() (key, bool) {
+	if t.active.Load() == 0 {
+		return key{}, false
+	}
+
+	g := goid()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stack := t.stacks[g]
+	if len(stack) == 0 {
+		return key{}, false
+	}
+	return stack[len(stack)-1], true
+}
+
+// observe records that the registration being built on this goroutine resolved k
+func (t *tracker) observe(k key) {
+	if t.active.Load() == 0 {
+		return
+	}
+
+	g := goid()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stack := t.stacks[g]
+	if len(stack) == 0 {
+		return
+	}
+	from := stack[len(stack)-1]
+	if t.edges == nil {
+		t.edges = make(map[key]map[key]struct{})
+	}
+	if t.edges[from] == nil {
+		t.edges[from] = make(map[key]struct{})
+	}
+	t.edges[from][k] = struct{}{}
+}
+
+// DependencyGraph returns the dependency graph of the default container
+func DependencyGraph() Graph {
+	return Default().Graph()
+}
+
+// Graph returns the registrations of c with the dependencies they declare
+// and the ones observed while their factories ran
+func (c *Container) Graph() Graph {
+	var g Graph
+	nodes := make(map[key]bool)
+	edges := make(map[GraphEdge]bool)
+
+	addEdge := func(from, to key) {
+		e := GraphEdge{From: from.String(), To: to.String()}
+		if !edges[e] {
+			edges[e] = true
+			g.Edges = append(g.Edges, e)
+		}
+	}
+
+	var depends []key
+	for _, f := range c.sortedFactories(func(*factory) bool { return true }) {
+		nodes[f.key] = true
+		g.Nodes = append(g.Nodes, GraphNode{
+			ID:       f.key.String(),
+			Type:     f.key.typ.String(),
+			Name:     f.key.name,
+			Lifetime: f.lifetime,
+			State:    c.state(f),
+		})
+		for _, dep := range f.deps {
+			addEdge(f.key, dep)
+			depends = append(depends, dep)
+		}
+	}
+
+	c.tracker.mu.Lock()
+	for from, tos := range c.tracker.edges {
+		for to := range tos {
+			addEdge(from, to)
+			depends = append(depends, to)
+		}
+	}
+	c.tracker.mu.Unlock()
+
+	for _, dep := range depends {
+		if !nodes[dep] {
+			nodes[dep] = true
+			g.Nodes = append(g.Nodes, GraphNode{ID: dep.String(), Type: dep.typ.String(), Name: dep.name, State: Missing})
+		}
+	}
+
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	return g
+}
+
+// state reports whether the registration f has an instance available
+func (c *Container) state(f *factory) NodeState {
+	if f.target != nil {
+		if target, ok := c.factories.load(*f.target); ok {
+			return c.state(target)
+		}
+		return Registered
+	}
+	if f.external || f.once != nil && f.once.Done() {
+		return Resolved
+	}
+	return Registered
+}
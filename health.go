@@ -0,0 +1,63 @@
+package di
+
+import (
+	"context"
+	"sync"
+)
+
+// HealthChecker is implemented by services that can report their health
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// CheckHealth checks the health of the default container's services
+func CheckHealth(ctx context.Context) map[string]error {
+	return Default().CheckHealth(ctx)
+}
+
+// CheckHealth runs the checks of every resolved singleton of c that
+// implements HealthChecker, concurrently, and returns their results keyed by
+// registration. This includes the unexpired instances of WithTTL and the live
+// instances of WithWeak registrations. A nil error means the service is
+// healthy. Registrations that have not been resolved yet, or whose instance
+// expired or was reclaimed, are not built and not reported.
+func (c *Container) CheckHealth(ctx context.Context) map[string]error {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]error)
+
+	check := func(k key, v any) {
+		checker, ok := v.(HealthChecker)
+		if !ok {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := checker.CheckHealth(ctx)
+			mu.Lock()
+			results[k.String()] = err
+			mu.Unlock()
+		}()
+	}
+	c.instances.each(func(k key, v any) bool {
+		check(k, v)
+		return true
+	})
+	c.factories.each(func(k key, f *factory) bool {
+		if f.ttl != nil {
+			if v, ok := f.ttl.current(); ok {
+				check(k, v)
+			}
+		}
+		if f.weak != nil {
+			if v, ok := f.weak.current(); ok {
+				check(k, v)
+			}
+		}
+		return true
+	})
+
+	wg.Wait()
+	return results
+}
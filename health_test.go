@@ -0,0 +1,58 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ryanbekhen/di"
+)
+
+type healthDB struct{ err error }
+
+func (d *healthDB) CheckHealth(context.Context) error { return d.err }
+
+type healthCache struct{ healthDB }
+type healthQueue struct{ healthDB }
+type healthMailer struct{ healthDB }
+
+func TestCheckHealthReportsResolvedSingletons(t *testing.T) {
+	c := di.New()
+	down := errors.New("down")
+	di.RegisterFactoryIn(c, func() *healthDB { return &healthDB{err: down} })
+	di.RegisterFactoryTTLIn(c, func() *healthCache { return &healthCache{} }, time.Minute)
+	di.RegisterFactoryIn(c, func() *healthQueue { return &healthQueue{} }, di.WithWeak())
+	di.RegisterFactoryIn(c, func() *healthMailer { return &healthMailer{} })
+
+	di.MustResolveIn[*healthDB](c)
+	di.MustResolveIn[*healthCache](c)
+	queue := di.MustResolveIn[*healthQueue](c)
+
+	results := c.CheckHealth(context.Background())
+	if len(results) != 3 {
+		t.Fatalf("CheckHealth reported %v, want the database, cache and queue", results)
+	}
+	if err := results["*di_test.healthDB"]; !errors.Is(err, down) {
+		t.Fatalf("database health = %v, want %v", err, down)
+	}
+	for _, k := range []string{"*di_test.healthCache", "*di_test.healthQueue"} {
+		if err, ok := results[k]; !ok || err != nil {
+			t.Fatalf("%s health = %v, %v", k, err, ok)
+		}
+	}
+	runtime.KeepAlive(queue)
+}
+
+func TestCheckHealthSkipsUnbuiltInstances(t *testing.T) {
+	c := di.New()
+	di.RegisterFactoryIn(c, func() *healthMailer { return &healthMailer{} })
+	di.RegisterFactoryTTLIn(c, func() *healthCache { return &healthCache{} }, 10*time.Millisecond)
+	di.MustResolveIn[*healthCache](c)
+	time.Sleep(20 * time.Millisecond)
+
+	if results := c.CheckHealth(context.Background()); len(results) != 0 {
+		t.Fatalf("CheckHealth reported %v for unresolved and expired instances", results)
+	}
+}
@@ -0,0 +1,150 @@
+package di
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ResolveInfo describes a resolution passed to resolution hooks
+type ResolveInfo struct {
+	// Type is the name of the type being resolved
+	Type string
+	// Name is the registration name, if any
+	Name string
+	// Duration is how long the resolution took; zero in before hooks
+	Duration time.Duration
+	// Err is the resolution error; nil in before hooks
+	Err error
+}
+
+// hooks holds the resolution hooks of a container. The hooks are published
+// as an immutable set so resolutions read them without locking.
+type hooks struct {
+	// mu serializes the updates of set
+	mu  sync.Mutex
+	set atomic.Pointer[hookSet]
+}
+
+// hookSet is the set of resolution hooks installed at some point
+type hookSet struct {
+	before []func(ResolveInfo) error
+	after  []func(ResolveInfo)
+}
+
+// load returns the installed hooks, or nil if there are none
+func (h *hooks) load() *hookSet {
+	return h.set.Load()
+}
+
+// update publishes a copy of the installed hooks modified by fn
+func (h *hooks) update(fn func(s *hookSet)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var next hookSet
+	if s := h.set.Load(); s != nil {
+		next.before = slices.Clone(s.before)
+		next.after = slices.Clone(s.after)
+	}
+	fn(&next)
+	h.set.Store(&next)
+}
+
+// OnBeforeResolve adds a hook to the default container that runs before every resolution
+func OnBeforeResolve(h func(ResolveInfo) error) {
+	Default().OnBeforeResolve(h)
+}
+
+// OnBeforeResolve adds a hook that runs before every resolution from c.
+// Returning an error aborts the resolution with that error.
+func (c *Container) OnBeforeResolve(h func(ResolveInfo) error) {
+	c.hooks.update(func(s *hookSet) {
+		s.before = append(s.before, h)
+	})
+}
+
+// OnAfterResolve adds a hook to the default container that runs after every resolution
+func OnAfterResolve(h func(ResolveInfo)) {
+	Default().OnAfterResolve(h)
+}
+
+// OnAfterResolve adds a hook that runs after every resolution from c,
+// successful or not
+func (c *Container) OnAfterResolve(h func(ResolveInfo)) {
+	c.hooks.update(func(s *hookSet) {
+		s.after = append(s.after, h)
+	})
+}
+
+// resolveKey resolves k from r, running the container's resolution hooks
+// around it and deriving component loggers if enabled
+func resolveKey(r Resolver, k key) (any, error) {
+	c := r.owner()
+	c.tracker.observe(k)
+
+	v, err := hookedResolve(r, k)
+	if err == nil && k == loggerKey {
+		if attr := c.componentLoggers.Load(); attr != nil {
+			v = c.componentLogger(*attr, v)
+		}
+	}
+	return v, err
+}
+
+// hookedResolve resolves k from r, running the container's resolution hooks around it
+func hookedResolve(r Resolver, k key) (any, error) {
+	hooks := r.owner().hooks.load()
+	metrics := r.owner().metrics.Load()
+	logger := r.owner().logger.Load()
+	stats := r.owner().stats.Load()
+	if hooks == nil && metrics == nil && logger == nil && stats == nil {
+		return instrumentedResolve(r, k)
+	}
+
+	var before []func(ResolveInfo) error
+	var after []func(ResolveInfo)
+	if hooks != nil {
+		before, after = hooks.before, hooks.after
+	}
+
+	info := ResolveInfo{Type: k.typ.String(), Name: k.name}
+	for _, hook := range before {
+		if err := hook(info); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	v, err := instrumentedResolve(r, k)
+	info.Duration = time.Since(start)
+	info.Err = err
+
+	for _, hook := range after {
+		hook(info)
+	}
+	if metrics != nil {
+		(*metrics).ObserveResolve(info)
+	}
+	if logger != nil {
+		logger.resolved(k, info)
+	}
+	if stats != nil {
+		stats.resolved(k, start)
+	}
+	return v, err
+}
+
+// instrumentedResolve resolves k from r inside the operations of the
+// container's instrumentation, if any
+func instrumentedResolve(r Resolver, k key) (any, error) {
+	c := r.owner()
+	if len(c.instruments.active()) == 0 {
+		return r.resolve(contextOf(r), k)
+	}
+	return c.instrument(contextOf(r), ResolveInfo{Type: k.typ.String(), Name: k.name}, Instrumentation.StartResolve, func(ctx context.Context) (any, error) {
+		return r.resolve(ctx, k)
+	})
+}
@@ -0,0 +1,78 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// InjectStruct fills the fields of the struct pointed to by target that are
+// tagged `di:"inject"` or `di:"inject,name=primary"` from the default container
+func InjectStruct(target any) error {
+	return Default().InjectStruct(target)
+}
+
+// InjectStruct fills the tagged fields of target from c
+func (c *Container) InjectStruct(target any) error {
+	return injectStruct(c, target)
+}
+
+// InjectStruct fills the tagged fields of target from the scope
+func (s *Scope) InjectStruct(target any) error {
+	return injectStruct(s, target)
+}
+
+// injectStruct resolves every tagged field of target from r
+func injectStruct(r Resolver, target any) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("inject target must be a non-nil pointer to a struct, got %T", target)
+	}
+
+	v = v.Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("di")
+		if !ok {
+			continue
+		}
+
+		name, err := parseInjectTag(tag)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if !field.IsExported() {
+			return fmt.Errorf("field %s: cannot inject into unexported field", field.Name)
+		}
+
+		k := keyOf(field.Type)
+		k.name = name
+		dep, err := resolveType(r, field.Type, k)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if dep != nil {
+			v.Field(i).Set(reflect.ValueOf(dep))
+		}
+	}
+	return nil
+}
+
+// parseInjectTag reads the registration name from an inject field tag
+func parseInjectTag(tag string) (name string, err error) {
+	opts := strings.Split(tag, ",")
+	if strings.TrimSpace(opts[0]) != "inject" {
+		return "", fmt.Errorf("unsupported tag %q", tag)
+	}
+	for _, opt := range opts[1:] {
+		k, v, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch k {
+		case "name":
+			name = v
+		default:
+			return "", fmt.Errorf("unsupported tag option %q", k)
+		}
+	}
+	return name, nil
+}
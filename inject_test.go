@@ -0,0 +1,85 @@
+package di_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type injectDB struct{ name string }
+type injectLogger struct{}
+
+func TestInjectStructFillsTaggedFields(t *testing.T) {
+	c := di.New()
+	di.RegisterIn(c, &injectDB{name: "default"})
+	di.RegisterNamedIn(c, "replica", &injectDB{name: "replica"})
+	di.RegisterIn(c, &injectLogger{})
+
+	var handler struct {
+		DB        *injectDB `di:"inject"`
+		Replica   *injectDB `di:"inject,name=replica"`
+		Log       *injectLogger
+		Untouched string
+	}
+	if err := c.InjectStruct(&handler); err != nil {
+		t.Fatal(err)
+	}
+	if handler.DB == nil || handler.DB.name != "default" {
+		t.Fatalf("DB = %+v", handler.DB)
+	}
+	if handler.Replica == nil || handler.Replica.name != "replica" {
+		t.Fatalf("Replica = %+v", handler.Replica)
+	}
+	if handler.Log != nil {
+		t.Fatal("an untagged field was injected")
+	}
+}
+
+func TestInjectStructReportsMissingDependency(t *testing.T) {
+	var handler struct {
+		DB *injectDB `di:"inject"`
+	}
+	err := di.New().InjectStruct(&handler)
+	if !errors.Is(err, di.ErrNotRegistered) {
+		t.Fatalf("InjectStruct() error = %v, want ErrNotRegistered", err)
+	}
+}
+
+func TestInjectStructRejectsUnexportedField(t *testing.T) {
+	c := di.New()
+	di.RegisterIn(c, &injectDB{})
+	var handler struct {
+		db *injectDB `di:"inject"`
+	}
+	if err := c.InjectStruct(&handler); err == nil {
+		t.Fatal("InjectStruct() filled an unexported field")
+	}
+	if handler.db != nil {
+		t.Fatal("the unexported field was set")
+	}
+}
+
+func TestInjectStructRejectsBadTagsAndTargets(t *testing.T) {
+	c := di.New()
+	di.RegisterIn(c, &injectDB{})
+
+	var unknown struct {
+		DB *injectDB `di:"provide"`
+	}
+	if err := c.InjectStruct(&unknown); err == nil {
+		t.Fatal("InjectStruct() accepted an unknown tag")
+	}
+	var option struct {
+		DB *injectDB `di:"inject,optional"`
+	}
+	if err := c.InjectStruct(&option); err == nil {
+		t.Fatal("InjectStruct() accepted an unknown tag option")
+	}
+	var value struct {
+		DB *injectDB `di:"inject"`
+	}
+	if err := c.InjectStruct(value); err == nil {
+		t.Fatal("InjectStruct() accepted a struct that is not a pointer")
+	}
+}
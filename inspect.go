@@ -0,0 +1,90 @@
+package di
+
+// Registration describes a registration of a container
+type Registration struct {
+	// Type is the name of the registered type
+	Type string
+	// Name is the registration name, if any
+	Name     string
+	Lifetime Lifetime
+	// Instance reports whether the instance was registered directly rather
+	// than built by a factory
+	Instance bool
+	// Resolved reports whether an instance is available without running a
+	// factory, which is always the case for registered instances
+	Resolved bool
+	// Used reports whether the registration was resolved since it was made
+	Used bool
+	// Module is the name of the module that made the registration, if any
+	Module string
+}
+
+// Contains reports whether T is registered in the default container
+func Contains[T any]() bool {
+	return ContainsIn[T](Default())
+}
+
+// ContainsIn reports whether T is registered in the container of r or one of
+// its parents. Nothing is built.
+func ContainsIn[T any](r Resolver) bool {
+	return r.owner().has(typeKey[T]())
+}
+
+// ContainsNamed reports whether T is registered under name in the default container
+func ContainsNamed[T any](name string) bool {
+	return ContainsNamedIn[T](Default(), name)
+}
+
+// ContainsNamedIn reports whether T is registered under name in the container
+// of r or one of its parents
+func ContainsNamedIn[T any](r Resolver, name string) bool {
+	return r.owner().has(namedKey[T](name))
+}
+
+// List returns the registrations of the default container
+func List() []Registration {
+	return Default().List()
+}
+
+// List returns the registrations made in c, in registration order.
+// Registrations inherited from a parent are not included.
+func (c *Container) List() []Registration {
+	factories := c.sortedFactories(func(*factory) bool { return true })
+	list := make([]Registration, 0, len(factories))
+	for _, f := range factories {
+		list = append(list, c.registration(f))
+	}
+	return list
+}
+
+// UnusedRegistrations returns the registrations of the default container that were never resolved
+func UnusedRegistrations() []Registration {
+	return Default().UnusedRegistrations()
+}
+
+// UnusedRegistrations returns the registrations made in c that were never
+// resolved, in registration order, to find wiring nothing depends on once
+// the application or its tests have run. Resolutions through a child
+// container or a scope count, as do the resolutions factories make of their
+// dependencies; building ahead with InitializeAll or Start, validation and
+// Graph do not.
+func (c *Container) UnusedRegistrations() []Registration {
+	var unused []Registration
+	for _, f := range c.sortedFactories(func(f *factory) bool { return !f.used.Load() }) {
+		unused = append(unused, c.registration(f))
+	}
+	return unused
+}
+
+// registration describes f
+func (c *Container) registration(f *factory) Registration {
+	return Registration{
+		Type:     f.key.typ.String(),
+		Name:     f.key.name,
+		Lifetime: f.lifetime,
+		Instance: f.external,
+		Resolved: c.state(f) == Resolved,
+		Used:     f.used.Load(),
+		Module:   f.module,
+	}
+}
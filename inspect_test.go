@@ -0,0 +1,80 @@
+package di_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type inspectDB struct{}
+type inspectRepo struct{ db *inspectDB }
+type inspectCache struct{}
+
+// newInspectContainer registers a repository, its database and an unused cache
+func newInspectContainer() *di.Container {
+	c := di.New()
+	di.RegisterFactoryIn(c, func() *inspectDB { return &inspectDB{} })
+	di.RegisterFactoryIn(c, func() *inspectRepo { return &inspectRepo{db: di.MustResolveIn[*inspectDB](c)} })
+	di.RegisterFactoryIn(c, func() *inspectCache { return &inspectCache{} })
+	return c
+}
+
+// unusedTypes returns the types reported by UnusedRegistrations
+func unusedTypes(c *di.Container) []string {
+	var types []string
+	for _, r := range c.UnusedRegistrations() {
+		types = append(types, r.Type)
+	}
+	return types
+}
+
+func TestUnusedRegistrations(t *testing.T) {
+	c := newInspectContainer()
+	if got := unusedTypes(c); len(got) != 3 {
+		t.Fatalf("UnusedRegistrations() = %v, want all three", got)
+	}
+
+	di.MustResolveIn[*inspectRepo](c)
+	if got := unusedTypes(c); len(got) != 1 || got[0] != "*di_test.inspectCache" {
+		t.Fatalf("UnusedRegistrations() = %v, want only the cache", got)
+	}
+}
+
+func TestUnusedRegistrationsIgnoresWarmUp(t *testing.T) {
+	c := newInspectContainer()
+	if err := c.InitializeAll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the repository resolved the database while it was built
+	if got := unusedTypes(c); len(got) != 2 || got[0] != "*di_test.inspectRepo" || got[1] != "*di_test.inspectCache" {
+		t.Fatalf("UnusedRegistrations() after warm-up = %v, want the repository and the cache", got)
+	}
+
+	repo := di.MustResolveIn[*inspectRepo](c)
+	if again := di.MustResolveIn[*inspectRepo](c); again != repo {
+		t.Fatal("the instance built by InitializeAll was not reused")
+	}
+	if got := unusedTypes(c); len(got) != 1 || got[0] != "*di_test.inspectCache" {
+		t.Fatalf("UnusedRegistrations() = %v, want only the cache", got)
+	}
+}
+
+func TestContainsAndList(t *testing.T) {
+	c := newInspectContainer()
+	if !di.ContainsIn[*inspectDB](c) || di.ContainsIn[string](c) {
+		t.Fatal("ContainsIn() does not match the registrations")
+	}
+	if !di.ContainsIn[*inspectDB](c.Child()) {
+		t.Fatal("ContainsIn() does not see the registrations of the parent")
+	}
+
+	list := c.List()
+	if len(list) != 3 || list[0].Type != "*di_test.inspectDB" || list[0].Resolved {
+		t.Fatalf("List() = %+v", list)
+	}
+}
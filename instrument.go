@@ -0,0 +1,69 @@
+package di
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Instrumentation observes the resolutions and factory runs of a container,
+// for example to trace them. Each Start method receives the context of the
+// enclosing operation and returns the context for nested operations along
+// with a function that is called with the outcome.
+type Instrumentation interface {
+	// StartResolve is called before a resolution
+	StartResolve(ctx context.Context, info ResolveInfo) (context.Context, func(err error))
+	// StartFactory is called before a factory runs
+	StartFactory(ctx context.Context, info ResolveInfo) (context.Context, func(err error))
+}
+
+// instruments holds the instrumentation of a container
+type instruments struct {
+	// mu serializes the updates of list
+	mu sync.Mutex
+	// list is published as an immutable slice so resolutions read it without locking
+	list atomic.Pointer[[]Instrumentation]
+}
+
+// Instrument adds instrumentation to the default container
+func Instrument(i Instrumentation) {
+	Default().Instrument(i)
+}
+
+// Instrument adds instrumentation that observes every resolution and factory run of c
+func (c *Container) Instrument(i Instrumentation) {
+	c.instruments.mu.Lock()
+	list := append(slices.Clone(c.instruments.active()), i)
+	c.instruments.list.Store(&list)
+	c.instruments.mu.Unlock()
+}
+
+// active returns the installed instrumentation
+func (in *instruments) active() []Instrumentation {
+	if list := in.list.Load(); list != nil {
+		return *list
+	}
+	return nil
+}
+
+// instrument runs op inside the operations started by start for each
+// installed instrumentation, passing it the context of the innermost one
+func (c *Container) instrument(ctx context.Context, info ResolveInfo, start func(i Instrumentation, ctx context.Context, info ResolveInfo) (context.Context, func(error)), op func(ctx context.Context) (any, error)) (any, error) {
+	list := c.instruments.active()
+	if len(list) == 0 {
+		return op(ctx)
+	}
+
+	ends := make([]func(error), len(list))
+	for i, in := range list {
+		ctx, ends[i] = start(in, ctx, info)
+	}
+
+	v, err := op(ctx)
+
+	for i := len(ends) - 1; i >= 0; i-- {
+		ends[i](err)
+	}
+	return v, err
+}
@@ -0,0 +1,48 @@
+package di
+
+// Invalidate drops the cached instance of T in the default container
+func Invalidate[T any]() {
+	InvalidateIn[T](Default())
+}
+
+// InvalidateIn drops the cached instance of T in c but keeps its
+// registration, so the next resolution builds it again. The dropped instance
+// is still torn down by Shutdown and Reset.
+func InvalidateIn[T any](c *Container) {
+	c.invalidate(typeKey[T]())
+}
+
+// InvalidateNamed drops the cached instance of T registered under name in the default container
+func InvalidateNamed[T any](name string) {
+	InvalidateNamedIn[T](Default(), name)
+}
+
+// InvalidateNamedIn drops the cached instance of T registered under name in c
+func InvalidateNamedIn[T any](c *Container, name string) {
+	c.invalidate(namedKey[T](name))
+}
+
+// invalidate drops the cached instance stored under k, following aliases
+func (c *Container) invalidate(k key) {
+	f, ok := c.factories.load(k)
+	if !ok {
+		return
+	}
+	if f.target != nil {
+		c.invalidate(*f.target)
+		return
+	}
+
+	if f.once != nil {
+		f.once.Reset()
+	}
+	if f.weak != nil {
+		f.weak.mu.Lock()
+		f.weak.typ = nil
+		f.weak.mu.Unlock()
+	}
+	if f.ttl != nil {
+		f.ttl.expire()
+	}
+	c.instances.delete(k)
+}
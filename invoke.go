@@ -0,0 +1,93 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Invoke calls fn with its parameters resolved from the default container.
+// fn may return nothing or an error as its last result, which is returned.
+func Invoke(fn any) error {
+	return Default().Invoke(fn)
+}
+
+// Invoke calls fn with its parameters resolved from c
+func (c *Container) Invoke(fn any) error {
+	return invoke(c, fn)
+}
+
+// Invoke calls fn with its parameters resolved from the scope
+func (s *Scope) Invoke(fn any) error {
+	return invoke(s, fn)
+}
+
+// invoke resolves the parameters of fn from r, calls it and returns its error result
+func invoke(r Resolver, fn any) error {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return fmt.Errorf("invoke target must be a non-nil func, got %T", fn)
+	}
+
+	out, err := call(r, v)
+	if err != nil {
+		return err
+	}
+	if n := len(out); n > 0 && v.Type().Out(n-1) == errorType && !out[n-1].IsNil() {
+		return out[n-1].Interface().(error)
+	}
+	return nil
+}
+
+// Invoke1 calls fn with its dependency resolved from the default container
+func Invoke1[D1 any](fn func(D1) error) error {
+	return Invoke1In(Default(), fn)
+}
+
+// Invoke1In calls fn with its dependency resolved from a container or scope
+func Invoke1In[D1 any](r Resolver, fn func(D1) error) error {
+	d1, err := ResolveIn[D1](r)
+	if err != nil {
+		return err
+	}
+	return fn(d1)
+}
+
+// Invoke2 calls fn with its dependencies resolved from the default container
+func Invoke2[D1, D2 any](fn func(D1, D2) error) error {
+	return Invoke2In(Default(), fn)
+}
+
+// Invoke2In calls fn with its dependencies resolved from a container or scope
+func Invoke2In[D1, D2 any](r Resolver, fn func(D1, D2) error) error {
+	d1, err := ResolveIn[D1](r)
+	if err != nil {
+		return err
+	}
+	d2, err := ResolveIn[D2](r)
+	if err != nil {
+		return err
+	}
+	return fn(d1, d2)
+}
+
+// Invoke3 calls fn with its dependencies resolved from the default container
+func Invoke3[D1, D2, D3 any](fn func(D1, D2, D3) error) error {
+	return Invoke3In(Default(), fn)
+}
+
+// Invoke3In calls fn with its dependencies resolved from a container or scope
+func Invoke3In[D1, D2, D3 any](r Resolver, fn func(D1, D2, D3) error) error {
+	d1, err := ResolveIn[D1](r)
+	if err != nil {
+		return err
+	}
+	d2, err := ResolveIn[D2](r)
+	if err != nil {
+		return err
+	}
+	d3, err := ResolveIn[D3](r)
+	if err != nil {
+		return err
+	}
+	return fn(d1, d2, d3)
+}
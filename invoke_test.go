@@ -0,0 +1,97 @@
+package di_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type invokeConfig struct{ port int }
+type invokeServer struct{ cfg *invokeConfig }
+
+func newInvokeContainer() *di.Container {
+	c := di.New()
+	di.RegisterIn(c, &invokeConfig{port: 8080})
+	di.RegisterFactory1In(c, func(cfg *invokeConfig) *invokeServer { return &invokeServer{cfg: cfg} })
+	return c
+}
+
+func TestInvokeResolvesParameters(t *testing.T) {
+	c := newInvokeContainer()
+	var got *invokeServer
+	err := c.Invoke(func(s *invokeServer, cfg *invokeConfig) {
+		if s.cfg != cfg {
+			t.Error("the server and the function received different configs")
+		}
+		got = s
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.cfg.port != 8080 {
+		t.Fatalf("invoked with %+v", got)
+	}
+}
+
+func TestInvokeReturnsFunctionError(t *testing.T) {
+	c := newInvokeContainer()
+	boom := errors.New("boom")
+	if err := c.Invoke(func(*invokeServer) error { return boom }); !errors.Is(err, boom) {
+		t.Fatalf("Invoke() error = %v, want %v", err, boom)
+	}
+	if err := c.Invoke(func(*invokeServer) error { return nil }); err != nil {
+		t.Fatalf("Invoke() error = %v", err)
+	}
+}
+
+func TestInvokeReportsMissingDependency(t *testing.T) {
+	called := false
+	err := di.New().Invoke(func(*invokeServer) { called = true })
+	if !errors.Is(err, di.ErrNotRegistered) {
+		t.Fatalf("Invoke() error = %v, want ErrNotRegistered", err)
+	}
+	if called {
+		t.Fatal("the function was called without its dependency")
+	}
+}
+
+func TestInvokeRejectsNonFunctions(t *testing.T) {
+	c := di.New()
+	if err := c.Invoke(42); err == nil {
+		t.Fatal("Invoke() accepted an int")
+	}
+	if err := c.Invoke((func())(nil)); err == nil {
+		t.Fatal("Invoke() accepted a nil func")
+	}
+}
+
+func TestGenericInvoke(t *testing.T) {
+	c := newInvokeContainer()
+	if err := di.Invoke2In(c, func(s *invokeServer, cfg *invokeConfig) error {
+		if s.cfg != cfg {
+			return errors.New("different configs")
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := di.Invoke1In(di.New(), func(*invokeConfig) error { return nil }); !errors.Is(err, di.ErrNotRegistered) {
+		t.Fatalf("Invoke1In() error = %v, want ErrNotRegistered", err)
+	}
+}
+
+func TestScopeInvokeResolvesScoped(t *testing.T) {
+	c := di.New()
+	di.RegisterScopedIn(c, func(*di.Scope) *invokeConfig { return &invokeConfig{} })
+	s := c.NewScope()
+	defer s.Close()
+
+	if err := s.Invoke(func(cfg *invokeConfig) {
+		if cfg != di.MustResolveIn[*invokeConfig](s) {
+			t.Error("the function received another scoped instance")
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
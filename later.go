@@ -0,0 +1,69 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+)
+
+// laterRegistration is a registration whose instance is decided by Build
+type laterRegistration struct {
+	k   key
+	run func(c *Container) (any, error)
+	// opts are applied when the instance is registered
+	opts []RegisterOption
+}
+
+// RegisterLater defers the registration of T in the default container to Build
+func RegisterLater[T any](f func(c *Container) (T, error), opts ...RegisterOption) {
+	RegisterLaterIn(Default(), f, opts...)
+}
+
+// RegisterLaterIn defers the registration of T in c to Build, which calls f
+// with the finished registry and registers its result as the instance of T.
+// f can check what else is registered to choose an implementation, for
+// example a clustered one when a cluster client is registered.
+func RegisterLaterIn[T any](c *Container, f func(c *Container) (T, error), opts ...RegisterOption) {
+	c.laterMu.Lock()
+	defer c.laterMu.Unlock()
+	c.later = append(c.later, laterRegistration{
+		k: typeKey[T](),
+		run: func(c *Container) (any, error) {
+			return f(c)
+		},
+		opts: opts,
+	})
+}
+
+// Build completes the wiring of the default container
+func Build() error {
+	return Default().Build()
+}
+
+// Build runs the registrations deferred with RegisterLater, in the order they
+// were made, and then validates c as Validate does. Each deferred function
+// sees the registrations made before it, including the results of earlier
+// deferred ones; one that fails registers nothing. Errors are joined. Start
+// runs pending deferred registrations too, without validating.
+func (c *Container) Build() error {
+	return errors.Join(c.runLater(), c.Validate())
+}
+
+// runLater runs and registers the pending deferred registrations
+func (c *Container) runLater() error {
+	c.laterMu.Lock()
+	pending := c.later
+	c.later = nil
+	c.laterMu.Unlock()
+
+	var errs []error
+	for _, l := range pending {
+		v, err := l.run(c)
+		if err == nil {
+			err = c.addInstance(l.k, v, l.opts)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("deferred registration of %v: %w", l.k, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,106 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type laterStore interface{ Kind() string }
+
+type laterSingleNode struct{}
+
+func (laterSingleNode) Kind() string { return "single-node" }
+
+type laterClustered struct{}
+
+func (laterClustered) Kind() string { return "clustered" }
+
+type laterClusterClient struct{}
+
+func registerStore(c *di.Container) {
+	di.RegisterLaterIn(c, func(c *di.Container) (laterStore, error) {
+		if di.ContainsIn[*laterClusterClient](c) {
+			return laterClustered{}, nil
+		}
+		return laterSingleNode{}, nil
+	})
+}
+
+func TestRegisterLaterSeesTheFinishedRegistry(t *testing.T) {
+	c := di.New()
+	registerStore(c)
+	// registered after the deferred registration, but before Build
+	di.RegisterIn(c, &laterClusterClient{})
+
+	if di.ContainsIn[laterStore](c) {
+		t.Fatal("a deferred registration was made before Build")
+	}
+	if err := c.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if got := di.MustResolveIn[laterStore](c).Kind(); got != "clustered" {
+		t.Fatalf("built %s, want clustered", got)
+	}
+
+	single := di.New()
+	registerStore(single)
+	if err := single.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if got := di.MustResolveIn[laterStore](single).Kind(); got != "single-node" {
+		t.Fatalf("built %s, want single-node", got)
+	}
+}
+
+func TestRegisterLaterRunsInOrderOnce(t *testing.T) {
+	c := di.New()
+	runs := 0
+	di.RegisterLaterIn(c, func(*di.Container) (*laterClusterClient, error) {
+		runs++
+		return &laterClusterClient{}, nil
+	})
+	registerStore(c)
+
+	if err := c.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if runs != 1 {
+		t.Fatalf("deferred function ran %d times", runs)
+	}
+	if got := di.MustResolveIn[laterStore](c).Kind(); got != "clustered" {
+		t.Fatalf("an earlier deferred registration was not visible: built %s", got)
+	}
+}
+
+func TestBuildJoinsDeferredErrors(t *testing.T) {
+	c := di.New()
+	boom := errors.New("boom")
+	di.RegisterLaterIn(c, func(*di.Container) (laterStore, error) { return nil, boom })
+	di.RegisterLaterIn(c, func(*di.Container) (*laterClusterClient, error) { return &laterClusterClient{}, nil })
+
+	err := c.Build()
+	if !errors.Is(err, boom) || !strings.Contains(err.Error(), "laterStore") {
+		t.Fatalf("Build returned %v, want the failure naming laterStore", err)
+	}
+	if di.ContainsIn[laterStore](c) || !di.ContainsIn[*laterClusterClient](c) {
+		t.Fatal("a failed deferred registration was registered or stopped the others")
+	}
+}
+
+func TestStartRunsDeferredRegistrations(t *testing.T) {
+	c := di.New()
+	registerStore(c)
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !di.ContainsIn[laterStore](c) {
+		t.Fatal("Start did not run the deferred registration")
+	}
+}
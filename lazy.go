@@ -0,0 +1,41 @@
+package di
+
+// Lazy is a dependency on T that is resolved on the first call to Value
+// rather than when the dependent is built. It breaks initialization-order
+// problems and keeps expensive dependencies from being built unless used.
+type Lazy[T any] struct {
+	value *OnceValue[T]
+}
+
+// NewLazy returns a Lazy that resolves T from a container or scope on first use
+func NewLazy[T any](r Resolver) Lazy[T] {
+	// the resolution outlives the one that built the dependent, and its context
+	r = unbind(r)
+	return Lazy[T]{value: Once(func() (T, error) {
+		return ResolveIn[T](r)
+	})}
+}
+
+// Value resolves T on the first call and returns the same instance afterwards.
+// A failed resolution is returned and retried on the next call. The zero Lazy
+// resolves from the default container.
+func (l Lazy[T]) Value() (T, error) {
+	if l.value == nil {
+		return Resolve[T]()
+	}
+	return l.value.Get()
+}
+
+// MustValue is like Value but panics if T cannot be resolved
+func (l Lazy[T]) MustValue() T {
+	v, err := l.Value()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// build returns a Lazy bound to r
+func (Lazy[T]) build(r Resolver) (any, error) {
+	return NewLazy[T](r), nil
+}
@@ -0,0 +1,356 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// builtInstance is a singleton recorded when its construction finished
+type builtInstance struct {
+	factory  *factory
+	instance any
+	// cleanup is the cleanup function returned by the factory, if any
+	cleanup func()
+	// state is the lifecycle state of the instance
+	state atomic.Int32
+}
+
+// Lifecycle states of a built instance. An instance without start hooks is
+// running as soon as it is built.
+const (
+	// instanceBuilt is the state of an instance whose start hooks have not run
+	instanceBuilt int32 = iota
+	// instanceStarting is the state of an instance whose start hooks are running
+	instanceStarting
+	// instanceStarted is the state of an instance whose start hooks all succeeded
+	instanceStarted
+	// instanceStopped is the state of an instance whose stop hooks ran
+	instanceStopped
+)
+
+// stop reports whether the stop hooks of b are due and marks them as run
+func (b *builtInstance) stop() bool {
+	if len(b.factory.onStart) > 0 {
+		return b.state.CompareAndSwap(instanceStarted, instanceStopped)
+	}
+	return b.state.CompareAndSwap(instanceBuilt, instanceStopped)
+}
+
+// OnStart attaches a hook that Start runs with the instance of the registration
+func OnStart[T any](hook func(ctx context.Context, instance T) error) RegisterOption {
+	return func(f *factory) {
+		f.onStart = append(f.onStart, func(ctx context.Context, v any) error {
+			instance, _ := v.(T)
+			return hook(ctx, instance)
+		})
+	}
+}
+
+// OnStop attaches a hook that Stop runs with the instance of the registration
+func OnStop[T any](hook func(ctx context.Context, instance T) error) RegisterOption {
+	return func(f *factory) {
+		f.onStop = append(f.onStop, func(ctx context.Context, v any) error {
+			instance, _ := v.(T)
+			return hook(ctx, instance)
+		})
+	}
+}
+
+// recordBuilt appends a freshly built singleton to the construction order
+func (c *Container) recordBuilt(f *factory, v any, cleanup func()) {
+	c.builtMu.Lock()
+	c.built = append(c.built, &builtInstance{factory: f, instance: v, cleanup: cleanup})
+	c.builtMu.Unlock()
+}
+
+// Start runs the start hooks of the default container
+func Start(ctx context.Context) error {
+	return Default().Start(ctx)
+}
+
+// Start builds every registration that has start hooks and runs the hooks
+// in dependency order: an instance starts after everything it was built from.
+// Registrations are built in the topological order of their declared
+// dependencies; factories registered with RegisterFactoryCtx receive ctx. If
+// a declared dependency, direct or not, is not registered, Start fails naming
+// it before anything is built. If a hook fails, the instances started so far
+// are stopped and the error is returned. Registrations deferred with
+// RegisterLater and not yet built run first.
+func (c *Container) Start(ctx context.Context) error {
+	if err := c.runLater(); err != nil {
+		return err
+	}
+	pending, err := c.startOrder()
+	if err != nil {
+		return err
+	}
+	for _, f := range pending {
+		if err := c.warm(ctx, f); err != nil {
+			return err
+		}
+	}
+
+	for _, b := range c.builtInstances() {
+		if len(b.factory.onStart) == 0 {
+			// stopped instances without start hooks run again
+			b.state.CompareAndSwap(instanceStopped, instanceBuilt)
+			continue
+		}
+		if !b.state.CompareAndSwap(instanceBuilt, instanceStarting) && !b.state.CompareAndSwap(instanceStopped, instanceStarting) {
+			continue
+		}
+		for _, hook := range b.factory.onStart {
+			if err := hook(ctx, b.instance); err != nil {
+				// the instance did not start, so its stop hooks must not run
+				b.state.Store(instanceStopped)
+				return errors.Join(err, c.Stop(ctx))
+			}
+		}
+		b.state.Store(instanceStarted)
+	}
+	return nil
+}
+
+// startOrder returns the registrations with start hooks, each after the ones
+// it declares a dependency on, or the missing declared dependencies
+func (c *Container) startOrder() ([]*factory, error) {
+	var order []*factory
+	var errs []error
+	visited := make(map[key]bool)
+	var path []string
+	var visit func(f *factory)
+	visit = func(f *factory) {
+		if visited[f.key] {
+			return
+		}
+		visited[f.key] = true
+		path = append(path, f.key.String())
+		for _, dep := range f.deps {
+			d, ok := c.lookup(dep)
+			switch {
+			case ok:
+				visit(d)
+			case !isBuiltin(dep):
+				errs = append(errs, fmt.Errorf("%s: missing dependency: %w", strings.Join(path, " -> "), c.newResolveError(dep)))
+			}
+		}
+		path = path[:len(path)-1]
+		if len(f.onStart) > 0 {
+			order = append(order, f)
+		}
+	}
+
+	for _, f := range c.sortedFactories(func(f *factory) bool { return len(f.onStart) > 0 }) {
+		visit(f)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return order, nil
+}
+
+// builtInstances returns a snapshot of the singletons in construction order
+func (c *Container) builtInstances() []*builtInstance {
+	c.builtMu.Lock()
+	defer c.builtMu.Unlock()
+	return append([]*builtInstance(nil), c.built...)
+}
+
+// Stop runs the stop hooks of the default container
+func Stop(ctx context.Context) error {
+	return Default().Stop(ctx)
+}
+
+// Stop runs the stop hooks of built instances in reverse construction order,
+// skipping instances whose start hooks have not all succeeded. The hooks of
+// an instance run once until the next Start, so Stop followed by Shutdown
+// does not repeat them. All hooks run even if some fail; their errors are
+// joined. If ctx has a deadline, each hook gets
+// an equal share of the time left, as described for Shutdown.
+func (c *Container) Stop(ctx context.Context) error {
+	return errors.Join(c.runStopSteps(ctx, c.stopSteps(c.builtInstances()))...)
+}
+
+// Shutdown stops and closes the default container
+func Shutdown(ctx context.Context) error {
+	return Default().Shutdown(ctx)
+}
+
+// ContextCloser is implemented by instances whose teardown honours a
+// context. Shutdown calls it instead of io.Closer.
+type ContextCloser interface {
+	Close(ctx context.Context) error
+}
+
+// Shutdown runs the stop hooks, then tears down every singleton built by a
+// factory in reverse construction order and forgets it, so the next
+// resolution builds a fresh instance. Teardown calls the cleanup function
+// returned by the factory or, if there is none, Close for instances that
+// implement ContextCloser or io.Closer. Instances registered directly are
+// not closed.
+//
+// Steps run one at a time, in order. If ctx has a deadline, every stop hook
+// and teardown gets a context ending with an equal share of the time left
+// when it starts, and at least minStopShare, so time a step does not use goes
+// to the following ones. A step that overruns its share is logged and
+// reported, but it is waited for: a step that ignores its context delays the
+// ones after it and Shutdown itself. Instances are forgotten only once every
+// step has finished, and the instances of WithTTL registrations are
+// discarded with their pending refreshes. Errors, including overruns, are
+// joined.
+func (c *Container) Shutdown(ctx context.Context) error {
+	steps := c.stopSteps(c.builtInstances())
+	built := c.takeBuilt()
+	for _, b := range built {
+		steps = append(steps, stopStep{key: b.factory.key, kind: "close", run: b.teardown})
+	}
+	errs := c.runStopSteps(ctx, steps)
+	c.stopRefreshes()
+
+	for _, b := range built {
+		b.factory.once.Reset()
+		if current, ok := c.factories.load(b.factory.key); ok && current == b.factory {
+			c.instances.delete(b.factory.key)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stopStep is a stop hook or teardown run by Stop or Shutdown
+type stopStep struct {
+	key key
+	// kind names the step in logs and errors
+	kind string
+	run  func(ctx context.Context) error
+}
+
+// stopSteps returns the stop hooks of built, an ascending construction
+// order, in reverse, skipping instances whose start hooks have not all
+// succeeded and instances already stopped
+func (c *Container) stopSteps(built []*builtInstance) []stopStep {
+	var steps []stopStep
+	for i := len(built) - 1; i >= 0; i-- {
+		b := built[i]
+		if len(b.factory.onStop) == 0 || !b.stop() {
+			continue
+		}
+		for _, hook := range b.factory.onStop {
+			steps = append(steps, stopStep{key: b.factory.key, kind: "stop hook", run: func(ctx context.Context) error {
+				return hook(ctx, b.instance)
+			}})
+		}
+	}
+	return steps
+}
+
+// minStopShare is the least time a stop step gets once a deadline is near or past
+const minStopShare = 10 * time.Millisecond
+
+// runStopSteps runs steps one after the other and returns their errors.
+// Without a deadline on ctx, each step gets ctx; with one, each step gets a
+// context ending with an equal share of the time left, at least
+// minStopShare, and overrunning it is reported as an error.
+func (c *Container) runStopSteps(ctx context.Context, steps []stopStep) []error {
+	var errs []error
+	deadline, bounded := ctx.Deadline()
+	for i, step := range steps {
+		if !bounded {
+			if err := step.run(ctx); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		// the share replaces the deadline of ctx, which may already have
+		// passed, but cancelling ctx still cancels the step
+		share := max(time.Until(deadline)/time.Duration(len(steps)-i), minStopShare)
+		stepCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), share)
+		stopCancel := context.AfterFunc(ctx, func() {
+			if errors.Is(ctx.Err(), context.Canceled) {
+				cancel()
+			}
+		})
+		overran := context.AfterFunc(stepCtx, func() {
+			c.logSlowStop(step, share)
+		})
+
+		err := step.run(stepCtx)
+		if !overran() {
+			// the share ran out or ctx was cancelled before the step returned
+			err = errors.Join(err, fmt.Errorf("%s for %v did not finish within %s: %w", step.kind, step.key, share, stepCtx.Err()))
+		}
+		stopCancel()
+		cancel()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// logSlowStop logs that step overran its share of the shutdown time
+func (c *Container) logSlowStop(step stopStep, share time.Duration) {
+	if l := c.logger.Load(); l != nil {
+		l.Warn("slow "+step.kind, "key", step.key.String(), "share", share)
+	} else {
+		log.Printf("di: %s for %s did not finish within %s", step.kind, step.key, share)
+	}
+}
+
+// takeBuilt empties the construction order and returns it reversed
+func (c *Container) takeBuilt() []*builtInstance {
+	c.builtMu.Lock()
+	built := c.built
+	c.built = nil
+	c.builtMu.Unlock()
+
+	slices.Reverse(built)
+	return built
+}
+
+// takeBuiltOf removes the singletons built from registrations accepted by
+// keep from the construction order and returns them in construction order
+func (c *Container) takeBuiltOf(keep func(f *factory) bool) []*builtInstance {
+	c.builtMu.Lock()
+	defer c.builtMu.Unlock()
+
+	var taken []*builtInstance
+	c.built = slices.DeleteFunc(c.built, func(b *builtInstance) bool {
+		if keep(b.factory) {
+			taken = append(taken, b)
+			return true
+		}
+		return false
+	})
+	return taken
+}
+
+// teardown runs the cleanup function of the instance or closes it with ctx
+func (b *builtInstance) teardown(ctx context.Context) error {
+	if b.cleanup != nil {
+		b.cleanup()
+		return nil
+	}
+	if b.factory.external {
+		return nil
+	}
+
+	var err error
+	switch closer := b.instance.(type) {
+	case ContextCloser:
+		err = closer.Close(ctx)
+	case io.Closer:
+		err = closer.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("close %v: %w", b.factory.key, err)
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ryanbekhen/di"
+)
+
+type lifeDB struct{ closed atomic.Bool }
+type lifeRepo struct{ db *lifeDB }
+type lifeServer struct{ repo *lifeRepo }
+
+// Close records that the instance was closed
+func (d *lifeDB) Close() error {
+	d.closed.Store(true)
+	return nil
+}
+
+// lifeLog records lifecycle events in order
+type lifeLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *lifeLog) add(event string) {
+	l.mu.Lock()
+	l.events = append(l.events, event)
+	l.mu.Unlock()
+}
+
+func (l *lifeLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.events...)
+}
+
+func TestShutdownRunsStepsOneAtATimeInReverseOrder(t *testing.T) {
+	c := di.New(di.WithLogger(slog.New(slog.DiscardHandler)))
+	var running, overlap atomic.Int32
+	log := &lifeLog{}
+	slowHook := func(name string) func(context.Context, any) error {
+		return func(ctx context.Context, _ any) error {
+			if running.Add(1) > 1 {
+				overlap.Store(1)
+			}
+			defer running.Add(-1)
+			// ignores its context and overruns its share
+			time.Sleep(30 * time.Millisecond)
+			log.add(name)
+			return nil
+		}
+	}
+	di.RegisterFactoryIn(c, func() *lifeDB { return &lifeDB{} }, di.OnStop(func(ctx context.Context, v *lifeDB) error {
+		return slowHook("db")(ctx, v)
+	}))
+	di.RegisterFactoryIn(c, func() *lifeRepo { return &lifeRepo{db: di.MustResolveIn[*lifeDB](c)} }, di.OnStop(func(ctx context.Context, v *lifeRepo) error {
+		return slowHook("repo")(ctx, v)
+	}))
+	di.RegisterFactoryIn(c, func() *lifeServer { return &lifeServer{repo: di.MustResolveIn[*lifeRepo](c)} }, di.OnStop(func(ctx context.Context, v *lifeServer) error {
+		return slowHook("server")(ctx, v)
+	}))
+	db := di.MustResolveIn[*lifeDB](c)
+	di.MustResolveIn[*lifeServer](c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := c.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() error = %v, want the overruns reported", err)
+	}
+
+	if overlap.Load() != 0 {
+		t.Fatal("stop steps ran concurrently")
+	}
+	got := log.get()
+	want := []string{"server", "repo", "db"}
+	if len(got) != len(want) {
+		t.Fatalf("stop hooks ran %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("stop hooks ran %v, want %v", got, want)
+		}
+	}
+	if !db.closed.Load() {
+		t.Fatal("Shutdown returned before closing the instance")
+	}
+	if again := di.MustResolveIn[*lifeDB](c); again == db {
+		t.Fatal("Shutdown did not forget the closed instance")
+	}
+}
+
+func TestShutdownGivesEachStepAPositiveShare(t *testing.T) {
+	c := di.New()
+	var shares []time.Duration
+	di.RegisterFactoryIn(c, func() *lifeRepo { return &lifeRepo{} }, di.OnStop(func(ctx context.Context, _ *lifeRepo) error {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			t.Error("stop hook context has no deadline")
+		}
+		shares = append(shares, time.Until(deadline))
+		return nil
+	}))
+	di.MustResolveIn[*lifeRepo](c)
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	_ = c.Shutdown(ctx)
+
+	if len(shares) != 1 || shares[0] <= 0 {
+		t.Fatalf("stop hook shares = %v, want one positive share", shares)
+	}
+}
+
+type lifeCtxCloser struct{ deadline bool }
+
+// Close records whether ctx carried the step deadline
+func (l *lifeCtxCloser) Close(ctx context.Context) error {
+	_, l.deadline = ctx.Deadline()
+	return nil
+}
+
+func TestShutdownPassesContextToClose(t *testing.T) {
+	c := di.New()
+	di.RegisterFactoryIn(c, func() *lifeCtxCloser { return &lifeCtxCloser{} })
+	closer := di.MustResolveIn[*lifeCtxCloser](c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := c.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !closer.deadline {
+		t.Fatal("Close did not receive the step context")
+	}
+}
+
+type lifeA struct{}
+type lifeB struct{}
+type lifeC struct{}
+
+func TestStartRollsBackStartedInstancesOnly(t *testing.T) {
+	c := di.New()
+	log := &lifeLog{}
+	di.RegisterFactoryIn(c, func() *lifeA { return &lifeA{} },
+		di.OnStart(func(context.Context, *lifeA) error { log.add("start a"); return nil }),
+		di.OnStop(func(context.Context, *lifeA) error { log.add("stop a"); return nil }))
+	di.RegisterFactoryIn(c, func() *lifeB { return &lifeB{} },
+		di.OnStart(func(context.Context, *lifeB) error { return errors.New("boom") }),
+		di.OnStop(func(context.Context, *lifeB) error { log.add("stop b"); return nil }))
+
+	if err := c.Start(context.Background()); err == nil {
+		t.Fatal("Start() succeeded with a failing hook")
+	}
+
+	got := log.get()
+	if len(got) != 2 || got[0] != "start a" || got[1] != "stop a" {
+		t.Fatalf("lifecycle events = %v, want [start a stop a]", got)
+	}
+}
+
+func TestStopThenShutdownRunsStopHooksOnce(t *testing.T) {
+	c := di.New()
+	var stops atomic.Int32
+	di.RegisterFactoryIn(c, func() *lifeC { return &lifeC{} },
+		di.OnStop(func(context.Context, *lifeC) error { stops.Add(1); return nil }))
+	di.MustResolveIn[*lifeC](c)
+
+	if err := c.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := stops.Load(); n != 1 {
+		t.Fatalf("stop hook ran %d times, want 1", n)
+	}
+}
+
+func TestStartStopRestart(t *testing.T) {
+	c := di.New()
+	log := &lifeLog{}
+	di.RegisterFactoryIn(c, func() *lifeA { return &lifeA{} },
+		di.OnStart(func(context.Context, *lifeA) error { log.add("start a"); return nil }),
+		di.OnStop(func(context.Context, *lifeA) error { log.add("stop a"); return nil }))
+	di.RegisterFactoryIn(c, func() *lifeC { return &lifeC{} },
+		di.OnStop(func(context.Context, *lifeC) error { log.add("stop c"); return nil }))
+	di.MustResolveIn[*lifeC](c)
+
+	ctx := context.Background()
+	for range 2 {
+		if err := c.Start(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Stop(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"start a", "stop a", "stop c", "start a", "stop a", "stop c"}
+	got := log.get()
+	if len(got) != len(want) {
+		t.Fatalf("lifecycle events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("lifecycle events = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestStartFollowsDependencyOrder(t *testing.T) {
+	c := di.New()
+	log := &lifeLog{}
+	// registered in reverse so the order comes from the dependencies
+	err := c.RegisterConstructor(func(r *lifeRepo) *lifeServer { return &lifeServer{repo: r} },
+		di.OnStart(func(context.Context, *lifeServer) error { log.add("start server"); return nil }),
+		di.OnStop(func(context.Context, *lifeServer) error { log.add("stop server"); return nil }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.RegisterConstructor(func(db *lifeDB) *lifeRepo { return &lifeRepo{db: db} },
+		di.OnStart(func(context.Context, *lifeRepo) error { log.add("start repo"); return nil }),
+		di.OnStop(func(context.Context, *lifeRepo) error { log.add("stop repo"); return nil }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	di.RegisterFactoryIn(c, func() *lifeDB { return &lifeDB{} },
+		di.OnStart(func(context.Context, *lifeDB) error { log.add("start db"); return nil }),
+		di.OnStop(func(context.Context, *lifeDB) error { log.add("stop db"); return nil }))
+
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"start db", "start repo", "start server", "stop server", "stop repo", "stop db"}
+	got := log.get()
+	if len(got) != len(want) {
+		t.Fatalf("lifecycle events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("lifecycle events = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package di
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+)
+
+// loggerKey is the key of an unnamed *slog.Logger registration
+var loggerKey = keyOf(reflect.TypeFor[*slog.Logger]())
+
+// LogLevels sets the level at which each kind of container event is logged
+type LogLevels struct {
+	// Register is the level of new registrations
+	Register slog.Level
+	// Overwrite is the level of registrations that replace another one
+	Overwrite slog.Level
+	// Resolve is the level of successful resolutions
+	Resolve slog.Level
+	// ResolveError is the level of failed resolutions
+	ResolveError slog.Level
+	// Panic is the level of factories that panic
+	Panic slog.Level
+	// Reset is the level of container resets
+	Reset slog.Level
+}
+
+// DefaultLogLevels are the levels used unless WithLogLevels says otherwise
+var DefaultLogLevels = LogLevels{
+	Register:     slog.LevelDebug,
+	Overwrite:    slog.LevelWarn,
+	Resolve:      slog.LevelDebug,
+	ResolveError: slog.LevelWarn,
+	Panic:        slog.LevelError,
+	Reset:        slog.LevelInfo,
+}
+
+// logger is a slog.Logger with the levels of container events
+type logger struct {
+	*slog.Logger
+	levels LogLevels
+}
+
+// WithLogger makes the container log its events to l
+func WithLogger(l *slog.Logger) Option {
+	return func(c *Container) {
+		c.SetLogger(l)
+	}
+}
+
+// WithLogLevels sets the levels of the events logged by WithLogger, before or
+// after it, and by loggers set later with SetLogger
+func WithLogLevels(levels LogLevels) Option {
+	return func(c *Container) {
+		c.logLevels.Store(&levels)
+		if l := c.logger.Load(); l != nil {
+			c.logger.Store(&logger{Logger: l.Logger, levels: levels})
+		}
+	}
+}
+
+// SetLogger makes the default container log its events to l
+func SetLogger(l *slog.Logger) {
+	Default().SetLogger(l)
+}
+
+// SetLogger makes c log registrations, overwrites, resolutions, factory
+// panics and resets to l with the levels of WithLogLevels, or
+// DefaultLogLevels. Registrations record the location of the code that made
+// them. A nil l stops logging.
+func (c *Container) SetLogger(l *slog.Logger) {
+	if l == nil {
+		c.logger.Store(nil)
+		return
+	}
+	levels := DefaultLogLevels
+	if custom := c.logLevels.Load(); custom != nil {
+		levels = *custom
+	}
+	c.logger.Store(&logger{Logger: l, levels: levels})
+}
+
+// Logger returns the logger of the default container
+func Logger() *slog.Logger {
+	return Default().Logger()
+}
+
+// Logger returns the logger set with SetLogger or WithLogger, or nil if c
+// does not log
+func (c *Container) Logger() *slog.Logger {
+	if l := c.logger.Load(); l != nil {
+		return l.Logger
+	}
+	return nil
+}
+
+// logRegistration logs that f was stored, replacing another registration if replaced
+func (c *Container) logRegistration(f *factory, replaced bool) {
+	l := c.logger.Load()
+	if l == nil {
+		return
+	}
+
+	msg, level := "registered", l.levels.Register
+	if replaced {
+		msg, level = "registration replaced", l.levels.Overwrite
+	}
+	if !l.Enabled(context.Background(), level) {
+		return
+	}
+
+	attrs := []any{"key", f.key.String(), "lifetime", f.lifetime.String()}
+	if f.module != "" {
+		attrs = append(attrs, "module", f.module)
+	}
+	if site := f.site.String(); site != "" {
+		attrs = append(attrs, "caller", site)
+	}
+	l.Log(context.Background(), level, msg, attrs...)
+}
+
+// resolved logs the outcome of a resolution
+func (l *logger) resolved(k key, info ResolveInfo) {
+	if info.Err != nil {
+		l.Log(context.Background(), l.levels.ResolveError, "resolve failed", "key", k.String(), "error", info.Err)
+		return
+	}
+	l.Log(context.Background(), l.levels.Resolve, "resolved", "key", k.String(), "duration", info.Duration)
+}
+
+// logPanic logs that the factory for k panicked with p
+func (c *Container) logPanic(k key, p any) {
+	if l := c.logger.Load(); l != nil {
+		l.Log(context.Background(), l.levels.Panic, "factory panicked", "key", k.String(), "panic", p)
+	}
+}
+
+// logReset logs that c is being reset
+func (c *Container) logReset() {
+	if l := c.logger.Load(); l != nil {
+		l.Log(context.Background(), l.levels.Reset, "container reset")
+	}
+}
+
+// DeriveComponentLoggers makes the default container derive component loggers
+func DeriveComponentLoggers(attr string) {
+	Default().DeriveComponentLoggers(attr)
+}
+
+// DeriveComponentLoggers makes every factory in c that resolves the unnamed
+// *slog.Logger receive a child logger with attr set to the name of the type
+// it builds, such as logger.With("component", "UserService"). Resolutions
+// made outside a factory receive the registered logger. An empty attr turns
+// it off.
+func (c *Container) DeriveComponentLoggers(attr string) {
+	if attr == "" {
+		c.componentLoggers.Store(nil)
+		return
+	}
+	c.componentLoggers.Store(&attr)
+}
+
+// componentLogger derives a child of the logger v named after the type being
+// built on the calling goroutine, if any
+func (c *Container) componentLogger(attr string, v any) any {
+	l, ok := v.(*slog.Logger)
+	if !ok || l == nil {
+		return v
+	}
+	from, ok := c.tracker.requester()
+	if !ok {
+		return v
+	}
+	t := from.typ
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	name := t.Name()
+	if name == "" {
+		name = t.String()
+	}
+	return l.With(attr, name)
+}
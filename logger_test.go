@@ -0,0 +1,61 @@
+package di_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type loggedService struct{ log *slog.Logger }
+
+func TestDeriveComponentLoggers(t *testing.T) {
+	var buf bytes.Buffer
+	base := slog.New(slog.NewTextHandler(&buf, nil))
+
+	c := di.New()
+	di.RegisterIn(c, base)
+	c.DeriveComponentLoggers("component")
+	if err := c.RegisterConstructor(func(l *slog.Logger) *loggedService { return &loggedService{log: l} }); err != nil {
+		t.Fatal(err)
+	}
+
+	di.MustResolveIn[*loggedService](c).log.Info("hello")
+	if !strings.Contains(buf.String(), "component=loggedService") {
+		t.Fatalf("log output %q has no component", buf.String())
+	}
+	if l := di.MustResolveIn[*slog.Logger](c); l != base {
+		t.Fatal("a resolution outside a factory received a derived logger")
+	}
+
+	c.DeriveComponentLoggers("")
+	di.InvalidateIn[*loggedService](c)
+	if l := di.MustResolveIn[*loggedService](c).log; l != base {
+		t.Fatal("DeriveComponentLoggers(\"\") did not turn derivation off")
+	}
+}
+
+func TestWithLogLevelsInAnyOrder(t *testing.T) {
+	levels := di.DefaultLogLevels
+	levels.Register = slog.LevelInfo
+
+	for name, opts := range map[string]func(l *slog.Logger) []di.Option{
+		"after WithLogger": func(l *slog.Logger) []di.Option {
+			return []di.Option{di.WithLogger(l), di.WithLogLevels(levels)}
+		},
+		"before WithLogger": func(l *slog.Logger) []di.Option {
+			return []di.Option{di.WithLogLevels(levels), di.WithLogger(l)}
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			c := di.New(opts(slog.New(slog.NewTextHandler(&buf, nil)))...)
+			di.RegisterIn(c, &loggedService{})
+			if !strings.Contains(buf.String(), "level=INFO msg=registered") {
+				t.Fatalf("log output %q has no registration at INFO", buf.String())
+			}
+		})
+	}
+}
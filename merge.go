@@ -0,0 +1,88 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ConflictPolicy decides what Merge does with a key registered in both containers
+type ConflictPolicy int
+
+const (
+	// ConflictError makes Merge fail without importing anything
+	ConflictError ConflictPolicy = iota
+	// ConflictSkip keeps the existing registration
+	ConflictSkip
+	// ConflictOverwrite replaces the existing registration with the imported one
+	ConflictOverwrite
+)
+
+// MergeOption configures Merge
+type MergeOption func(o *mergeOptions)
+
+// mergeOptions holds the settings of a Merge call
+type mergeOptions struct {
+	conflict ConflictPolicy
+}
+
+// OnConflict sets how Merge handles keys registered in both containers.
+// The default is ConflictError.
+func OnConflict(policy ConflictPolicy) MergeOption {
+	return func(o *mergeOptions) {
+		o.conflict = policy
+	}
+}
+
+// Merge imports the registrations of other into c. Imported factories build
+// their own instances in c and resolve their dependencies from c. Multiple
+// bindings added with RegisterMulti or groups never conflict; they are
+// appended after the existing ones. other is left unchanged.
+func (c *Container) Merge(other *Container, opts ...MergeOption) error {
+	o := mergeOptions{conflict: ConflictError}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	imported := other.sortedFactories(func(*factory) bool { return true })
+	if o.conflict == ConflictError {
+		var errs []error
+		for _, f := range imported {
+			if _, ok := c.factories.load(f.key); ok && f.key.id == 0 {
+				errs = append(errs, fmt.Errorf("%w: %v", ErrConflict, f.key))
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+	}
+
+	// multiple bindings are renumbered so they do not clash with c's own
+	renamed := make(map[key]key)
+	for _, f := range imported {
+		if f.key.id != 0 {
+			renamed[f.key] = c.multiKey(f.key)
+		}
+	}
+	rename := func(k key) key {
+		if to, ok := renamed[k]; ok {
+			return to
+		}
+		return k
+	}
+
+	for _, f := range imported {
+		k := rename(f.key)
+		if _, ok := c.factories.load(k); ok && o.conflict == ConflictSkip {
+			continue
+		}
+
+		target := f.target
+		if target != nil {
+			to := rename(*target)
+			target = &to
+		}
+		c.adopt(f, k, target)
+	}
+	c.notifyRegistered()
+	return nil
+}
@@ -0,0 +1,84 @@
+package di_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type mergeDB struct{ owner string }
+type mergeRepo struct{ db *mergeDB }
+type mergePlugin struct{ name string }
+
+// newTeamContainer returns a container with the wiring a team ships
+func newTeamContainer(owner string) *di.Container {
+	c := di.New()
+	di.RegisterFactoryIn(c, func() *mergeDB { return &mergeDB{owner: owner} })
+	di.RegisterMultiIn(c, &mergePlugin{name: owner})
+	return c
+}
+
+func TestMergeImportsRegistrations(t *testing.T) {
+	c := di.New()
+	di.RegisterFactory1In(c, func(db *mergeDB) *mergeRepo { return &mergeRepo{db: db} })
+	di.RegisterMultiIn(c, &mergePlugin{name: "app"})
+
+	team := newTeamContainer("team")
+	if err := c.Merge(team); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := di.MustResolveIn[*mergeRepo](c)
+	if repo.db.owner != "team" {
+		t.Fatalf("repo built with %+v", repo.db)
+	}
+	if repo.db == di.MustResolveIn[*mergeDB](team) {
+		t.Fatal("the merged registration shares its instance with the original container")
+	}
+	plugins, err := di.ResolveAllIn[*mergePlugin](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plugins) != 2 || plugins[0].name != "app" || plugins[1].name != "team" {
+		t.Fatalf("ResolveAllIn() = %+v, want app then team", plugins)
+	}
+}
+
+func TestMergeConflict(t *testing.T) {
+	c := newTeamContainer("app")
+	err := c.Merge(newTeamContainer("team"))
+	if !errors.Is(err, di.ErrConflict) {
+		t.Fatalf("Merge() error = %v, want ErrConflict", err)
+	}
+	if db := di.MustResolveIn[*mergeDB](c); db.owner != "app" {
+		t.Fatal("a failed Merge replaced a registration")
+	}
+	if plugins, _ := di.ResolveAllIn[*mergePlugin](c); len(plugins) != 1 {
+		t.Fatalf("a failed Merge imported %d plugins", len(plugins)-1)
+	}
+}
+
+func TestMergeConflictPolicies(t *testing.T) {
+	skip := newTeamContainer("app")
+	if err := skip.Merge(newTeamContainer("team"), di.OnConflict(di.ConflictSkip)); err != nil {
+		t.Fatal(err)
+	}
+	if db := di.MustResolveIn[*mergeDB](skip); db.owner != "app" {
+		t.Fatalf("ConflictSkip replaced the registration with %q", db.owner)
+	}
+
+	overwrite := newTeamContainer("app")
+	if err := overwrite.Merge(newTeamContainer("team"), di.OnConflict(di.ConflictOverwrite)); err != nil {
+		t.Fatal(err)
+	}
+	if db := di.MustResolveIn[*mergeDB](overwrite); db.owner != "team" {
+		t.Fatalf("ConflictOverwrite kept the registration of %q", db.owner)
+	}
+
+	for name, c := range map[string]*di.Container{"skip": skip, "overwrite": overwrite} {
+		if plugins, _ := di.ResolveAllIn[*mergePlugin](c); len(plugins) != 2 {
+			t.Fatalf("%s: multiple bindings = %d, want both", name, len(plugins))
+		}
+	}
+}
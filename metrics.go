@@ -0,0 +1,33 @@
+package di
+
+// Metrics receives measurements of the work a container does. Implementations
+// must be safe for concurrent use.
+type Metrics interface {
+	// ObserveResolve is called after every resolution with its duration and error
+	ObserveResolve(info ResolveInfo)
+	// ObserveFactory is called after every factory run with its duration and error
+	ObserveFactory(info ResolveInfo)
+	// ObserveCacheHit is called when a singleton is served from the cache
+	ObserveCacheHit(info ResolveInfo)
+}
+
+// WithMetrics makes the container report to m
+func WithMetrics(m Metrics) Option {
+	return func(c *Container) {
+		c.SetMetrics(m)
+	}
+}
+
+// SetMetrics makes the default container report to m
+func SetMetrics(m Metrics) {
+	Default().SetMetrics(m)
+}
+
+// SetMetrics makes c report to m. A nil m stops reporting.
+func (c *Container) SetMetrics(m Metrics) {
+	if m == nil {
+		c.metrics.Store(nil)
+		return
+	}
+	c.metrics.Store(&m)
+}
@@ -0,0 +1,344 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// UseStruct registers every constructor field of a module struct as a factory.
+// Fields must be constructors of a form RegisterConstructor accepts, whose
+// parameters are resolved from the container; nil fields are skipped. A
+// `di:"name=primary,group=storage,lifetime=scoped"` tag sets the registration
+// name, group and lifetime, which is singleton by default. Every field is
+// checked before any is registered, so an invalid field registers nothing.
+func UseStruct(module any) error {
+	return Default().UseStruct(module)
+}
+
+// UseStruct registers every constructor field of a module struct in c
+func (c *Container) UseStruct(module any) error {
+	v := reflect.ValueOf(module)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("UseStruct expects a struct, got %T", module)
+	}
+
+	type moduleField struct {
+		name string
+		fn   reflect.Value
+		tag  moduleTag
+	}
+	var fields []moduleField
+	keys := make(map[key]string)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag, err := parseModuleTag(field.Tag.Get("di"))
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		fn := v.Field(i)
+		if field.Type.Kind() != reflect.Func {
+			return fmt.Errorf("field %s: expected a constructor func, got %v", field.Name, field.Type)
+		}
+		if fn.IsNil() {
+			continue
+		}
+		if _, _, err := constructorResults(field.Type); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		// grouped bindings without a name are collected, so they never conflict
+		if k := (key{typ: field.Type.Out(0), name: tag.name}); c.strict && (tag.name != "" || len(tag.opts) == 0) {
+			if other, ok := keys[k]; ok {
+				return fmt.Errorf("field %s: %w: %v is also registered by field %s", field.Name, ErrConflict, k, other)
+			}
+			if existing, ok := c.factories.load(k); ok && !existing.fallback {
+				return fmt.Errorf("field %s: %w: %v is already registered", field.Name, ErrConflict, k)
+			}
+			keys[k] = field.Name
+		}
+		fields = append(fields, moduleField{name: field.Name, fn: fn, tag: tag})
+	}
+
+	for _, field := range fields {
+		if err := c.addConstructor(field.fn, field.tag.name, field.tag.lifetime, field.tag.opts); err != nil {
+			return fmt.Errorf("field %s: %w", field.name, err)
+		}
+	}
+	return nil
+}
+
+// moduleTag is the parsed tag of a UseStruct field
+type moduleTag struct {
+	name     string
+	lifetime Lifetime
+	opts     []RegisterOption
+}
+
+// parseModuleTag reads the name, lifetime and registration options of a UseStruct field tag
+func parseModuleTag(s string) (moduleTag, error) {
+	tag := moduleTag{lifetime: Singleton}
+	if s == "" {
+		return tag, nil
+	}
+	for _, opt := range strings.Split(s, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch k {
+		case "name":
+			tag.name = v
+		case "group":
+			tag.opts = append(tag.opts, WithGroup(v))
+		case "lifetime":
+			switch v {
+			case "singleton":
+				tag.lifetime = Singleton
+			case "scoped":
+				tag.lifetime = Scoped
+			case "transient":
+				tag.lifetime = Transient
+			default:
+				return tag, fmt.Errorf("unsupported lifetime %q", v)
+			}
+		default:
+			return tag, fmt.Errorf("unsupported tag option %q", k)
+		}
+	}
+	return tag, nil
+}
+
+// Module is a named bundle of registrations that is installed and
+// uninstalled as a unit
+type Module struct {
+	name    string
+	install func(c *Container)
+	version string
+	// provides lists the capabilities the module offers besides its name
+	provides []string
+	// requires lists the capabilities that must be installed with the module
+	requires []string
+}
+
+// ModuleOption configures a module
+type ModuleOption func(m *Module)
+
+// WithVersion sets the version of a module. Use refuses to install two
+// versions of the same module.
+func WithVersion(version string) ModuleOption {
+	return func(m *Module) {
+		m.version = version
+	}
+}
+
+// Provides declares capabilities the module offers in addition to its name,
+// such as "sql" for a module registering a database
+func Provides(capabilities ...string) ModuleOption {
+	return func(m *Module) {
+		m.provides = append(m.provides, capabilities...)
+	}
+}
+
+// Requires declares capabilities, module names or names given to Provides,
+// that must be installed for the module to work
+func Requires(capabilities ...string) ModuleOption {
+	return func(m *Module) {
+		m.requires = append(m.requires, capabilities...)
+	}
+}
+
+// installedModule is a module installed in a container
+type installedModule struct {
+	module *Module
+	// parent is the module whose install function installed it, if any
+	parent *Module
+}
+
+// NewModule creates a module whose install function makes its registrations
+func NewModule(name string, install func(c *Container), opts ...ModuleOption) *Module {
+	m := &Module{name: name, install: install}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Name returns the name of the module
+func (m *Module) Name() string {
+	return m.name
+}
+
+// Version returns the version of the module, empty if it has none
+func (m *Module) Version() string {
+	return m.version
+}
+
+// String returns the name of the module with its version, if any
+func (m *Module) String() string {
+	if m.version == "" {
+		return m.name
+	}
+	return m.name + "@" + m.version
+}
+
+// Install installs the module in the default container
+func Install(m *Module) error {
+	return Default().Install(m)
+}
+
+// Install runs the install function of m against c and records the
+// registrations it makes as belonging to m. Modules may install other
+// modules. Installing a module with the name of an installed one is an error.
+// Registrations made concurrently by other goroutines during Install may be
+// attributed to m.
+func (c *Container) Install(m *Module) error {
+	c.modulesMu.Lock()
+	for _, installed := range c.modules {
+		if installed.module.name == m.name {
+			c.modulesMu.Unlock()
+			return fmt.Errorf("%w: module %q is already installed", ErrConflict, m.name)
+		}
+	}
+	c.modules = append(c.modules, installedModule{module: m, parent: c.installing.Load()})
+	c.modulesMu.Unlock()
+
+	previous := c.installing.Swap(m)
+	defer c.installing.Store(previous)
+	m.install(c)
+	return nil
+}
+
+// Use installs modules in the default container
+func Use(modules ...*Module) error {
+	return Default().Use(modules...)
+}
+
+// Use checks modules against each other and the modules installed in c, then
+// installs them in order. Modules with the name of a module that is already
+// installed or listed earlier are skipped if their versions match; otherwise
+// they conflict. The requirements of every module must be provided by the
+// modules installed or listed. All problems are reported together, matching
+// ErrConflict or ErrMissingRequirement, and nothing is installed if there is any.
+func (c *Container) Use(modules ...*Module) error {
+	c.modulesMu.Lock()
+	seen := make(map[string]*Module)
+	provided := make(map[string]bool)
+	offer := func(m *Module) {
+		seen[m.name] = m
+		provided[m.name] = true
+		for _, capability := range m.provides {
+			provided[capability] = true
+		}
+	}
+	for _, installed := range c.modules {
+		offer(installed.module)
+	}
+	c.modulesMu.Unlock()
+
+	var errs []error
+	var pending []*Module
+	for _, m := range modules {
+		if other, ok := seen[m.name]; ok {
+			if other.version != m.version {
+				errs = append(errs, fmt.Errorf("%w: module %s conflicts with %s", ErrConflict, m, other))
+			}
+			continue
+		}
+		offer(m)
+		pending = append(pending, m)
+	}
+	for _, m := range pending {
+		var missing []string
+		for _, capability := range m.requires {
+			if !provided[capability] {
+				missing = append(missing, strconv.Quote(capability))
+			}
+		}
+		if len(missing) > 0 {
+			errs = append(errs, fmt.Errorf("%w: module %s requires %s", ErrMissingRequirement, m, strings.Join(missing, ", ")))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for _, m := range pending {
+		if err := c.Install(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Uninstall removes a module from the default container
+func Uninstall(name string) error {
+	return Default().Uninstall(name)
+}
+
+// Uninstall removes the module named name from c, together with the modules
+// it installed and every registration they made. The singletons built from
+// those registrations are torn down as Shutdown does, after their stop
+// hooks; the errors are joined.
+func (c *Container) Uninstall(name string) error {
+	c.modulesMu.Lock()
+	i := slices.IndexFunc(c.modules, func(m installedModule) bool {
+		return m.module.name == name
+	})
+	if i < 0 {
+		c.modulesMu.Unlock()
+		return fmt.Errorf("module %q is not installed", name)
+	}
+	removed := map[string]bool{name: true}
+	for grew := true; grew; {
+		grew = false
+		for _, m := range c.modules {
+			if m.parent != nil && removed[m.parent.name] && !removed[m.module.name] {
+				removed[m.module.name] = true
+				grew = true
+			}
+		}
+	}
+	c.modules = slices.DeleteFunc(c.modules, func(m installedModule) bool {
+		return removed[m.module.name]
+	})
+	c.modulesMu.Unlock()
+
+	factories := c.sortedFactories(func(f *factory) bool { return removed[f.module] })
+	for _, f := range factories {
+		c.unregister(f.key)
+	}
+
+	built := c.takeBuiltOf(func(f *factory) bool { return removed[f.module] })
+	steps := c.stopSteps(built)
+	for i := len(built) - 1; i >= 0; i-- {
+		steps = append(steps, stopStep{key: built[i].factory.key, kind: "close", run: built[i].teardown})
+	}
+	return errors.Join(c.runStopSteps(context.Background(), steps)...)
+}
+
+// Modules returns the names of the modules installed in the default container
+func Modules() []string {
+	return Default().Modules()
+}
+
+// Modules returns the names of the modules installed in c, in installation order
+func (c *Container) Modules() []string {
+	c.modulesMu.Lock()
+	defer c.modulesMu.Unlock()
+
+	names := make([]string, len(c.modules))
+	for i, m := range c.modules {
+		names[i] = m.module.name
+	}
+	return names
+}
@@ -0,0 +1,271 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type moduleConfig struct{ dsn string }
+type moduleStore struct{ cfg *moduleConfig }
+type moduleRequest struct{ store *moduleStore }
+
+func TestUseStructResolvesConstructorParameters(t *testing.T) {
+	c := di.New()
+	err := c.UseStruct(struct {
+		Config func() *moduleConfig `di:"name=primary"`
+		Store  func(cfg *moduleConfig) *moduleStore
+	}{
+		Config: func() *moduleConfig { return &moduleConfig{dsn: "db"} },
+		Store:  func(cfg *moduleConfig) *moduleStore { return &moduleStore{cfg: cfg} },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	di.RegisterIn(c, &moduleConfig{dsn: "default"})
+
+	store := di.MustResolveIn[*moduleStore](c)
+	if store.cfg == nil || store.cfg.dsn != "default" {
+		t.Fatalf("store built with %+v, want the unnamed config", store.cfg)
+	}
+	if cfg, err := di.ResolveNamedIn[*moduleConfig](c, "primary"); err != nil || cfg.dsn != "db" {
+		t.Fatalf("named config = %+v, %v", cfg, err)
+	}
+}
+
+func TestUseStructLifetimeTag(t *testing.T) {
+	c := di.New()
+	err := c.UseStruct(struct {
+		Store   func() *moduleStore               `di:"lifetime=transient"`
+		Request func(*moduleStore) *moduleRequest `di:"lifetime=scoped"`
+	}{
+		Store:   func() *moduleStore { return &moduleStore{} },
+		Request: func(s *moduleStore) *moduleRequest { return &moduleRequest{store: s} },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if di.MustResolveIn[*moduleStore](c) == di.MustResolveIn[*moduleStore](c) {
+		t.Fatal("a transient field was built once")
+	}
+
+	s1, s2 := c.NewScope(), c.NewScope()
+	defer s1.Close()
+	defer s2.Close()
+	a := di.MustResolveIn[*moduleRequest](s1)
+	if di.MustResolveIn[*moduleRequest](s1) != a {
+		t.Fatal("a scoped field was built twice in one scope")
+	}
+	if di.MustResolveIn[*moduleRequest](s2) == a {
+		t.Fatal("two scopes share a scoped field")
+	}
+}
+
+func TestUseStructRejectsUnknownLifetime(t *testing.T) {
+	c := di.New()
+	err := c.UseStruct(struct {
+		Store func() *moduleStore `di:"lifetime=forever"`
+	}{Store: func() *moduleStore { return &moduleStore{} }})
+	if err == nil {
+		t.Fatal("UseStruct() accepted an unknown lifetime")
+	}
+	err = c.UseStruct(struct {
+		Store *moduleStore
+	}{Store: &moduleStore{}})
+	if err == nil {
+		t.Fatal("UseStruct() accepted a field that is not a func")
+	}
+}
+
+func TestUseStructRegistersNothingOnInvalidField(t *testing.T) {
+	c := di.New()
+	err := c.UseStruct(struct {
+		Config func() *moduleConfig
+		Store  func() (*moduleStore, int)
+	}{
+		Config: func() *moduleConfig { return &moduleConfig{} },
+		Store:  func() (*moduleStore, int) { return &moduleStore{}, 0 },
+	})
+	if err == nil {
+		t.Fatal("UseStruct() accepted a constructor with unsupported results")
+	}
+	if _, err := di.ResolveIn[*moduleConfig](c); !errors.Is(err, di.ErrNotRegistered) {
+		t.Fatal("the fields before the invalid one were registered")
+	}
+}
+
+func TestUseStructRegistersNothingOnStrictConflict(t *testing.T) {
+	c := di.New(di.WithStrict())
+	di.RegisterIn(c, &moduleStore{})
+	err := c.UseStruct(struct {
+		Config func() *moduleConfig
+		Store  func() *moduleStore
+	}{
+		Config: func() *moduleConfig { return &moduleConfig{} },
+		Store:  func() *moduleStore { return &moduleStore{} },
+	})
+	if !errors.Is(err, di.ErrConflict) {
+		t.Fatalf("UseStruct() returned %v, want ErrConflict", err)
+	}
+	if _, err := di.ResolveIn[*moduleConfig](c); !errors.Is(err, di.ErrNotRegistered) {
+		t.Fatal("the fields before the conflicting one were registered")
+	}
+}
+
+type moduleConn struct{ closes int }
+
+func (c *moduleConn) Close() error {
+	c.closes++
+	return nil
+}
+
+type moduleCache struct{}
+
+func TestInstallAndUninstall(t *testing.T) {
+	c := di.New()
+	var stopped bool
+	storage := di.NewModule("storage", func(c *di.Container) {
+		di.RegisterFactoryIn(c, func() *moduleConn { return &moduleConn{} },
+			di.OnStop(func(context.Context, *moduleConn) error { stopped = true; return nil }))
+	})
+	if err := c.Install(storage); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Install(storage); !errors.Is(err, di.ErrConflict) {
+		t.Fatalf("second Install() error = %v, want ErrConflict", err)
+	}
+	conn := di.MustResolveIn[*moduleConn](c)
+
+	if err := c.Uninstall("storage"); err != nil {
+		t.Fatal(err)
+	}
+	if !stopped || conn.closes != 1 {
+		t.Fatalf("Uninstall() stopped = %v, closes = %d, want the instance torn down", stopped, conn.closes)
+	}
+	if _, err := di.ResolveIn[*moduleConn](c); !errors.Is(err, di.ErrNotRegistered) {
+		t.Fatalf("ResolveIn() after Uninstall error = %v, want ErrNotRegistered", err)
+	}
+	if got := c.Modules(); len(got) != 0 {
+		t.Fatalf("Modules() = %v, want none", got)
+	}
+	if err := c.Uninstall("storage"); err == nil {
+		t.Fatal("Uninstall() of a module that is not installed succeeded")
+	}
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if conn.closes != 1 {
+		t.Fatalf("Shutdown() closed the uninstalled instance again (%d closes)", conn.closes)
+	}
+}
+
+func TestUninstallRemovesNestedModules(t *testing.T) {
+	c := di.New()
+	cache := di.NewModule("cache", func(c *di.Container) {
+		di.RegisterIn(c, &moduleCache{})
+	})
+	app := di.NewModule("app", func(c *di.Container) {
+		if err := c.Install(cache); err != nil {
+			t.Error(err)
+		}
+		di.RegisterFactoryIn(c, func() *moduleConn { return &moduleConn{} })
+	})
+	other := di.NewModule("other", func(c *di.Container) {
+		di.RegisterIn(c, "kept")
+	})
+	for _, m := range []*di.Module{app, other} {
+		if err := c.Install(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := c.Modules(); len(got) != 3 {
+		t.Fatalf("Modules() = %v, want app, cache and other", got)
+	}
+
+	if err := c.Uninstall("app"); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Modules(); len(got) != 1 || got[0] != "other" {
+		t.Fatalf("Modules() = %v, want [other]", got)
+	}
+	if _, err := di.ResolveIn[*moduleCache](c); !errors.Is(err, di.ErrNotRegistered) {
+		t.Fatalf("nested registration survived: %v", err)
+	}
+	if v := di.MustResolveIn[string](c); v != "kept" {
+		t.Fatalf("unrelated registration = %q", v)
+	}
+}
+
+func TestResetForgetsModules(t *testing.T) {
+	c := di.New()
+	storage := di.NewModule("storage", func(c *di.Container) {
+		di.RegisterIn(c, &moduleConn{})
+	})
+	if err := c.Install(storage); err != nil {
+		t.Fatal(err)
+	}
+	c.Reset()
+	if got := c.Modules(); len(got) != 0 {
+		t.Fatalf("Modules() after Reset = %v, want none", got)
+	}
+	if err := c.Install(storage); err != nil {
+		t.Fatalf("Install() after Reset error = %v", err)
+	}
+	di.MustResolveIn[*moduleConn](c)
+}
+
+func TestUseInstallsModulesWithTheirRequirements(t *testing.T) {
+	c := di.New()
+	storage := di.NewModule("storage", func(c *di.Container) {
+		di.RegisterIn(c, &moduleConfig{dsn: "db"})
+	}, di.WithVersion("v1.2.0"), di.Provides("sql"))
+	users := di.NewModule("users", func(c *di.Container) {
+		di.RegisterFactoryIn(c, func() *moduleStore {
+			return &moduleStore{cfg: di.MustResolveIn[*moduleConfig](c)}
+		})
+	}, di.Requires("sql"))
+
+	if err := c.Use(storage, users); err != nil {
+		t.Fatal(err)
+	}
+	if store := di.MustResolveIn[*moduleStore](c); store.cfg.dsn != "db" {
+		t.Fatalf("store built with %+v", store.cfg)
+	}
+
+	// a second team using the same version of storage is fine
+	if err := c.Use(di.NewModule("storage", func(*di.Container) {}, di.WithVersion("v1.2.0"))); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Modules(); len(got) != 2 {
+		t.Fatalf("installed modules %v", got)
+	}
+}
+
+func TestUseReportsEveryProblemAndInstallsNothing(t *testing.T) {
+	c := di.New()
+	if err := c.Use(di.NewModule("storage", func(*di.Container) {}, di.WithVersion("v1"))); err != nil {
+		t.Fatal(err)
+	}
+
+	installed := false
+	err := c.Use(
+		di.NewModule("cache", func(*di.Container) { installed = true }),
+		di.NewModule("storage", func(*di.Container) { installed = true }, di.WithVersion("v2")),
+		di.NewModule("billing", func(*di.Container) { installed = true }, di.Requires("payments", "cache", "queue")),
+	)
+	if !errors.Is(err, di.ErrConflict) || !errors.Is(err, di.ErrMissingRequirement) {
+		t.Fatalf("got %v, want a conflict and a missing requirement", err)
+	}
+	for _, want := range []string{"storage@v2 conflicts with storage@v1", `billing requires "payments", "queue"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+	if installed || len(c.Modules()) != 1 {
+		t.Fatal("a module was installed despite the errors")
+	}
+}
@@ -0,0 +1,163 @@
+package di
+
+import (
+	"cmp"
+	"iter"
+	"reflect"
+	"slices"
+	"sort"
+)
+
+// owner returns c itself
+func (c *Container) owner() *Container {
+	return c
+}
+
+// RegisterMulti adds instance as one more binding of T without replacing the others
+func RegisterMulti[T any](instance T, opts ...RegisterOption) {
+	RegisterMultiIn(Default(), instance, opts...)
+}
+
+// RegisterMultiIn adds instance as one more binding of T in c
+func RegisterMultiIn[T any](c *Container, instance T, opts ...RegisterOption) {
+	c.register(c.multiKey(typeKey[T]()), instance, opts)
+}
+
+// RegisterMultiFactory adds a factory as one more binding of T without replacing the others
+func RegisterMultiFactory[T any](f func() T, opts ...RegisterOption) {
+	RegisterMultiFactoryIn(Default(), f, opts...)
+}
+
+// RegisterMultiFactoryIn adds a factory as one more binding of T in c
+func RegisterMultiFactoryIn[T any](c *Container, f func() T, opts ...RegisterOption) {
+	c.registerFactory(c.multiKey(typeKey[T]()), Singleton, opts, func(Resolver) (any, func(), error) {
+		return f(), nil, nil
+	})
+}
+
+// RegisterOptions adds values as bindings of T, so constructors with a
+// variadic ...T parameter receive them. Modules use it to contribute options,
+// such as middlewares or dial options, to components they do not own.
+func RegisterOptions[T any](values ...T) {
+	RegisterOptionsIn(Default(), values...)
+}
+
+// RegisterOptionsIn adds values as bindings of T in c
+func RegisterOptionsIn[T any](c *Container, values ...T) {
+	for _, v := range values {
+		RegisterMultiIn(c, v)
+	}
+}
+
+// multiKey returns a fresh anonymous key for another binding of k's type
+func (c *Container) multiKey(k key) key {
+	k.id = c.seq.Add(1)
+	return k
+}
+
+// ResolveAll retrieves every binding of T, named and unnamed, by priority
+// and then in registration order
+func ResolveAll[T any]() ([]T, error) {
+	return ResolveAllIn[T](Default())
+}
+
+// ResolveAllIn retrieves every binding of T from a container or scope by
+// priority and then in registration order
+func ResolveAllIn[T any](r Resolver) ([]T, error) {
+	bindings := prioritized(r.owner().bindings(typeKey[T]().typ))
+
+	all := make([]T, 0, len(bindings))
+	for _, f := range bindings {
+		instance, err := resolveAs[T](r, f.key)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, instance)
+	}
+	return all, nil
+}
+
+// ResolveSeq yields the bindings of T from the default container lazily
+func ResolveSeq[T any]() iter.Seq2[T, error] {
+	return ResolveSeqIn[T](Default())
+}
+
+// ResolveSeqIn yields the bindings of T from a container or scope in the
+// order of ResolveAllIn, building each one only when the loop reaches it.
+// A binding that fails is yielded with its error and the loop may go on to
+// the next, so it suits fallback chains; breaking out of the loop leaves the
+// remaining bindings unbuilt.
+func ResolveSeqIn[T any](r Resolver) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, f := range prioritized(r.owner().bindings(typeKey[T]().typ)) {
+			if !yield(resolveAs[T](r, f.key)) {
+				return
+			}
+		}
+	}
+}
+
+// bindings returns the registrations of typ in registration order, after
+// the ones inherited from the parent that are not overridden
+func (c *Container) bindings(typ reflect.Type) []*factory {
+	own := c.sortedFactories(func(f *factory) bool {
+		return f.key.typ == typ
+	})
+	if c.parent == nil {
+		return own
+	}
+
+	var inherited []*factory
+	for _, f := range c.parent.bindings(typ) {
+		if _, ok := c.factories.load(f.key); !ok {
+			inherited = append(inherited, f)
+		}
+	}
+	return append(inherited, own...)
+}
+
+// prioritized sorts bindings by descending priority, keeping the order of
+// bindings with the same priority
+func prioritized(bindings []*factory) []*factory {
+	slices.SortStableFunc(bindings, func(a, b *factory) int {
+		return cmp.Compare(b.priority, a.priority)
+	})
+	return bindings
+}
+
+// sortedFactories returns the registrations accepted by keep in registration order
+func (c *Container) sortedFactories(keep func(f *factory) bool) []*factory {
+	var found []*factory
+	c.factories.each(func(_ key, f *factory) bool {
+		if keep(f) {
+			found = append(found, f)
+		}
+		return true
+	})
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].seq < found[j].seq
+	})
+	return found
+}
+
+// ResolveGroup retrieves the bindings of T registered in group, by priority
+// and then in registration order
+func ResolveGroup[T any](group string) ([]T, error) {
+	return ResolveGroupIn[T](Default(), group)
+}
+
+// ResolveGroupIn retrieves the bindings of T in group from a container or scope
+func ResolveGroupIn[T any](r Resolver, group string) ([]T, error) {
+	var members []T
+	for _, f := range prioritized(r.owner().bindings(typeKey[T]().typ)) {
+		if !slices.Contains(f.groups, group) {
+			continue
+		}
+		instance, err := resolveAs[T](r, f.key)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, instance)
+	}
+	return members, nil
+}
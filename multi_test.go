@@ -0,0 +1,108 @@
+package di_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type serverOption func(*optServer)
+type optServer struct {
+	name  string
+	ports []int
+}
+
+func TestVariadicConstructorReceivesOptions(t *testing.T) {
+	c := di.New()
+	di.RegisterIn(c, "api")
+	di.RegisterOptionsIn[serverOption](c,
+		func(s *optServer) { s.ports = append(s.ports, 80) },
+		func(s *optServer) { s.ports = append(s.ports, 443) },
+	)
+	di.RegisterMultiIn[serverOption](c, func(s *optServer) { s.ports = append(s.ports, 8080) }, di.WithPriority(1))
+
+	err := c.RegisterConstructor(func(name string, opts ...serverOption) *optServer {
+		s := &optServer{name: name}
+		for _, opt := range opts {
+			opt(s)
+		}
+		return s
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	s := di.MustResolveIn[*optServer](c)
+	if s.name != "api" || len(s.ports) != 3 || s.ports[0] != 8080 || s.ports[1] != 80 || s.ports[2] != 443 {
+		t.Fatalf("server = %+v, want api with ports [8080 80 443]", s)
+	}
+}
+
+func TestVariadicConstructorWithoutOptions(t *testing.T) {
+	c := di.New()
+	err := c.RegisterConstructor(func(opts ...serverOption) *optServer {
+		return &optServer{name: "bare", ports: make([]int, len(opts))}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := di.MustResolveIn[*optServer](c); s.name != "bare" || len(s.ports) != 0 {
+		t.Fatalf("server = %+v, want no options", s)
+	}
+}
+
+type seqStore struct{ name string }
+
+func TestResolveSeqBuildsLazily(t *testing.T) {
+	c := di.New()
+	built := map[string]bool{}
+	for _, name := range []string{"primary", "replica", "backup"} {
+		di.RegisterNamedFactoryEIn(c, name, func() (*seqStore, error) {
+			built[name] = true
+			if name == "primary" {
+				return nil, errors.New("primary down")
+			}
+			return &seqStore{name: name}, nil
+		})
+	}
+
+	var got *seqStore
+	var failed int
+	for s, err := range di.ResolveSeqIn[*seqStore](c) {
+		if err != nil {
+			failed++
+			continue
+		}
+		got = s
+		break
+	}
+	if failed != 1 || got == nil || got.name != "replica" {
+		t.Fatalf("got %+v after %d failures, want replica after 1", got, failed)
+	}
+	if built["backup"] {
+		t.Fatal("a binding after the break was built")
+	}
+}
+
+func TestWithPriorityOrdersBindings(t *testing.T) {
+	c := di.New()
+	di.RegisterMultiIn(c, "log")
+	di.RegisterMultiIn(c, "auth", di.WithPriority(10))
+	di.RegisterMultiIn(c, "last", di.WithPriority(-1))
+	di.RegisterMultiIn(c, "recover", di.WithPriority(10))
+
+	all, err := di.ResolveAllIn[string](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"auth", "recover", "log", "last"}
+	for i := range want {
+		if i >= len(all) || all[i] != want[i] {
+			t.Fatalf("ResolveAllIn() = %v, want %v", all, want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package di
+
+// RegisterNamed registers a singleton instance under name
+func RegisterNamed[T any](name string, instance T, opts ...RegisterOption) {
+	RegisterNamedIn(Default(), name, instance, opts...)
+}
+
+// RegisterNamedIn registers a singleton instance under name in c
+func RegisterNamedIn[T any](c *Container, name string, instance T, opts ...RegisterOption) {
+	c.register(namedKey[T](name), instance, opts)
+}
+
+// RegisterNamedFactory registers a factory under name for lazy initialization
+func RegisterNamedFactory[T any](name string, f func() T, opts ...RegisterOption) {
+	RegisterNamedFactoryIn(Default(), name, f, opts...)
+}
+
+// RegisterNamedFactoryIn registers a factory under name for lazy initialization in c
+func RegisterNamedFactoryIn[T any](c *Container, name string, f func() T, opts ...RegisterOption) {
+	c.registerFactory(namedKey[T](name), Singleton, opts, func(Resolver) (any, func(), error) {
+		return f(), nil, nil
+	})
+}
+
+// RegisterNamedFactoryE registers a factory that may fail under name for lazy initialization
+func RegisterNamedFactoryE[T any](name string, f func() (T, error), opts ...RegisterOption) {
+	RegisterNamedFactoryEIn(Default(), name, f, opts...)
+}
+
+// RegisterNamedFactoryEIn registers a factory that may fail under name for lazy initialization in c
+func RegisterNamedFactoryEIn[T any](c *Container, name string, f func() (T, error), opts ...RegisterOption) {
+	c.registerFactory(namedKey[T](name), Singleton, opts, func(Resolver) (any, func(), error) {
+		v, err := f()
+		return v, nil, err
+	})
+}
+
+// ResolveNamed retrieves the instance registered under name
+func ResolveNamed[T any](name string) (T, error) {
+	return ResolveNamedIn[T](Default(), name)
+}
+
+// ResolveNamedIn retrieves the instance registered under name from a container or scope
+func ResolveNamedIn[T any](r Resolver, name string) (T, error) {
+	return resolveAs[T](r, namedKey[T](name))
+}
+
+// MustResolveNamed retrieves the instance registered under name or panics if not found
+func MustResolveNamed[T any](name string) T {
+	v, err := ResolveNamed[T](name)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// UnregisterNamed removes the instance or factory registered under name
+func UnregisterNamed[T any](name string) {
+	UnregisterNamedIn[T](Default(), name)
+}
+
+// UnregisterNamedIn removes the instance or factory registered under name from c
+func UnregisterNamedIn[T any](c *Container, name string) {
+	c.unregister(namedKey[T](name))
+}
@@ -0,0 +1,128 @@
+package di
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// OnceValue holds a lazily constructed value that is built at most once
+// until it is reset. Failed constructions are not cached.
+type OnceValue[T any] struct {
+	// mu serializes constructions and resets
+	mu sync.Mutex
+	// value publishes the constructed value, nil until it is built
+	value   atomic.Pointer[T]
+	factory func() (T, error)
+}
+
+// Once returns a thread-safe lazy value backed by factory
+func Once[T any](factory func() (T, error)) *OnceValue[T] {
+	return &OnceValue[T]{factory: factory}
+}
+
+// Get returns the value, calling the factory on first use
+func (o *OnceValue[T]) Get() (T, error) {
+	p, err := o.get()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return *p, nil
+}
+
+// get returns the published value, calling the factory on first use. Each
+// construction publishes a new pointer, so it identifies the construction.
+func (o *OnceValue[T]) get() (*T, error) {
+	return o.getWith(o.factory)
+}
+
+// getWith is like get but builds the value with factory instead
+func (o *OnceValue[T]) getWith(factory func() (T, error)) (*T, error) {
+	if p := o.value.Load(); p != nil {
+		return p, nil
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if p := o.value.Load(); p != nil {
+		return p, nil
+	}
+
+	v, err := factory()
+	if err != nil {
+		return nil, err
+	}
+	o.value.Store(&v)
+	return &v, nil
+}
+
+// holds reports whether p is the value currently published
+func (o *OnceValue[T]) holds(p *T) bool {
+	return o.value.Load() == p
+}
+
+// Done reports whether the value has been constructed
+func (o *OnceValue[T]) Done() bool {
+	return o.value.Load() != nil
+}
+
+// Reset discards the cached value so the next Get calls the factory again
+func (o *OnceValue[T]) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.value.Store(nil)
+}
+
+// Memo caches the result of a keyed factory, building each key at most once
+type Memo[K comparable, T any] struct {
+	mu      sync.Mutex
+	values  map[K]*OnceValue[T]
+	factory func(K) (T, error)
+}
+
+// NewMemo returns a thread-safe memoizer backed by factory
+func NewMemo[K comparable, T any](factory func(K) (T, error)) *Memo[K, T] {
+	return &Memo[K, T]{
+		values:  make(map[K]*OnceValue[T]),
+		factory: factory,
+	}
+}
+
+// Get returns the value for key, calling the factory on first use
+func (m *Memo[K, T]) Get(key K) (T, error) {
+	return m.getWith(key, m.factory)
+}
+
+// getWith is like Get but builds a missing value with factory instead
+func (m *Memo[K, T]) getWith(key K, factory func(K) (T, error)) (T, error) {
+	m.mu.Lock()
+	o, ok := m.values[key]
+	if !ok {
+		o = Once(func() (T, error) { return m.factory(key) })
+		m.values[key] = o
+	}
+	m.mu.Unlock()
+
+	p, err := o.getWith(func() (T, error) { return factory(key) })
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return *p, nil
+}
+
+// Forget discards the cached value for key
+func (m *Memo[K, T]) Forget(key K) {
+	m.mu.Lock()
+	delete(m.values, key)
+	m.mu.Unlock()
+}
+
+// Reset discards all cached values
+func (m *Memo[K, T]) Reset() {
+	m.mu.Lock()
+	m.values = make(map[K]*OnceValue[T])
+	m.mu.Unlock()
+}
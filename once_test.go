@@ -0,0 +1,138 @@
+package di_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type onceCounter struct{ n int64 }
+
+func TestOnceValueBuildsOnce(t *testing.T) {
+	var calls atomic.Int64
+	o := di.Once(func() (int, error) {
+		calls.Add(1)
+		return 42, nil
+	})
+
+	var wg sync.WaitGroup
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := o.Get(); err != nil || v != 42 {
+				t.Errorf("Get() = %v, %v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("factory ran %d times, want 1", n)
+	}
+}
+
+func TestOnceValueReset(t *testing.T) {
+	var calls atomic.Int64
+	o := di.Once(func() (int64, error) {
+		return calls.Add(1), nil
+	})
+
+	if v, _ := o.Get(); v != 1 {
+		t.Fatalf("Get() = %d, want 1", v)
+	}
+	o.Reset()
+	if o.Done() {
+		t.Fatal("Done() after Reset")
+	}
+	if v, _ := o.Get(); v != 2 {
+		t.Fatalf("Get() after Reset = %d, want 2", v)
+	}
+}
+
+func TestOnceValueGetWhileResetting(t *testing.T) {
+	o := di.Once(func() (*onceCounter, error) {
+		return &onceCounter{n: 1}, nil
+	})
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if v, err := o.Get(); err != nil || v == nil || v.n != 1 {
+					t.Errorf("Get() = %v, %v", v, err)
+					return
+				}
+			}
+		}()
+	}
+
+	for range 1000 {
+		o.Reset()
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestResolveWhileInvalidating(t *testing.T) {
+	c := di.New()
+	var built atomic.Int64
+	di.RegisterFactoryIn(c, func() *onceCounter {
+		return &onceCounter{n: built.Add(1)}
+	})
+	if _, err := di.ResolveIn[*onceCounter](c); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				v, err := di.ResolveIn[*onceCounter](c)
+				if err != nil || v == nil || v.n == 0 {
+					t.Errorf("ResolveIn() = %v, %v", v, err)
+					return
+				}
+			}
+		}()
+	}
+
+	for range 1000 {
+		di.InvalidateIn[*onceCounter](c)
+	}
+	close(stop)
+	wg.Wait()
+
+	v, err := di.ResolveIn[*onceCounter](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if built.Load() < 2 {
+		t.Fatalf("factory ran %d times, want a rebuild after invalidation", built.Load())
+	}
+	// no instance built before the last invalidation may stay cached
+	if v.n != built.Load() {
+		t.Fatalf("resolved build %d, want the latest build %d", v.n, built.Load())
+	}
+	if again := di.MustResolveIn[*onceCounter](c); again != v {
+		t.Fatal("the latest build was not cached")
+	}
+}
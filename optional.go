@@ -0,0 +1,60 @@
+package di
+
+// TryResolve retrieves an instance of T, reporting false if T is not registered
+// or cannot be built
+func TryResolve[T any]() (T, bool) {
+	return TryResolveIn[T](Default())
+}
+
+// TryResolveIn retrieves an instance of T from a container or scope, reporting
+// false if T is not registered or cannot be built. A missing registration does
+// not allocate an error.
+func TryResolveIn[T any](r Resolver) (T, bool) {
+	k := typeKey[T]()
+	if !r.owner().has(k) {
+		var zero T
+		return zero, false
+	}
+
+	v, err := resolveAs[T](r, k)
+	return v, err == nil
+}
+
+// has reports whether k is registered in c or one of its parents
+func (c *Container) has(k key) bool {
+	_, ok := c.lookup(k)
+	return ok
+}
+
+// ResolveOr retrieves an instance of T, or fallback if T is not registered
+func ResolveOr[T any](fallback T) (T, error) {
+	return ResolveOrIn(Default(), fallback)
+}
+
+// ResolveOrIn retrieves an instance of T from a container or scope, or
+// fallback if T is not registered. If T is registered but cannot be built,
+// the error is returned rather than the fallback.
+func ResolveOrIn[T any](r Resolver, fallback T) (T, error) {
+	k := typeKey[T]()
+	if !r.owner().has(k) {
+		return fallback, nil
+	}
+	return resolveAs[T](r, k)
+}
+
+// ResolveOrFunc retrieves an instance of T, or the result of fallback if T is
+// not registered
+func ResolveOrFunc[T any](fallback func() T) (T, error) {
+	return ResolveOrFuncIn(Default(), fallback)
+}
+
+// ResolveOrFuncIn retrieves an instance of T from a container or scope, or
+// the result of fallback if T is not registered. If T is registered but
+// cannot be built, the error is returned and fallback is not called.
+func ResolveOrFuncIn[T any](r Resolver, fallback func() T) (T, error) {
+	k := typeKey[T]()
+	if !r.owner().has(k) {
+		return fallback(), nil
+	}
+	return resolveAs[T](r, k)
+}
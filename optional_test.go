@@ -0,0 +1,57 @@
+package di_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type orCache struct{ name string }
+
+func TestResolveOrFallsBackWhenNotRegistered(t *testing.T) {
+	c := di.New()
+	fallback := &orCache{name: "memory"}
+	if got, err := di.ResolveOrIn(c, fallback); err != nil || got != fallback {
+		t.Fatalf("ResolveOrIn() = %v, %v, want the fallback", got, err)
+	}
+	got, err := di.ResolveOrFuncIn(c, func() *orCache { return &orCache{name: "lazy"} })
+	if err != nil || got.name != "lazy" {
+		t.Fatalf("ResolveOrFuncIn() = %v, %v, want the fallback", got, err)
+	}
+}
+
+func TestResolveOrPrefersRegistration(t *testing.T) {
+	c := di.New()
+	registered := &orCache{name: "redis"}
+	di.RegisterIn(c, registered)
+	if got, err := di.ResolveOrIn(c, &orCache{}); err != nil || got != registered {
+		t.Fatalf("ResolveOrIn() = %v, %v, want the registered instance", got, err)
+	}
+}
+
+func TestResolveOrReportsFailedFactory(t *testing.T) {
+	c := di.New()
+	errDown := errors.New("redis down")
+	di.RegisterFactoryEIn(c, func() (*orCache, error) { return nil, errDown })
+
+	if _, err := di.ResolveOrIn(c, &orCache{}); !errors.Is(err, errDown) {
+		t.Fatalf("ResolveOrIn() error = %v, want %v", err, errDown)
+	}
+	called := false
+	_, err := di.ResolveOrFuncIn(c, func() *orCache { called = true; return nil })
+	if !errors.Is(err, errDown) || called {
+		t.Fatalf("ResolveOrFuncIn() error = %v, fallback called = %v", err, called)
+	}
+}
+
+func TestTryResolve(t *testing.T) {
+	c := di.New()
+	if _, ok := di.TryResolveIn[*orCache](c); ok {
+		t.Fatal("TryResolveIn() found an unregistered type")
+	}
+	di.RegisterIn(c, &orCache{})
+	if _, ok := di.TryResolveIn[*orCache](c); !ok {
+		t.Fatal("TryResolveIn() missed a registered type")
+	}
+}
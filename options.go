@@ -0,0 +1,72 @@
+package di
+
+import (
+	"os"
+	"time"
+)
+
+// RegisterOption configures a registration
+type RegisterOption func(f *factory)
+
+// WithGroup adds the registration to group. Grouped registrations are
+// collected alongside the other members of the group instead of replacing
+// the existing binding of their type.
+func WithGroup(group string) RegisterOption {
+	return func(f *factory) {
+		f.groups = append(f.groups, group)
+	}
+}
+
+// WithPriority sets the position of the registration among the bindings of
+// its type returned by ResolveAll and ResolveGroup: higher priorities come
+// first, and registrations of equal priority keep their registration order.
+// Registrations without a priority have priority 0.
+func WithPriority(n int) RegisterOption {
+	return func(f *factory) {
+		f.priority = n
+	}
+}
+
+// As also makes the registration resolvable as I, sharing the same instance.
+// The registered type must implement I; otherwise the registration fails
+// with ErrTypeMismatch.
+func As[I any]() RegisterOption {
+	return func(f *factory) {
+		f.aliases = append(f.aliases, typeKey[I]())
+	}
+}
+
+// WithCondition makes the registration take effect only if cond reports true.
+// cond is evaluated once, when the registration is made.
+func WithCondition(cond func() bool) RegisterOption {
+	return func(f *factory) {
+		if !cond() {
+			f.disabled = true
+		}
+	}
+}
+
+// WithEnv makes the registration take effect only if the environment variable
+// name is set to value
+func WithEnv(name, value string) RegisterOption {
+	return WithCondition(func() bool {
+		return os.Getenv(name) == value
+	})
+}
+
+// retryPolicy describes how failed factory runs are retried
+type retryPolicy struct {
+	attempts int
+	backoff  time.Duration
+}
+
+// WithRetry makes the factory run up to attempts times when it returns an
+// error, waiting backoff before the second attempt and doubling the wait
+// after each further failure. Waits end early when the context passed to
+// ResolveCtx is done. Panics are not retried. Failed constructions are never
+// cached, so a later resolution tries again.
+func WithRetry(attempts int, backoff time.Duration) RegisterOption {
+	return func(f *factory) {
+		f.retry = &retryPolicy{attempts: attempts, backoff: backoff}
+	}
+}
@@ -0,0 +1,38 @@
+package di_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type optWriter struct{}
+
+// Write discards p
+func (optWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+func TestAsResolvesTheSameInstance(t *testing.T) {
+	c := di.New()
+	di.RegisterFactoryIn(c, func() *optWriter { return &optWriter{} }, di.As[io.Writer]())
+
+	w := di.MustResolveIn[io.Writer](c)
+	if w != io.Writer(di.MustResolveIn[*optWriter](c)) {
+		t.Fatal("the alias resolved another instance")
+	}
+}
+
+func TestAsRejectsTypesThatDoNotImplement(t *testing.T) {
+	c := di.New()
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, di.ErrTypeMismatch) {
+			t.Fatalf("RegisterFactoryIn() panicked with %v, want ErrTypeMismatch", err)
+		}
+		if di.ContainsIn[*optWriter](c) {
+			t.Fatal("the rejected registration was stored")
+		}
+	}()
+	di.RegisterFactoryIn(c, func() *optWriter { return &optWriter{} }, di.As[io.Reader]())
+}
@@ -0,0 +1,16 @@
+package di
+
+// Provider is a dependency that resolves a T on every call. Consumers that
+// create many instances over time, such as per-message handlers, can take a
+// Provider[T] instead of a single T; transient registrations yield a new
+// instance on each call.
+type Provider[T any] func() (T, error)
+
+// build returns a Provider bound to r
+func (Provider[T]) build(r Resolver) (any, error) {
+	// calls outlive the resolution that built the dependent, and its context
+	r = unbind(r)
+	return Provider[T](func() (T, error) {
+		return ResolveIn[T](r)
+	}), nil
+}
@@ -0,0 +1,77 @@
+package di
+
+// Interceptor runs around the calls made through an interface proxy. It calls
+// call.Proceed to continue with the next interceptor, or the wrapped instance
+// after the last one, and may change the arguments before and the results
+// after. An interceptor that does not call Proceed, such as a failed
+// authorization check, returns the results it sets, zero values otherwise.
+type Interceptor func(call *Call)
+
+// Call is a method call made through an interface proxy generated by digen
+type Call struct {
+	// Interface is the qualified name of the proxied interface, such as app.Mailer
+	Interface string
+	Method    string
+	Args      []any
+	// Results holds one value per result of the method, nil until set
+	Results []any
+
+	interceptors []Interceptor
+	target       func(call *Call)
+	next         int
+}
+
+// Proceed runs the rest of the chain and stores its results in call.Results.
+// It may be called more than once, to retry the call.
+func (call *Call) Proceed() {
+	if call.next == len(call.interceptors) {
+		call.target(call)
+		return
+	}
+	i := call.interceptors[call.next]
+	call.next++
+	defer func() { call.next-- }()
+	i(call)
+}
+
+// Err returns the last result of the call if it is a non-nil error
+func (call *Call) Err() error {
+	if len(call.Results) == 0 {
+		return nil
+	}
+	err, _ := call.Results[len(call.Results)-1].(error)
+	return err
+}
+
+// SetErr sets the last result of a method returning an error to err
+func (call *Call) SetErr(err error) {
+	if len(call.Results) > 0 {
+		call.Results[len(call.Results)-1] = err
+	}
+}
+
+// Intercept runs call through interceptors, calling target after the last
+// one. Proxies generated by digen call it for every method; target calls the
+// wrapped instance with call.Args and stores the results in call.Results.
+func Intercept(call *Call, interceptors []Interceptor, target func(call *Call)) {
+	call.interceptors = interceptors
+	call.target = target
+	call.next = 0
+	call.Proceed()
+}
+
+// Proxy wraps every instance of the interface T built by the default container
+// with proxy, a constructor generated by digen, so interceptors run around
+// every call
+func Proxy[T any](proxy func(target T, interceptors ...Interceptor) T, interceptors ...Interceptor) {
+	ProxyIn(Default(), proxy, interceptors...)
+}
+
+// ProxyIn wraps every instance of the interface T built by c with proxy. It is
+// a decorator: instances resolved before the call are rebuilt, and a later
+// call wraps the proxies of earlier ones, so its interceptors run first.
+func ProxyIn[T any](c *Container, proxy func(target T, interceptors ...Interceptor) T, interceptors ...Interceptor) {
+	DecorateIn(c, func(target T) T {
+		return proxy(target, interceptors...)
+	})
+}
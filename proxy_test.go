@@ -0,0 +1,145 @@
+package di_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type Greeter interface {
+	Greet(name string) (string, error)
+}
+
+type englishGreeter struct{ calls int }
+
+func (g *englishGreeter) Greet(name string) (string, error) {
+	g.calls++
+	if name == "" {
+		return "", errors.New("no name")
+	}
+	return "hello " + name, nil
+}
+
+// greeterProxy is what digen generates for Greeter
+type greeterProxy struct {
+	target       Greeter
+	interceptors []di.Interceptor
+}
+
+func NewGreeterProxy(target Greeter, interceptors ...di.Interceptor) Greeter {
+	return &greeterProxy{target: target, interceptors: interceptors}
+}
+
+func (p *greeterProxy) Greet(a0 string) (string, error) {
+	call := &di.Call{Interface: "di_test.Greeter", Method: "Greet", Args: []any{a0}, Results: make([]any, 2)}
+	di.Intercept(call, p.interceptors, func(call *di.Call) {
+		a0, _ := call.Args[0].(string)
+		r0, r1 := p.target.Greet(a0)
+		call.Results[0], call.Results[1] = r0, r1
+	})
+	r0, _ := call.Results[0].(string)
+	r1, _ := call.Results[1].(error)
+	return r0, r1
+}
+
+func TestProxyInterceptorOrder(t *testing.T) {
+	c := di.New()
+	di.RegisterIn[Greeter](c, &englishGreeter{})
+
+	var trace []string
+	record := func(name string) di.Interceptor {
+		return func(call *di.Call) {
+			trace = append(trace, name+" "+call.Interface+"."+call.Method)
+			call.Proceed()
+			trace = append(trace, name+" done")
+		}
+	}
+	di.ProxyIn(c, NewGreeterProxy, record("inner"))
+	di.ProxyIn(c, NewGreeterProxy, record("outer"))
+
+	got, err := di.MustResolveIn[Greeter](c).Greet("ada")
+	if err != nil || got != "hello ada" {
+		t.Fatalf("Greet() = %q, %v", got, err)
+	}
+	want := []string{"outer di_test.Greeter.Greet", "inner di_test.Greeter.Greet", "inner done", "outer done"}
+	if !reflect.DeepEqual(trace, want) {
+		t.Fatalf("trace = %q, want %q", trace, want)
+	}
+}
+
+func TestProxyInterceptorChangesCall(t *testing.T) {
+	c := di.New()
+	di.RegisterIn[Greeter](c, &englishGreeter{})
+	di.ProxyIn(c, NewGreeterProxy, func(call *di.Call) {
+		call.Args[0] = "grace"
+		call.Proceed()
+		call.Results[0] = call.Results[0].(string) + "!"
+	})
+
+	got, err := di.MustResolveIn[Greeter](c).Greet("ada")
+	if err != nil || got != "hello grace!" {
+		t.Fatalf("Greet() = %q, %v", got, err)
+	}
+}
+
+func TestProxyInterceptorRetries(t *testing.T) {
+	c := di.New()
+	target := &englishGreeter{}
+	di.RegisterIn[Greeter](c, target)
+
+	var inner int
+	retry := func(call *di.Call) {
+		for range 3 {
+			call.Proceed()
+			if call.Err() == nil {
+				return
+			}
+		}
+	}
+	count := func(call *di.Call) {
+		inner++
+		call.Proceed()
+	}
+	di.ProxyIn(c, NewGreeterProxy, retry, count)
+
+	if _, err := di.MustResolveIn[Greeter](c).Greet(""); err == nil {
+		t.Fatal("Greet() succeeded")
+	}
+	if target.calls != 3 || inner != 3 {
+		t.Fatalf("target called %d times, inner interceptor %d times, want 3", target.calls, inner)
+	}
+}
+
+func TestProxyInterceptorShortCircuits(t *testing.T) {
+	c := di.New()
+	target := &englishGreeter{}
+	di.RegisterIn[Greeter](c, target)
+
+	denied := errors.New("denied")
+	di.ProxyIn(c, NewGreeterProxy, func(call *di.Call) {
+		call.SetErr(denied)
+	})
+
+	got, err := di.MustResolveIn[Greeter](c).Greet("ada")
+	if !errors.Is(err, denied) || got != "" {
+		t.Fatalf("Greet() = %q, %v, want denied", got, err)
+	}
+	if target.calls != 0 {
+		t.Fatalf("target called %d times, want 0", target.calls)
+	}
+}
+
+func TestProxyRebuildsResolvedInstances(t *testing.T) {
+	c := di.New()
+	di.RegisterFactoryIn[Greeter](c, func() Greeter { return &englishGreeter{} })
+	if _, ok := di.MustResolveIn[Greeter](c).(*englishGreeter); !ok {
+		t.Fatal("resolved a proxy before ProxyIn")
+	}
+
+	di.ProxyIn(c, NewGreeterProxy)
+	if _, ok := di.MustResolveIn[Greeter](c).(*greeterProxy); !ok {
+		t.Fatal("ProxyIn did not rebuild the resolved instance")
+	}
+}
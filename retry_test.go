@@ -0,0 +1,66 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ryanbekhen/di"
+)
+
+type retryConn struct{}
+
+func TestRetryRerunsFailedFactory(t *testing.T) {
+	c := di.New()
+	var calls atomic.Int64
+	di.RegisterFactoryEIn(c, func() (*retryConn, error) {
+		if calls.Add(1) < 3 {
+			return nil, errors.New("not ready")
+		}
+		return &retryConn{}, nil
+	}, di.WithRetry(3, time.Millisecond))
+
+	if _, err := di.ResolveIn[*retryConn](c); err != nil {
+		t.Fatalf("ResolveIn() error = %v", err)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Fatalf("factory ran %d times, want 3", n)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	c := di.New()
+	var calls atomic.Int64
+	errDown := errors.New("down")
+	di.RegisterFactoryEIn(c, func() (*retryConn, error) {
+		calls.Add(1)
+		return nil, errDown
+	}, di.WithRetry(2, time.Millisecond))
+
+	if _, err := di.ResolveIn[*retryConn](c); !errors.Is(err, errDown) {
+		t.Fatalf("ResolveIn() error = %v, want %v", err, errDown)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("factory ran %d times, want 2", n)
+	}
+}
+
+func TestRetryStopsWhenContextIsDone(t *testing.T) {
+	c := di.New()
+	var runs atomic.Int64
+	di.RegisterFactoryEIn(c, func() (*retryConn, error) {
+		runs.Add(1)
+		return nil, errors.New("unavailable")
+	}, di.WithRetry(5, time.Hour))
+
+	ctx, cancel := context.WithTimeout(di.WithContainer(context.Background(), c), 10*time.Millisecond)
+	defer cancel()
+	if _, err := di.ResolveCtx[*retryConn](ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ResolveCtx() error = %v, want context.DeadlineExceeded", err)
+	}
+	if n := runs.Load(); n != 1 {
+		t.Fatalf("factory ran %d times, want 1", n)
+	}
+}
@@ -0,0 +1,194 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Lifetime controls how long a factory-built instance is reused
+type Lifetime int
+
+const (
+	// Singleton instances are built once per container
+	Singleton Lifetime = iota
+	// Scoped instances are built once per Scope and torn down with it
+	Scoped
+	// Transient instances are built anew on every resolution
+	Transient
+)
+
+// String returns the name of the lifetime
+func (l Lifetime) String() string {
+	switch l {
+	case Singleton:
+		return "singleton"
+	case Scoped:
+		return "scoped"
+	case Transient:
+		return "transient"
+	default:
+		return fmt.Sprintf("Lifetime(%d)", int(l))
+	}
+}
+
+// Resolver is a source of instances: a Container or a Scope
+type Resolver interface {
+	// resolve retrieves the instance stored under k, building it with ctx
+	resolve(ctx context.Context, k key) (any, error)
+	owner() *Container
+}
+
+// Scope holds the instances of scoped registrations for one unit of work,
+// such as an HTTP request or a job
+type Scope struct {
+	container *Container
+	values    *Memo[key, any]
+
+	// mu guards created and closed
+	mu      sync.Mutex
+	created []*builtInstance
+	closed  bool
+
+	// detach stops the close tied to a context by ScopeFromContext, if any
+	detach func() bool
+}
+
+// RegisterScoped registers a factory that builds one instance per scope
+func RegisterScoped[T any](f func(s *Scope) T, opts ...RegisterOption) {
+	RegisterScopedIn(Default(), f, opts...)
+}
+
+// RegisterScopedIn registers a factory in c that builds one instance per scope
+func RegisterScopedIn[T any](c *Container, f func(s *Scope) T, opts ...RegisterOption) {
+	c.registerFactory(typeKey[T](), Scoped, opts, func(r Resolver) (any, func(), error) {
+		return f(unbind(r).(*Scope)), nil, nil
+	})
+}
+
+// RegisterTransient registers a factory that builds a new instance on every resolution
+func RegisterTransient[T any](f func() T, opts ...RegisterOption) {
+	RegisterTransientIn(Default(), f, opts...)
+}
+
+// RegisterTransientIn registers a factory in c that builds a new instance on every resolution
+func RegisterTransientIn[T any](c *Container, f func() T, opts ...RegisterOption) {
+	c.registerFactory(typeKey[T](), Transient, opts, func(Resolver) (any, func(), error) {
+		return f(), nil, nil
+	})
+}
+
+// NewScope opens a new scope on the default container
+func NewScope() *Scope {
+	return Default().NewScope()
+}
+
+// NewScope opens a new scope whose scoped instances live until Close
+func (c *Container) NewScope() *Scope {
+	s := &Scope{container: c}
+	s.values = NewMemo(func(k key) (any, error) {
+		return s.build(context.Background(), k)
+	})
+	return s
+}
+
+// Container returns the container the scope was opened on
+func (s *Scope) Container() *Container {
+	return s.container
+}
+
+// owner returns the container the scope was opened on
+func (s *Scope) owner() *Container {
+	return s.container
+}
+
+// resolve retrieves scoped instances from the scope, builds transients against
+// the scope and retrieves everything else from the container
+func (s *Scope) resolve(ctx context.Context, k key) (any, error) {
+	f, ok := s.container.lookup(k)
+	if ok && f.target != nil {
+		f.markUsed()
+		return s.resolve(ctx, *f.target)
+	}
+	if !ok || f.lifetime == Singleton {
+		return s.container.resolve(ctx, k)
+	}
+	f.markUsed()
+	if f.lifetime == Transient {
+		// built against the scope so it can depend on scoped registrations
+		if path := s.container.tracker.cycle(k); path != nil {
+			return nil, s.container.cycleError(path)
+		}
+		v, _, err := s.container.run(ctx, f, s)
+		return v, err
+	}
+
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed {
+		return nil, fmt.Errorf("%w: cannot resolve %v", ErrScopeClosed, k)
+	}
+	if path := s.container.tracker.cycle(k); path != nil {
+		return nil, s.container.cycleError(path)
+	}
+
+	return s.values.getWith(k, func(k key) (any, error) {
+		return s.build(ctx, k)
+	})
+}
+
+// build runs the scoped factory for key and records the instance for
+// teardown. An instance built after the scope closed is torn down at once.
+func (s *Scope) build(ctx context.Context, k key) (any, error) {
+	f, ok := s.container.lookup(k)
+	if !ok {
+		return nil, s.container.newResolveError(k)
+	}
+
+	v, cleanup, err := s.container.run(ctx, f, s)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &builtInstance{factory: f, instance: v, cleanup: cleanup}
+	s.mu.Lock()
+	if s.closed {
+		// the scope closed while the factory ran, so nothing else tears it down
+		s.mu.Unlock()
+		err := fmt.Errorf("%w: cannot resolve %v", ErrScopeClosed, k)
+		return nil, errors.Join(err, b.teardown(context.Background()))
+	}
+	s.created = append(s.created, b)
+	s.mu.Unlock()
+	return v, nil
+}
+
+// Close tears down the scoped instances in reverse creation order, calling
+// the cleanup function returned by their factory or, if there is none, Close
+// for instances that implement io.Closer. Closing twice is a no-op.
+func (s *Scope) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	created := s.created
+	s.created = nil
+	s.mu.Unlock()
+
+	if s.detach != nil {
+		s.detach()
+	}
+	s.values.Reset()
+
+	var errs []error
+	for i := len(created) - 1; i >= 0; i-- {
+		if err := created[i].teardown(context.Background()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
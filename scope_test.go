@@ -0,0 +1,163 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ryanbekhen/di"
+)
+
+type scopeRequest struct{ id int }
+type scopeHandler struct{ req *scopeRequest }
+
+func TestScopedInstancesArePerScope(t *testing.T) {
+	c := di.New()
+	next := 0
+	di.RegisterScopedIn(c, func(*di.Scope) *scopeRequest {
+		next++
+		return &scopeRequest{id: next}
+	})
+
+	s1, s2 := c.NewScope(), c.NewScope()
+	defer s1.Close()
+	defer s2.Close()
+
+	a := di.MustResolveIn[*scopeRequest](s1)
+	if b := di.MustResolveIn[*scopeRequest](s1); a != b {
+		t.Fatal("a scope built its scoped instance twice")
+	}
+	if b := di.MustResolveIn[*scopeRequest](s2); a == b {
+		t.Fatal("two scopes share a scoped instance")
+	}
+	if _, err := di.ResolveIn[*scopeRequest](c); !errors.Is(err, di.ErrScopeRequired) {
+		t.Fatalf("ResolveIn(container) error = %v, want ErrScopeRequired", err)
+	}
+}
+
+func TestClosedScopeRejectsResolutions(t *testing.T) {
+	c := di.New()
+	di.RegisterScopedIn(c, func(*di.Scope) *scopeRequest { return &scopeRequest{} })
+	s := c.NewScope()
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := di.ResolveIn[*scopeRequest](s); !errors.Is(err, di.ErrScopeClosed) {
+		t.Fatalf("ResolveIn() error = %v, want ErrScopeClosed", err)
+	}
+}
+
+func TestScopeClosedDuringBuildTearsInstanceDown(t *testing.T) {
+	c := di.New()
+	building, release := make(chan struct{}), make(chan struct{})
+	conn := &scopeConn{closed: make(chan struct{})}
+	di.RegisterScopedIn(c, func(*di.Scope) *scopeConn {
+		close(building)
+		<-release
+		return conn
+	})
+
+	s := c.NewScope()
+	errc := make(chan error, 1)
+	go func() {
+		_, err := di.ResolveIn[*scopeConn](s)
+		errc <- err
+	}()
+	<-building
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+
+	if err := <-errc; !errors.Is(err, di.ErrScopeClosed) {
+		t.Fatalf("ResolveIn() error = %v, want ErrScopeClosed", err)
+	}
+	select {
+	case <-conn.closed:
+	default:
+		t.Fatal("the instance built after Close was not closed")
+	}
+}
+
+func TestTransientFromScopeDependsOnScoped(t *testing.T) {
+	c := di.New()
+	di.RegisterScopedIn(c, func(*di.Scope) *scopeRequest { return &scopeRequest{} })
+	err := c.RegisterDynamicFactory(reflect.TypeFor[*scopeHandler](), di.Transient, func(r di.Resolver) (any, error) {
+		req, err := di.ResolveIn[*scopeRequest](r)
+		return &scopeHandler{req: req}, err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := c.NewScope()
+	defer s.Close()
+	h1, err := di.ResolveIn[*scopeHandler](s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2 := di.MustResolveIn[*scopeHandler](s)
+	if h1 == h2 {
+		t.Fatal("a transient was reused")
+	}
+	if h1.req != h2.req || h1.req != di.MustResolveIn[*scopeRequest](s) {
+		t.Fatal("transients did not receive the scoped instance of their scope")
+	}
+}
+
+type scopeConn struct{ closed chan struct{} }
+
+func (c *scopeConn) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func TestScopeFromContextClosesWhenDone(t *testing.T) {
+	c := di.New()
+	di.RegisterScopedIn(c, func(*di.Scope) *scopeConn { return &scopeConn{closed: make(chan struct{})} })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := c.ScopeFromContext(ctx)
+	conn := di.MustResolveIn[*scopeConn](s)
+
+	cancel()
+	select {
+	case <-conn.closed:
+	case <-time.After(time.Second):
+		t.Fatal("the scope was not closed when its context was cancelled")
+	}
+	if _, err := di.ResolveIn[*scopeConn](s); !errors.Is(err, di.ErrScopeClosed) {
+		t.Fatalf("ResolveIn() error = %v, want ErrScopeClosed", err)
+	}
+}
+
+func TestScopeFromContextClosedEarly(t *testing.T) {
+	c := di.New()
+	di.RegisterScopedIn(c, func(*di.Scope) *scopeConn { return &scopeConn{closed: make(chan struct{})} })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := c.ScopeFromContext(ctx)
+	conn := di.MustResolveIn[*scopeConn](s)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-conn.closed:
+	default:
+		t.Fatal("Close() did not close the scoped instance")
+	}
+}
+
+func TestScopeOf(t *testing.T) {
+	s := di.New().NewScope()
+	defer s.Close()
+	if got, ok := di.ScopeOf(di.WithScope(context.Background(), s)); !ok || got != s {
+		t.Fatalf("ScopeOf() = %v, %v", got, ok)
+	}
+	if _, ok := di.ScopeOf(context.Background()); ok {
+		t.Fatal("ScopeOf() found a scope in an empty context")
+	}
+}
@@ -0,0 +1,133 @@
+package di
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TypeStats describes how a registration has been used
+type TypeStats struct {
+	// ID identifies the registration, as in GraphNode
+	ID string
+	// Type is the name of the registered type
+	Type string
+	// Name is the registration name, if any
+	Name string
+	// Registered reports whether the key is currently registered
+	Registered bool
+	// Resolves counts the resolutions of the key
+	Resolves uint64
+	// FactoryRuns counts the runs of its factory
+	FactoryRuns uint64
+	// LastResolved is when the key was last resolved; zero if never
+	LastResolved time.Time
+	// BuildDuration is the total time spent in its factory
+	BuildDuration time.Duration
+}
+
+// stats holds the counters of each key
+type stats struct {
+	keys sync.Map // key → *keyStats
+}
+
+// keyStats holds the counters of one key
+type keyStats struct {
+	resolves     atomic.Uint64
+	factoryRuns  atomic.Uint64
+	lastResolved atomic.Int64
+	buildNanos   atomic.Int64
+}
+
+// WithStats makes the container collect resolution statistics from the start
+func WithStats() Option {
+	return func(c *Container) {
+		c.EnableStats()
+	}
+}
+
+// EnableStats makes the default container collect resolution statistics
+func EnableStats() {
+	Default().EnableStats()
+}
+
+// EnableStats makes c count resolutions and factory runs per registration.
+// Collecting adds a little work to every resolution, so it is off by default.
+func (c *Container) EnableStats() {
+	c.stats.CompareAndSwap(nil, &stats{})
+}
+
+// Stats returns the statistics of the default container
+func Stats() []TypeStats {
+	return Default().Stats()
+}
+
+// Stats returns the statistics of every registration of c in registration
+// order, followed by keys that were resolved but are not registered.
+// Counts are zero unless statistics were enabled.
+func (c *Container) Stats() []TypeStats {
+	s := c.stats.Load()
+	seen := make(map[key]bool)
+
+	var all []TypeStats
+	for _, f := range c.sortedFactories(func(*factory) bool { return true }) {
+		seen[f.key] = true
+		all = append(all, s.of(f.key, true))
+	}
+	if s != nil {
+		var missing []TypeStats
+		s.keys.Range(func(k, _ any) bool {
+			if !seen[k.(key)] {
+				missing = append(missing, s.of(k.(key), false))
+			}
+			return true
+		})
+		sort.Slice(missing, func(i, j int) bool {
+			return missing[i].ID < missing[j].ID
+		})
+		all = append(all, missing...)
+	}
+	return all
+}
+
+// counters returns the counters of k, creating them on first use
+func (s *stats) counters(k key) *keyStats {
+	if ks, ok := s.keys.Load(k); ok {
+		return ks.(*keyStats)
+	}
+	ks, _ := s.keys.LoadOrStore(k, &keyStats{})
+	return ks.(*keyStats)
+}
+
+// resolved counts a resolution of k made at the given time
+func (s *stats) resolved(k key, at time.Time) {
+	ks := s.counters(k)
+	ks.resolves.Add(1)
+	ks.lastResolved.Store(at.UnixNano())
+}
+
+// built counts a factory run for k that took d
+func (s *stats) built(k key, d time.Duration) {
+	ks := s.counters(k)
+	ks.factoryRuns.Add(1)
+	ks.buildNanos.Add(int64(d))
+}
+
+// of returns the statistics of k; s may be nil
+func (s *stats) of(k key, registered bool) TypeStats {
+	ts := TypeStats{ID: k.String(), Type: k.typ.String(), Name: k.name, Registered: registered}
+	if s == nil {
+		return ts
+	}
+	if v, ok := s.keys.Load(k); ok {
+		ks := v.(*keyStats)
+		ts.Resolves = ks.resolves.Load()
+		ts.FactoryRuns = ks.factoryRuns.Load()
+		ts.BuildDuration = time.Duration(ks.buildNanos.Load())
+		if last := ks.lastResolved.Load(); last != 0 {
+			ts.LastResolved = time.Unix(0, last)
+		}
+	}
+	return ts
+}
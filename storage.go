@@ -0,0 +1,150 @@
+package di
+
+import (
+	"hash/maphash"
+	"maps"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// shardCount is the number of shards of a shardedMap
+const shardCount = 256
+
+// shardSeed seeds the hash that assigns keys to shards
+var shardSeed = maphash.MakeSeed()
+
+// shardedMap maps keys to values for the registrations and instances of a
+// container. Each shard publishes an immutable snapshot, so reads are an
+// atomic load and a map lookup with no locking. Writes copy the snapshot of
+// one shard under its lock, which keeps their cost proportional to the keys
+// of the shard rather than of the whole container.
+type shardedMap[V any] struct {
+	shards [shardCount]shard[V]
+}
+
+// shard is one part of a shardedMap
+type shard[V any] struct {
+	// mu serializes the updates of snapshot
+	mu       sync.Mutex
+	snapshot atomic.Pointer[map[key]V]
+}
+
+// shardOf returns the shard that holds k
+func (m *shardedMap[V]) shardOf(k key) *shard[V] {
+	h := uint64(reflect.ValueOf(k.typ).Pointer())>>4 ^ k.id
+	if k.name != "" {
+		h ^= maphash.String(shardSeed, k.name)
+	}
+	return &m.shards[h%shardCount]
+}
+
+// load returns the value stored under k
+func (m *shardedMap[V]) load(k key) (V, bool) {
+	if s := m.shardOf(k).snapshot.Load(); s != nil {
+		v, ok := (*s)[k]
+		return v, ok
+	}
+	var zero V
+	return zero, false
+}
+
+// store publishes v as the value stored under k
+func (m *shardedMap[V]) store(k key, v V) {
+	m.swap(k, v)
+}
+
+// storeIf publishes v as the value stored under k if keep, which runs under
+// the lock of the shard, reports true. Writers that change what keep checks
+// before writing to the shard cannot interleave with it.
+func (m *shardedMap[V]) storeIf(k key, v V, keep func() bool) {
+	sh := m.shardOf(k)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if !keep() {
+		return
+	}
+	next := make(map[key]V, 1)
+	if s := sh.snapshot.Load(); s != nil {
+		next = maps.Clone(*s)
+	}
+	next[k] = v
+	sh.snapshot.Store(&next)
+}
+
+// swap publishes v as the value stored under k and returns the previous one
+func (m *shardedMap[V]) swap(k key, v V) (previous V, loaded bool) {
+	sh := m.shardOf(k)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	next := make(map[key]V, 1)
+	if s := sh.snapshot.Load(); s != nil {
+		next = maps.Clone(*s)
+		previous, loaded = next[k]
+	}
+	next[k] = v
+	sh.snapshot.Store(&next)
+	return previous, loaded
+}
+
+// delete removes the value stored under k, leaving the snapshot untouched
+// if there is none
+func (m *shardedMap[V]) delete(k key) {
+	if _, ok := m.load(k); !ok {
+		return
+	}
+
+	sh := m.shardOf(k)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	s := sh.snapshot.Load()
+	if s == nil {
+		return
+	}
+	if _, ok := (*s)[k]; !ok {
+		return
+	}
+	next := maps.Clone(*s)
+	delete(next, k)
+	sh.snapshot.Store(&next)
+}
+
+// clear removes every value
+func (m *shardedMap[V]) clear() {
+	for i := range m.shards {
+		sh := &m.shards[i]
+		sh.mu.Lock()
+		sh.snapshot.Store(nil)
+		sh.mu.Unlock()
+	}
+}
+
+// copyFrom makes m hold the values of other. The snapshots are immutable,
+// so they are shared rather than copied.
+func (m *shardedMap[V]) copyFrom(other *shardedMap[V]) {
+	for i := range m.shards {
+		sh := &m.shards[i]
+		sh.mu.Lock()
+		sh.snapshot.Store(other.shards[i].snapshot.Load())
+		sh.mu.Unlock()
+	}
+}
+
+// each calls fn for every value until fn returns false. Each shard is read
+// from its current snapshot.
+func (m *shardedMap[V]) each(fn func(k key, v V) bool) {
+	for i := range m.shards {
+		s := m.shards[i].snapshot.Load()
+		if s == nil {
+			continue
+		}
+		for k, v := range *s {
+			if !fn(k, v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package di_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type strictMailer struct{ from string }
+
+func TestStrictRejectsDuplicateRegistration(t *testing.T) {
+	c := di.New(di.WithStrict())
+	di.RegisterIn(c, &strictMailer{from: "first"})
+
+	func() {
+		defer func() {
+			err, _ := recover().(error)
+			if !errors.Is(err, di.ErrConflict) {
+				t.Fatalf("RegisterIn() panicked with %v, want ErrConflict", err)
+			}
+		}()
+		di.RegisterIn(c, &strictMailer{from: "second"})
+	}()
+	if m := di.MustResolveIn[*strictMailer](c); m.from != "first" {
+		t.Fatalf("the rejected registration replaced the first one: %q", m.from)
+	}
+
+	err := c.RegisterConstructor(func() *strictMailer { return &strictMailer{from: "ctor"} })
+	if !errors.Is(err, di.ErrConflict) {
+		t.Fatalf("RegisterConstructor() error = %v, want ErrConflict", err)
+	}
+}
+
+func TestStrictAllowsDistinctKeys(t *testing.T) {
+	c := di.New(di.WithStrict())
+	di.RegisterIn(c, &strictMailer{from: "default"})
+	di.RegisterNamedIn(c, "bulk", &strictMailer{from: "bulk"})
+	di.RegisterMultiIn(c, &strictMailer{})
+	di.RegisterMultiIn(c, &strictMailer{})
+
+	if m, err := di.ResolveNamedIn[*strictMailer](c, "bulk"); err != nil || m.from != "bulk" {
+		t.Fatalf("ResolveNamedIn() = %+v, %v", m, err)
+	}
+}
+
+func TestStrictReplacesDefaults(t *testing.T) {
+	c := di.New(di.WithStrict())
+	di.RegisterDefaultIn(c, &strictMailer{from: "default"})
+	di.RegisterIn(c, &strictMailer{from: "app"})
+	if m := di.MustResolveIn[*strictMailer](c); m.from != "app" {
+		t.Fatalf("resolved the mailer of %q, want app", m.from)
+	}
+}
+
+func TestWithoutStrictOverwrites(t *testing.T) {
+	c := di.New()
+	di.RegisterIn(c, &strictMailer{from: "first"})
+	di.RegisterIn(c, &strictMailer{from: "second"})
+	if m := di.MustResolveIn[*strictMailer](c); m.from != "second" {
+		t.Fatalf("resolved the mailer of %q, want second", m.from)
+	}
+}
@@ -0,0 +1,78 @@
+package di
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxSuggestions caps the number of "did you mean" candidates in an error
+const maxSuggestions = 3
+
+// suggest returns the registered keys that look like a typo or a package
+// mix-up of want, closest first
+func suggest(want string, registered []string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	base := unqualified(want)
+	var candidates []candidate
+	for _, name := range registered {
+		d := levenshtein(want, name)
+		other := unqualified(name)
+		switch {
+		case d <= max(2, len(want)/4):
+		case other == base:
+			// same type name in another package, or pointer vs value
+		case min(len(base), len(other)) >= 3 && (strings.HasPrefix(other, base) || strings.HasPrefix(base, other)):
+			// abbreviated or extended type name
+		default:
+			continue
+		}
+		candidates = append(candidates, candidate{name: name, distance: d})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	var suggestions []string
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, candidates[i].name)
+	}
+	return suggestions
+}
+
+// unqualified strips pointers, package paths and registration names from a
+// key so that *pkgA.Config, pkgB.Config and *pkgA.Config named "x" compare equal
+func unqualified(name string) string {
+	name, _, _ = strings.Cut(name, " ")
+	name = strings.TrimLeft(name, "*")
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,44 @@
+package di
+
+// Swap replaces the instance of T in the default container and returns the previous one
+func Swap[T any](instance T) (old T) {
+	return SwapIn(Default(), instance)
+}
+
+// SwapIn atomically replaces the registration of T in c with instance and
+// returns the previously resolved instance, or the zero value if there was
+// none. The decorators of T apply to instance as they do to built instances.
+// Concurrent resolutions see either the old or the new instance. The old
+// instance is not torn down; Swap is allowed in strict mode.
+func SwapIn[T any](c *Container, instance T) (old T) {
+	k := typeKey[T]()
+	f := c.newFactory(k, Singleton, func(Resolver) (any, func(), error) {
+		return instance, nil, nil
+	})
+	f.external = true
+	f.seq = c.seq.Add(1)
+
+	previous, replaced := c.factories.swap(k, f)
+	v, cached := c.instances.swap(k, c.applyDecorators(k, instance))
+	if replaced {
+		f.overrides = previous.site.copy()
+		// the instance is cached right away, so resolutions would not mark it
+		if previous.used.Load() {
+			f.used.Store(true)
+		}
+	}
+	if !cached && replaced {
+		if previous.once != nil && previous.once.Done() {
+			v, _ = previous.once.Get()
+			cached = true
+		}
+	}
+	c.logRegistration(f, replaced)
+	c.emitRegistration(f, replaced)
+	c.notifyRegistered()
+
+	if cached {
+		old, _ = v.(T)
+	}
+	return old
+}
@@ -0,0 +1,66 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type swapValue int
+
+func TestSwapAppliesDecorators(t *testing.T) {
+	c := di.New()
+	di.RegisterIn(c, swapValue(1))
+	di.DecorateIn(c, func(v swapValue) swapValue { return v + 100 })
+
+	if v := di.MustResolveIn[swapValue](c); v != 101 {
+		t.Fatalf("ResolveIn() = %d, want 101", v)
+	}
+	if old := di.SwapIn(c, swapValue(2)); old != 101 {
+		t.Fatalf("SwapIn() = %d, want the previous instance 101", old)
+	}
+	if v := di.MustResolveIn[swapValue](c); v != 102 {
+		t.Fatalf("ResolveIn() after SwapIn = %d, want 102", v)
+	}
+
+	di.InvalidateIn[swapValue](c)
+	if v := di.MustResolveIn[swapValue](c); v != 102 {
+		t.Fatalf("ResolveIn() after InvalidateIn = %d, want 102", v)
+	}
+}
+
+func TestSwapReturnsZeroWithoutPreviousInstance(t *testing.T) {
+	c := di.New()
+	di.RegisterFactoryIn(c, func() swapValue { return 1 })
+	if old := di.SwapIn(c, swapValue(2)); old != 0 {
+		t.Fatalf("SwapIn() = %d, want 0 for an unresolved factory", old)
+	}
+	if v := di.MustResolveIn[swapValue](c); v != 2 {
+		t.Fatalf("ResolveIn() = %d, want 2", v)
+	}
+}
+
+func TestSwapWhileBuilding(t *testing.T) {
+	c := di.New()
+	building, release := make(chan struct{}), make(chan struct{})
+	di.RegisterFactoryIn(c, func() swapValue {
+		close(building)
+		<-release
+		return 1
+	})
+
+	done := make(chan swapValue)
+	go func() { done <- di.MustResolveIn[swapValue](c) }()
+	<-building
+	di.SwapIn(c, swapValue(2))
+	close(release)
+
+	if v := <-done; v != 1 {
+		t.Fatalf("resolution in flight = %d, want the instance it built", v)
+	}
+	for range 3 {
+		if v := di.MustResolveIn[swapValue](c); v != 2 {
+			t.Fatalf("ResolveIn() after SwapIn = %d, want 2", v)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package di
+
+// TB is the part of testing.TB used by the test helpers; *testing.T,
+// *testing.B and *testing.F satisfy it
+type TB interface {
+	Helper()
+	Cleanup(func())
+}
+
+// Override replaces the registration of T in the default container with
+// replacement for the duration of the test and restores the previous
+// registration, or removes T, when the test ends
+func Override[T any](t TB, replacement T) {
+	t.Helper()
+	OverrideIn(Default(), t, replacement)
+}
+
+// OverrideIn replaces the registration of T in c for the duration of the test.
+// Singletons already built from the previous registration keep it.
+func OverrideIn[T any](c *Container, t TB, replacement T) {
+	t.Helper()
+	k := typeKey[T]()
+	f := c.newFactory(k, Singleton, func(Resolver) (any, func(), error) {
+		return replacement, nil, nil
+	})
+	f.external = true
+	f.seq = c.seq.Add(1)
+
+	previous, ok := c.factories.load(k)
+	if ok {
+		f.overrides = previous.site.copy()
+	}
+	c.replace(k, f)
+	t.Cleanup(func() {
+		if ok {
+			c.replace(k, previous)
+		} else {
+			c.unregister(k)
+		}
+	})
+}
+
+// replace stores f under k, bypassing strict mode, and drops the cached instance
+func (c *Container) replace(k key, f *factory) {
+	_, replaced := c.factories.swap(k, f)
+	c.instances.delete(k)
+	c.emitRegistration(f, replaced)
+	c.notifyRegistered()
+}
+
+// ForTest returns a new container that is reset when the test ends, running
+// the cleanup functions of the instances it built
+func ForTest(t TB) *Container {
+	t.Helper()
+	c := New()
+	t.Cleanup(c.Reset)
+	return c
+}
+
+// UseDefault installs c as the container of the package-level functions for
+// the duration of the test. Tests that call it must not run in parallel with
+// tests that use the default container.
+func UseDefault(t TB, c *Container) {
+	t.Helper()
+	t.Cleanup(SetDefault(c))
+}
@@ -0,0 +1,111 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type testingMailer struct{ name string }
+
+func TestOverrideRestoresRegistration(t *testing.T) {
+	c := di.New()
+	di.RegisterIn(c, &testingMailer{name: "smtp"})
+
+	t.Run("override", func(t *testing.T) {
+		di.OverrideIn(c, t, &testingMailer{name: "fake"})
+		if m := di.MustResolveIn[*testingMailer](c); m.name != "fake" {
+			t.Fatalf("resolved mailer %q, want fake", m.name)
+		}
+	})
+	if m := di.MustResolveIn[*testingMailer](c); m.name != "smtp" {
+		t.Fatalf("resolved mailer %q after the test, want smtp", m.name)
+	}
+}
+
+func TestOverrideRemovesUnregisteredType(t *testing.T) {
+	c := di.New()
+	t.Run("override", func(t *testing.T) {
+		di.OverrideIn(c, t, &testingMailer{name: "fake"})
+		di.MustResolveIn[*testingMailer](c)
+	})
+	if _, ok := di.TryResolveIn[*testingMailer](c); ok {
+		t.Fatal("the override outlived the test")
+	}
+}
+
+func TestOverrideBypassesStrictMode(t *testing.T) {
+	c := di.New(di.WithStrict())
+	di.RegisterIn(c, &testingMailer{name: "smtp"})
+	t.Run("override", func(t *testing.T) {
+		di.OverrideIn(c, t, &testingMailer{name: "fake"})
+		if m := di.MustResolveIn[*testingMailer](c); m.name != "fake" {
+			t.Fatalf("resolved mailer %q, want fake", m.name)
+		}
+	})
+}
+
+func TestOverrideRebuildsDependents(t *testing.T) {
+	type notifier struct{ mailer *testingMailer }
+	c := di.New()
+	di.RegisterIn(c, &testingMailer{name: "smtp"})
+	di.RegisterTransientIn(c, func() *notifier {
+		return &notifier{mailer: di.MustResolveIn[*testingMailer](c)}
+	})
+
+	t.Run("override", func(t *testing.T) {
+		di.OverrideIn(c, t, &testingMailer{name: "fake"})
+		if n := di.MustResolveIn[*notifier](c); n.mailer.name != "fake" {
+			t.Fatalf("dependent built with mailer %q, want fake", n.mailer.name)
+		}
+	})
+}
+
+func TestForTestResetsWhenTestEnds(t *testing.T) {
+	closed := false
+	var c *di.Container
+	t.Run("test", func(t *testing.T) {
+		c = di.ForTest(t)
+		di.RegisterFactoryCleanupIn(c, func() (*testingMailer, func()) {
+			return &testingMailer{}, func() { closed = true }
+		})
+		di.MustResolveIn[*testingMailer](c)
+	})
+	if !closed {
+		t.Fatal("the cleanup function did not run when the test ended")
+	}
+	if _, ok := di.TryResolveIn[*testingMailer](c); ok {
+		t.Fatal("the registration outlived the test")
+	}
+}
+
+func TestForTestContainersAreIsolated(t *testing.T) {
+	for _, name := range []string{"a", "b"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			c := di.ForTest(t)
+			di.RegisterIn(c, &testingMailer{name: name})
+			if m := di.MustResolveIn[*testingMailer](c); m.name != name {
+				t.Fatalf("resolved mailer %q, want %q", m.name, name)
+			}
+		})
+	}
+}
+
+func TestUseDefaultRestoresPreviousDefault(t *testing.T) {
+	previous := di.Default()
+	t.Run("test", func(t *testing.T) {
+		c := di.ForTest(t)
+		di.UseDefault(t, c)
+		di.Register(&testingMailer{name: "fake"})
+		if m := di.MustResolveIn[*testingMailer](c); m.name != "fake" {
+			t.Fatal("the package-level functions did not use the test container")
+		}
+	})
+	if di.Default() != previous {
+		t.Fatal("the default container was not restored")
+	}
+	if _, ok := di.TryResolve[*testingMailer](); ok {
+		t.Fatal("the test registration leaked into the default container")
+	}
+}
@@ -0,0 +1,68 @@
+package di
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// transientWatch counts transient constructions per key in fixed windows
+type transientWatch struct {
+	limit  int
+	window time.Duration
+
+	mu     sync.Mutex
+	counts map[key]*transientCount
+}
+
+// transientCount is the number of constructions of a key in the current window
+type transientCount struct {
+	start time.Time
+	n     int
+}
+
+// WarnFrequentTransients logs transients of the default container that are
+// built more than limit times within window
+func WarnFrequentTransients(limit int, window time.Duration) {
+	Default().WarnFrequentTransients(limit, window)
+}
+
+// WarnFrequentTransients logs a warning when a transient registration in c is
+// built more than limit times within window, which suggests it should be a
+// singleton or pooled. The warning is logged once per window and key. A zero
+// limit or window disables the warning.
+func (c *Container) WarnFrequentTransients(limit int, window time.Duration) {
+	if limit <= 0 || window <= 0 {
+		c.transients.Store(nil)
+		return
+	}
+	c.transients.Store(&transientWatch{limit: limit, window: window, counts: make(map[key]*transientCount)})
+}
+
+// observe counts a construction of k and reports whether it is the first one
+// over the limit in the current window
+func (w *transientWatch) observe(k key, now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := w.counts[k]
+	if n == nil || now.Sub(n.start) >= w.window {
+		n = &transientCount{start: now}
+		w.counts[k] = n
+	}
+	n.n++
+	return n.n == w.limit+1
+}
+
+// observeTransient warns if the transient f is built too often
+func (c *Container) observeTransient(f *factory) {
+	w := c.transients.Load()
+	if w == nil || !w.observe(f.key, time.Now()) {
+		return
+	}
+	if l := c.logger.Load(); l != nil {
+		l.Warn("frequent transient", "key", f.key.String(), "limit", w.limit, "window", w.window)
+	} else {
+		log.Printf("di: transient %s was built more than %d times within %s; consider a singleton or a pool", f.key, w.limit, w.window)
+	}
+}
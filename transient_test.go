@@ -0,0 +1,33 @@
+package di_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ryanbekhen/di"
+)
+
+type transientClient struct{}
+
+func TestWarnFrequentTransients(t *testing.T) {
+	var buf bytes.Buffer
+	c := di.New(di.WithLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+	di.RegisterTransientIn(c, func() *transientClient { return &transientClient{} })
+	c.WarnFrequentTransients(3, time.Hour)
+
+	for range 3 {
+		di.MustResolveIn[*transientClient](c)
+	}
+	if strings.Contains(buf.String(), "frequent transient") {
+		t.Fatalf("warned at the limit: %s", buf.String())
+	}
+	for range 5 {
+		di.MustResolveIn[*transientClient](c)
+	}
+	if n := strings.Count(buf.String(), "frequent transient"); n != 1 {
+		t.Fatalf("warned %d times over the limit, want once: %s", n, buf.String())
+	}
+}
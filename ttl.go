@@ -0,0 +1,180 @@
+package di
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// ttlSlot caches the instance of a singleton until it expires
+type ttlSlot struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	value   any
+	expires time.Time
+	// cleanup is the cleanup function returned with value, if any
+	cleanup func()
+	// resolved reports whether value was resolved since it was built
+	resolved bool
+	// refresh is the timer of a pending refresh-ahead, if any
+	refresh *time.Timer
+	// gen changes whenever the instance is discarded, so a refresh that
+	// finishes afterwards is dropped
+	gen uint64
+}
+
+// WithTTL makes a singleton factory discard its instance ttl after building
+// it; the next resolution runs the factory again. Expired instances have no
+// lifecycle hooks, and the cleanup functions of their factories are not run.
+func WithTTL(ttl time.Duration) RegisterOption {
+	return func(f *factory) {
+		f.ttl = &ttlSlot{ttl: ttl}
+	}
+}
+
+// WithRefreshAhead makes an expiring singleton rebuild its instance in the
+// background before it expires, once it is within before of expiring, if the
+// instance was resolved since it was built. The old instance keeps being
+// served until the new one is ready, so resolutions do not wait for the
+// factory, and is then torn down as Shutdown would. An instance nobody
+// resolves is not refreshed and expires as usual, as does one whose refresh
+// fails. It only applies together with WithTTL.
+func WithRefreshAhead(before time.Duration) RegisterOption {
+	return func(f *factory) {
+		f.refreshAhead = before
+	}
+}
+
+// RegisterFactoryTTL registers a factory whose instance is rebuilt once it is older than ttl
+func RegisterFactoryTTL[T any](f func() T, ttl time.Duration, opts ...RegisterOption) {
+	RegisterFactoryTTLIn(Default(), f, ttl, opts...)
+}
+
+// RegisterFactoryTTLIn registers a factory in c whose instance is rebuilt once it is older than ttl
+func RegisterFactoryTTLIn[T any](c *Container, f func() T, ttl time.Duration, opts ...RegisterOption) {
+	RegisterFactoryIn(c, f, append(opts, WithTTL(ttl))...)
+}
+
+// current returns the unexpired instance, if any
+func (s *ttlSlot) current() (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.expires.IsZero() || !time.Now().Before(s.expires) {
+		return nil, false
+	}
+	return s.value, true
+}
+
+// resolveTTL returns the unexpired instance of f or builds a new one
+func (c *Container) resolveTTL(ctx context.Context, f *factory) (any, error) {
+	s := f.ttl
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.expires.IsZero() && time.Now().Before(s.expires) {
+		s.resolved = true
+		return s.value, nil
+	}
+
+	v, cleanup, err := c.run(ctx, f, c)
+	if err != nil {
+		return nil, err
+	}
+	s.gen++
+	s.value, s.cleanup, s.expires, s.resolved = v, cleanup, time.Now().Add(s.ttl), false
+	c.scheduleRefresh(f)
+	return v, nil
+}
+
+// scheduleRefresh arms the refresh-ahead of f, if any. s.mu must be held.
+func (c *Container) scheduleRefresh(f *factory) {
+	s := f.ttl
+	if f.refreshAhead <= 0 || f.refreshAhead >= s.ttl {
+		return
+	}
+	gen := s.gen
+	s.refresh = time.AfterFunc(s.ttl-f.refreshAhead, func() {
+		c.refresh(f, gen)
+	})
+}
+
+// refresh rebuilds the instance of f while the current one keeps being
+// served and then tears the old one down. Nothing is rebuilt if the instance
+// was not resolved since it was built, was discarded, or f was replaced.
+func (c *Container) refresh(f *factory, gen uint64) {
+	s := f.ttl
+	s.mu.Lock()
+	due := s.gen == gen && s.resolved
+	if !due {
+		s.refresh = nil
+	}
+	s.mu.Unlock()
+	if !due {
+		return
+	}
+	if current, ok := c.factories.load(f.key); !ok || current != f {
+		return
+	}
+
+	v, cleanup, err := c.run(context.Background(), f, c)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.gen != gen {
+		// discarded while rebuilding, so the new instance is not used either
+		s.mu.Unlock()
+		c.dispose(f, v, cleanup)
+		return
+	}
+	old, oldCleanup := s.value, s.cleanup
+	s.gen++
+	s.value, s.cleanup, s.expires, s.resolved = v, cleanup, time.Now().Add(s.ttl), false
+	c.scheduleRefresh(f)
+	s.mu.Unlock()
+
+	c.dispose(f, old, oldCleanup)
+}
+
+// dispose tears down an instance of f that is no longer served, logging failures
+func (c *Container) dispose(f *factory, v any, cleanup func()) {
+	b := &builtInstance{factory: f, instance: v, cleanup: cleanup}
+	if err := b.teardown(context.Background()); err != nil {
+		if l := c.logger.Load(); l != nil {
+			l.Error("disposing instance", "key", f.key.String(), "err", err)
+		} else {
+			log.Printf("di: disposing instance of %s: %v", f.key, err)
+		}
+	}
+}
+
+// stopRefreshes stops the pending refresh-ahead of every expiring singleton
+// in c and tears their instances down
+func (c *Container) stopRefreshes() {
+	c.factories.each(func(_ key, f *factory) bool {
+		if f.ttl == nil {
+			return true
+		}
+		if v, cleanup := f.ttl.expire(); v != nil {
+			c.dispose(f, v, cleanup)
+		}
+		return true
+	})
+}
+
+// expire discards the cached instance, returning it with its cleanup, and
+// stops its pending refresh-ahead
+func (s *ttlSlot) expire() (any, func()) {
+	s.mu.Lock()
+	v, cleanup := s.value, s.cleanup
+	s.value, s.cleanup, s.expires, s.resolved = nil, nil, time.Time{}, false
+	s.gen++
+	if s.refresh != nil {
+		s.refresh.Stop()
+		s.refresh = nil
+	}
+	s.mu.Unlock()
+	return v, cleanup
+}
@@ -0,0 +1,127 @@
+package di_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ryanbekhen/di"
+)
+
+type ttlToken struct{ n int64 }
+
+func TestTTLRebuildsExpiredInstance(t *testing.T) {
+	c := di.New()
+	var built atomic.Int64
+	di.RegisterFactoryTTLIn(c, func() *ttlToken { return &ttlToken{n: built.Add(1)} }, 20*time.Millisecond)
+
+	a := di.MustResolveIn[*ttlToken](c)
+	if b := di.MustResolveIn[*ttlToken](c); a != b {
+		t.Fatal("the instance was rebuilt before it expired")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if b := di.MustResolveIn[*ttlToken](c); a == b {
+		t.Fatal("the expired instance was served")
+	}
+	if n := built.Load(); n != 2 {
+		t.Fatalf("factory ran %d times, want 2", n)
+	}
+}
+
+func TestRefreshAheadReplacesInstanceInBackground(t *testing.T) {
+	c := di.New()
+	var built atomic.Int64
+	di.RegisterFactoryTTLIn(c, func() *ttlToken {
+		n := built.Add(1)
+		if n > 1 {
+			time.Sleep(20 * time.Millisecond)
+		}
+		return &ttlToken{n: n}
+	}, 100*time.Millisecond, di.WithRefreshAhead(60*time.Millisecond))
+
+	first := di.MustResolveIn[*ttlToken](c)
+	deadline := time.Now().Add(time.Second)
+	for built.Load() < 2 && time.Now().Before(deadline) {
+		// the old instance is served while the refresh runs
+		start := time.Now()
+		if v := di.MustResolveIn[*ttlToken](c); v != first && v.n != 2 {
+			t.Fatalf("resolved instance %d", v.n)
+		}
+		if d := time.Since(start); d > 15*time.Millisecond {
+			t.Fatalf("a resolution waited %s for the refresh", d)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	deadline = time.Now().Add(time.Second)
+	for di.MustResolveIn[*ttlToken](c) == first {
+		if time.Now().After(deadline) {
+			t.Fatal("the instance was not refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	c.Reset()
+}
+
+type ttlConn struct{ closed *atomic.Int64 }
+
+func (c *ttlConn) Close() error {
+	c.closed.Add(1)
+	return nil
+}
+
+func TestRefreshAheadStopsWhenNotResolved(t *testing.T) {
+	c := di.New()
+	var built atomic.Int64
+	di.RegisterFactoryTTLIn(c, func() *ttlToken { return &ttlToken{n: built.Add(1)} },
+		20*time.Millisecond, di.WithRefreshAhead(10*time.Millisecond))
+
+	di.MustResolveIn[*ttlToken](c)
+	time.Sleep(150 * time.Millisecond)
+	if n := built.Load(); n > 2 {
+		t.Fatalf("factory ran %d times without resolutions, want at most 2", n)
+	}
+}
+
+func TestRefreshAheadClosesReplacedInstance(t *testing.T) {
+	c := di.New()
+	var closed atomic.Int64
+	di.RegisterFactoryTTLIn(c, func() *ttlConn { return &ttlConn{closed: &closed} },
+		50*time.Millisecond, di.WithRefreshAhead(40*time.Millisecond))
+
+	first := di.MustResolveIn[*ttlConn](c)
+	deadline := time.Now().Add(time.Second)
+	for di.MustResolveIn[*ttlConn](c) == first {
+		if time.Now().After(deadline) {
+			t.Fatal("the instance was not refreshed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// the old instance is closed once the new one is served
+	for closed.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := closed.Load(); n != 1 {
+		t.Fatalf("closed %d instances after a refresh, want 1", n)
+	}
+
+	c.Reset()
+	if n := closed.Load(); n != 2 {
+		t.Fatalf("closed %d instances after Reset, want 2", n)
+	}
+}
+
+func TestResetStopsRefreshAhead(t *testing.T) {
+	c := di.New()
+	var built atomic.Int64
+	di.RegisterFactoryTTLIn(c, func() *ttlToken { return &ttlToken{n: built.Add(1)} },
+		40*time.Millisecond, di.WithRefreshAhead(30*time.Millisecond))
+
+	di.MustResolveIn[*ttlToken](c)
+	di.MustResolveIn[*ttlToken](c)
+	c.Reset()
+	time.Sleep(50 * time.Millisecond)
+	if n := built.Load(); n != 1 {
+		t.Fatalf("factory ran %d times after Reset, want 1", n)
+	}
+}
@@ -0,0 +1,97 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Validate checks the wiring of the default container
+func Validate() error {
+	return Default().Validate()
+}
+
+// Validate checks, without building anything, that every dependency
+// declared by a registration of c is registered, that singletons do not
+// depend on scoped registrations and that declared dependencies have no
+// cycles. All problems found are joined into the returned error.
+func (c *Container) Validate() error {
+	factories := c.sortedFactories(func(*factory) bool { return true })
+	byKey := make(map[key]*factory, len(factories))
+	for _, f := range factories {
+		byKey[f.key] = f
+	}
+	for p := c.parent; p != nil; p = p.parent {
+		for _, f := range p.sortedFactories(func(*factory) bool { return true }) {
+			if _, ok := byKey[f.key]; !ok {
+				byKey[f.key] = f
+			}
+		}
+	}
+
+	var errs []error
+	for _, f := range factories {
+		for _, dep := range f.deps {
+			d, ok := byKey[dep]
+			switch {
+			case !ok && isBuiltin(dep):
+			case !ok:
+				errs = append(errs, fmt.Errorf("%v: missing dependency: %w", f.key, c.newResolveError(dep)))
+			case f.lifetime == Singleton && f.target == nil && lifetimeOf(d, byKey) == Scoped:
+				errs = append(errs, fmt.Errorf("singleton %v depends on scoped %v", f.key, dep))
+			}
+		}
+	}
+
+	// depth-first search for cycles among declared dependencies
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[key]int, len(factories))
+	var path []key
+	var visit func(k key)
+	visit = func(k key) {
+		switch state[k] {
+		case visiting:
+			for i := range path {
+				if path[i] == k {
+					errs = append(errs, c.cycleError(append(append([]key(nil), path[i:]...), k)))
+					break
+				}
+			}
+			return
+		case done:
+			return
+		}
+
+		f, ok := byKey[k]
+		if !ok {
+			return
+		}
+		state[k] = visiting
+		path = append(path, k)
+		for _, dep := range f.deps {
+			visit(dep)
+		}
+		path = path[:len(path)-1]
+		state[k] = done
+	}
+	for _, f := range factories {
+		visit(f.key)
+	}
+
+	return errors.Join(errs...)
+}
+
+// lifetimeOf returns the lifetime of f, following aliases to their target
+func lifetimeOf(f *factory, byKey map[key]*factory) Lifetime {
+	for seen := 0; f.target != nil && seen < len(byKey); seen++ {
+		target, ok := byKey[*f.target]
+		if !ok {
+			break
+		}
+		f = target
+	}
+	return f.lifetime
+}
@@ -0,0 +1,45 @@
+package di
+
+import (
+	"context"
+	"errors"
+)
+
+// notifyRegistered wakes up goroutines blocked in WaitResolve
+func (c *Container) notifyRegistered() {
+	c.changedMu.Lock()
+	close(c.changed)
+	c.changed = make(chan struct{})
+	c.changedMu.Unlock()
+}
+
+// registrations returns a channel closed on the next registration in c
+func (c *Container) registrations() <-chan struct{} {
+	c.changedMu.Lock()
+	defer c.changedMu.Unlock()
+	return c.changed
+}
+
+// WaitResolve blocks until an instance of T can be resolved or ctx is done
+func WaitResolve[T any](ctx context.Context) (T, error) {
+	return WaitResolveIn[T](ctx, Default())
+}
+
+// WaitResolveIn blocks until an instance of T can be resolved from c or ctx is done
+func WaitResolveIn[T any](ctx context.Context, c *Container) (T, error) {
+	for {
+		next := c.registrations()
+
+		v, err := ResolveIn[T](c)
+		if !errors.Is(err, ErrNotRegistered) {
+			return v, err
+		}
+
+		select {
+		case <-next:
+		case <-ctx.Done():
+			var zero T
+			return zero, errors.Join(ctx.Err(), err)
+		}
+	}
+}
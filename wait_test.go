@@ -0,0 +1,35 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ryanbekhen/di"
+)
+
+type waitQueue struct{}
+
+func TestWaitResolveReturnsLateRegistration(t *testing.T) {
+	c := di.New()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		di.RegisterFactoryIn(c, func() *waitQueue { return &waitQueue{} })
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if v, err := di.WaitResolveIn[*waitQueue](ctx, c); err != nil || v == nil {
+		t.Fatalf("WaitResolveIn returned %v, %v", v, err)
+	}
+}
+
+func TestWaitResolveStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := di.WaitResolveIn[*waitQueue](ctx, di.New())
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, di.ErrNotRegistered) {
+		t.Fatalf("WaitResolveIn returned %v", err)
+	}
+}
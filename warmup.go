@@ -0,0 +1,62 @@
+package di
+
+import (
+	"context"
+	"errors"
+)
+
+// InitializeAll builds every singleton of the default container up front
+func InitializeAll(ctx context.Context) error {
+	return Default().InitializeAll(ctx)
+}
+
+// InitializeAll builds every singleton registered in c up front, in
+// dependency order, instead of waiting for the first resolution. Factories
+// registered with RegisterFactoryCtx receive ctx. It keeps going after a
+// failure and returns all errors joined. It stops early when ctx is done.
+func (c *Container) InitializeAll(ctx context.Context) error {
+	var errs []error
+	for _, f := range c.initOrder() {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if f.lifetime != Singleton || f.target != nil {
+			continue
+		}
+		if err := c.warm(ctx, f); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// initOrder returns the registrations of c with declared dependencies
+// ordered before their dependents, otherwise in registration order
+func (c *Container) initOrder() []*factory {
+	factories := c.sortedFactories(func(*factory) bool { return true })
+	byKey := make(map[key]*factory, len(factories))
+	for _, f := range factories {
+		byKey[f.key] = f
+	}
+
+	order := make([]*factory, 0, len(factories))
+	visited := make(map[key]bool, len(factories))
+	var visit func(f *factory)
+	visit = func(f *factory) {
+		if visited[f.key] {
+			return
+		}
+		visited[f.key] = true
+		for _, dep := range f.deps {
+			if d, ok := byKey[dep]; ok {
+				visit(d)
+			}
+		}
+		order = append(order, f)
+	}
+	for _, f := range factories {
+		visit(f)
+	}
+	return order
+}
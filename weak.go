@@ -0,0 +1,65 @@
+package di
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"unsafe"
+	"weak"
+)
+
+// weakSlot caches the instance of a weak singleton without keeping it alive
+type weakSlot struct {
+	mu  sync.Mutex
+	ref weak.Pointer[byte]
+	typ reflect.Type
+}
+
+// WithWeak makes a singleton factory cache its instance behind a weak
+// reference, so the garbage collector can reclaim it once nothing else uses
+// it; the next resolution then builds a new one. Only pointer instances are
+// held weakly; others are rebuilt on every resolution. Weak instances have
+// no lifecycle hooks, and the cleanup functions of their factories are not run.
+func WithWeak() RegisterOption {
+	return func(f *factory) {
+		f.weak = &weakSlot{}
+	}
+}
+
+// current returns the live instance, if any
+func (s *weakSlot) current() (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.typ == nil {
+		return nil, false
+	}
+	p := s.ref.Value()
+	if p == nil {
+		return nil, false
+	}
+	return reflect.NewAt(s.typ.Elem(), unsafe.Pointer(p)).Interface(), true
+}
+
+// resolveWeak returns the live instance of the weak singleton f or builds a new one
+func (c *Container) resolveWeak(ctx context.Context, f *factory) (any, error) {
+	s := f.weak
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.typ != nil {
+		if p := s.ref.Value(); p != nil {
+			return reflect.NewAt(s.typ.Elem(), unsafe.Pointer(p)).Interface(), nil
+		}
+	}
+
+	v, _, err := c.run(ctx, f, c)
+	if err != nil {
+		return nil, err
+	}
+	s.typ = nil
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		s.ref = weak.Make((*byte)(rv.UnsafePointer()))
+		s.typ = rv.Type()
+	}
+	return v, nil
+}
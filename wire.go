@@ -0,0 +1,169 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// wirePath is the import path of google/wire, whose provider sets and markers
+// only exist for its code generator
+const wirePath = "github.com/google/wire"
+
+// setEntry is an entry of a provider set that is not a constructor
+type setEntry interface {
+	registerIn(c *Container) error
+}
+
+// isWireValue reports whether v was built by the google/wire package, such as
+// the result of wire.NewSet or wire.Bind
+func isWireValue(v any) bool {
+	t := reflect.TypeOf(v)
+	return t != nil && t.PkgPath() == wirePath
+}
+
+// bindEntry binds the interface from to the registration of to
+type bindEntry struct {
+	from, to reflect.Type
+}
+
+// Binding returns a provider set binding I to the registration of Impl, like
+// wire.Bind(new(I), new(Impl))
+func Binding[I, Impl any]() ProviderSet {
+	return ProviderSet{bindEntry{from: typeOf[I](), to: typeOf[Impl]()}}
+}
+
+func (b bindEntry) registerIn(c *Container) error {
+	return c.alias(keyOf(b.from), keyOf(b.to), b.from, b.to, nil)
+}
+
+// valueEntry registers a ready instance
+type valueEntry struct {
+	k     key
+	value any
+}
+
+// Value returns a provider set registering v as the instance of T, like
+// wire.Value(v). Pass the interface explicitly, Value[io.Reader](r), for
+// what wire.InterfaceValue does.
+func Value[T any](v T) ProviderSet {
+	return ProviderSet{valueEntry{k: typeKey[T](), value: v}}
+}
+
+func (v valueEntry) registerIn(c *Container) error {
+	return c.addInstance(v.k, v.value, nil)
+}
+
+// structEntry builds a struct, or a pointer to one, from its fields
+type structEntry struct {
+	typ    reflect.Type
+	fields []string
+}
+
+// Struct returns a provider set that builds T, a struct or a pointer to one,
+// by resolving the named fields, like wire.Struct(new(T), fields...). "*"
+// stands for every exported field not tagged `wire:"-"`.
+func Struct[T any](fields ...string) ProviderSet {
+	return ProviderSet{structEntry{typ: typeOf[T](), fields: fields}}
+}
+
+func (s structEntry) registerIn(c *Container) error {
+	st, err := structOf(s.typ)
+	if err != nil {
+		return err
+	}
+	var fields []reflect.StructField
+	if slices.Contains(s.fields, "*") {
+		for i := range st.NumField() {
+			if f := st.Field(i); f.IsExported() && f.Tag.Get("wire") != "-" {
+				fields = append(fields, f)
+			}
+		}
+	} else {
+		for _, name := range s.fields {
+			f, ok := st.FieldByName(name)
+			if !ok || !f.IsExported() {
+				return fmt.Errorf("%v has no exported field %s", st, name)
+			}
+			fields = append(fields, f)
+		}
+	}
+
+	deps := make([]key, len(fields))
+	for i, f := range fields {
+		deps[i] = depKeyOf(f.Type)
+	}
+	return c.addFactory(keyOf(s.typ), Singleton, nil, func(r Resolver) (any, func(), error) {
+		v := reflect.New(st).Elem()
+		for i, f := range fields {
+			dep, err := resolveType(r, f.Type, deps[i])
+			if err != nil {
+				return nil, nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			if dep != nil {
+				v.FieldByIndex(f.Index).Set(reflect.ValueOf(dep))
+			}
+		}
+		if s.typ.Kind() == reflect.Pointer {
+			return v.Addr().Interface(), nil, nil
+		}
+		return v.Interface(), nil, nil
+	}, deps...)
+}
+
+// fieldsEntry provides fields of a registered struct
+type fieldsEntry struct {
+	typ    reflect.Type
+	fields []string
+}
+
+// FieldsOf returns a provider set that provides the named fields of T, a
+// registered struct or pointer to one, by their types, like
+// wire.FieldsOf(new(T), fields...)
+func FieldsOf[T any](fields ...string) ProviderSet {
+	return ProviderSet{fieldsEntry{typ: typeOf[T](), fields: fields}}
+}
+
+func (e fieldsEntry) registerIn(c *Container) error {
+	st, err := structOf(e.typ)
+	if err != nil {
+		return err
+	}
+	from := keyOf(e.typ)
+	var errs []error
+	for _, name := range e.fields {
+		f, ok := st.FieldByName(name)
+		if !ok || !f.IsExported() {
+			errs = append(errs, fmt.Errorf("%v has no exported field %s", st, name))
+			continue
+		}
+		errs = append(errs, c.addFactory(keyOf(f.Type), Singleton, nil, func(r Resolver) (any, func(), error) {
+			owner, err := resolveKey(r, from)
+			if err != nil {
+				return nil, nil, err
+			}
+			v := reflect.ValueOf(owner)
+			if v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					return nil, nil, fmt.Errorf("field %s of nil %v", name, e.typ)
+				}
+				v = v.Elem()
+			}
+			return v.FieldByIndex(f.Index).Interface(), nil, nil
+		}, from))
+	}
+	return errors.Join(errs...)
+}
+
+// structOf returns t if it is a struct type, or the struct t points to
+func structOf(t reflect.Type) (reflect.Type, error) {
+	st := t
+	if st.Kind() == reflect.Pointer {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%v is not a struct or a pointer to one", t)
+	}
+	return st, nil
+}
@@ -0,0 +1,95 @@
+package di_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ryanbekhen/di"
+)
+
+type wireStore interface{ Get(key string) string }
+
+type wireMemStore struct{ prefix string }
+
+func (s *wireMemStore) Get(key string) string { return s.prefix + key }
+
+type wireConfig struct {
+	Prefix string
+	Port   int
+}
+
+type wireHandler struct {
+	Store  wireStore
+	Config *wireConfig
+	Secret string `wire:"-"`
+	local  int
+}
+
+func newWireMemStore(prefix string) *wireMemStore { return &wireMemStore{prefix: prefix} }
+
+// sharedProviders is the kind of plain slice a wire set can be built from
+var sharedProviders = []any{newWireMemStore}
+
+func TestRegisterConstructorsWireEntries(t *testing.T) {
+	c := di.New()
+	set := di.NewSet(
+		sharedProviders,
+		di.Binding[wireStore, *wireMemStore](),
+		di.Value(&wireConfig{Prefix: "k:", Port: 8080}),
+		di.FieldsOf[*wireConfig]("Prefix", "Port"),
+		di.Struct[*wireHandler]("*"),
+	)
+	if err := c.RegisterConstructors(set); err != nil {
+		t.Fatal(err)
+	}
+
+	h := di.MustResolveIn[*wireHandler](c)
+	if got := h.Store.Get("a"); got != "k:a" {
+		t.Fatalf("Store.Get() = %q, want k:a", got)
+	}
+	if h.Config.Port != 8080 || h.Secret != "" {
+		t.Fatalf("handler built with %+v", h)
+	}
+	if di.MustResolveIn[int](c) != 8080 {
+		t.Fatal("FieldsOf did not provide Port")
+	}
+	if di.MustResolveIn[wireStore](c) != wireStore(di.MustResolveIn[*wireMemStore](c)) {
+		t.Fatal("Binding does not share the instance of the implementation")
+	}
+}
+
+func TestStructNamedFields(t *testing.T) {
+	c := di.New()
+	di.RegisterIn(c, &wireConfig{Port: 1})
+	if err := c.RegisterConstructors(di.Struct[wireHandler]("Config")); err != nil {
+		t.Fatal(err)
+	}
+
+	h := di.MustResolveIn[wireHandler](c)
+	if h.Config == nil || h.Store != nil {
+		t.Fatalf("handler built with %+v", h)
+	}
+}
+
+func TestRegisterConstructorsWireEntryErrors(t *testing.T) {
+	c := di.New()
+	err := c.RegisterConstructors(
+		di.Binding[wireStore, wireConfig](),
+		di.Struct[int]("*"),
+		di.Struct[wireHandler]("Missing"),
+		di.FieldsOf[wireHandler]("local"),
+	)
+	if err == nil {
+		t.Fatal("RegisterConstructors() succeeded")
+	}
+	for _, want := range []string{
+		"cannot bind",
+		"int is not a struct or a pointer to one",
+		"has no exported field Missing",
+		"has no exported field local",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+}